@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+)
+
+type CacheSubcommand struct {
+	CacheList  *CacheListArgs  `arg:"subcommand:list"`
+	CachePrune *CachePruneArgs `arg:"subcommand:prune"`
+	CacheClear *CacheClearArgs `arg:"subcommand:clear"`
+}
+
+func (args *CacheSubcommand) Run() {
+	switch {
+	case args.CacheList != nil:
+		args.CacheList.Run()
+	case args.CachePrune != nil:
+		args.CachePrune.Run()
+	case args.CacheClear != nil:
+		args.CacheClear.Run()
+
+	default:
+		logger.Panic("command not supported")
+	}
+
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"github.com/sombrahq/sombra-cli/internal/runtime"
+)
+
+type CacheClearArgs struct{}
+
+func (args *CacheClearArgs) Run() {
+	rt, err := runtime.NewCacheRuntime()
+	if err != nil {
+		logger.Panic("Cache not available")
+	}
+
+	err = rt.UseCase.DoCacheClear()
+	if err != nil {
+		logger.Panic("Failed to clear cache")
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"github.com/sombrahq/sombra-cli/internal/runtime"
+)
+
+type CacheListArgs struct{}
+
+func (args *CacheListArgs) Run() {
+	rt, err := runtime.NewCacheRuntime()
+	if err != nil {
+		logger.Panic("Cache not available")
+	}
+
+	err = rt.UseCase.DoCacheList()
+	if err != nil {
+		logger.Panic("Failed to list cache")
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"github.com/sombrahq/sombra-cli/internal/runtime"
+)
+
+type CachePruneArgs struct {
+	OlderThan int `arg:"--older-than" help:"Remove the templates not used in this number of days" default:"30"`
+}
+
+func (args *CachePruneArgs) Run() {
+	rt, err := runtime.NewCacheRuntime()
+	if err != nil {
+		logger.Panic("Cache not available")
+	}
+
+	err = rt.UseCase.DoCachePrune(args.OlderThan)
+	if err != nil {
+		logger.Error("Failed to prune cache", err)
+		logger.Panic("Failed to prune cache")
+	}
+}
@@ -7,6 +7,7 @@ import (
 type LocalSubcommand struct {
 	LocalInit   *LocalInitArgs   `arg:"subcommand:init"`
 	LocalUpdate *LocalUpdateArgs `arg:"subcommand:update"`
+	LocalDiff   *LocalDiffArgs   `arg:"subcommand:diff"`
 }
 
 func (args *LocalSubcommand) Run() {
@@ -15,6 +16,8 @@ func (args *LocalSubcommand) Run() {
 		args.LocalInit.Run()
 	case args.LocalUpdate != nil:
 		args.LocalUpdate.Run()
+	case args.LocalDiff != nil:
+		args.LocalDiff.Run()
 
 	default:
 		logger.Panic("command not supported")
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"github.com/sombrahq/sombra-cli/internal/runtime"
+	"os"
+)
+
+type LocalDiffArgs struct {
+	Template string `arg:"positional,required" help:"Git template to compare with"`
+	Tag      string `arg:"--tag" help:"Git tag to use as template"`
+	Stat     bool   `arg:"--stat" help:"Show a summary of the changes instead of the full diff"`
+	Offline  bool   `arg:"--offline" help:"Only use the templates already in the cache"`
+}
+
+func (args *LocalDiffArgs) Run() {
+	rt, err := runtime.NewLocalDiffRuntime(args.Offline)
+	if err != nil {
+		logger.Error("Failed to read the global config", err)
+		logger.Panic("Failed to diff local project")
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		logger.Panic("What local directory")
+	}
+
+	err = rt.UseCase.DoLocalDiff(cwd, args.Template, args.Tag, args.Stat)
+	if err != nil {
+		logger.Error("Failed to diff local project", err)
+		logger.Panic("Failed to diff local project")
+	}
+}
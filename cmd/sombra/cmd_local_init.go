@@ -7,18 +7,33 @@ import (
 )
 
 type LocalInitArgs struct {
-	Template string `arg:"positional,required" help:"Git Repository to use as template"`
+	Template string   `arg:"positional,required" help:"Git Repository to use as template"`
+	Flavor   string   `arg:"--flavor" help:"Template definition to use, .sombra/<flavor>.yaml (default: default)"`
+	Offline  bool     `arg:"--offline" help:"Only use the templates already in the cache"`
+	Vars     []string `arg:"--var,separate" help:"Value of a variable, key=value"`
+	VarsFile string   `arg:"--vars-file" help:"YAML file with the values of the variables"`
+	NoInput  bool     `arg:"--no-input" help:"Fail on the missing variables instead of prompting for them"`
 }
 
 func (args *LocalInitArgs) Run() {
-	rt := runtime.NewLocalInitRuntime()
+	rt, err := runtime.NewLocalInitRuntime(runtime.LocalInitOptions{
+		Offline:  args.Offline,
+		Vars:     args.Vars,
+		VarsFile: args.VarsFile,
+		NoInput:  args.NoInput,
+	})
+	if err != nil {
+		logger.Error("Failed to read the config", err)
+		logger.Panic("Failed to init local project")
+	}
 	cwd, err := os.Getwd()
 	if err != nil {
 		logger.Panic("What local directory")
 	}
 
-	err = rt.UseCase.DoLocalInit(cwd, args.Template)
+	err = rt.UseCase.DoLocalInit(cwd, args.Template, args.Flavor)
 	if err != nil {
+		logger.Error("Failed to init local project", err)
 		logger.Panic("Failed to init local project")
 	}
 }
@@ -7,20 +7,49 @@ import (
 )
 
 type LocalUpdateArgs struct {
-	Template string `arg:"positional,required" help:"Git template to update"`
+	Template string `arg:"positional" help:"Git template to update, all the templates are updated when it is not set"`
+	All      bool   `arg:"--all" help:"Update every template of sombra.yaml, each one to its latest version"`
+	ToNext   bool   `arg:"--to-next" help:"Update to the release right after the current one instead of the latest one"`
+	Stepwise bool   `arg:"--stepwise" help:"Apply every release between the current version and the target one in turn (diff method only)"`
 	Tag      string `arg:"--tag" help:"Git tag to use as template"`
-	Method   string `arg:"--method" help:"Method to use for updating the project. (copy|diff)" default:"copy"`
+	Method   string `arg:"--method" help:"Method to use for updating the project. (copy|diff|merge)" default:"copy"`
+	DryRun   bool   `arg:"--dry-run" help:"Report the planned changes without writing any file"`
+	Offline  bool   `arg:"--offline" help:"Only use the templates already in the cache"`
+	// AllowRejects only applies to the diff method
+	AllowRejects bool `arg:"--allow-rejects" help:"Store the new version even if some changes could not be applied"`
 }
 
 func (args *LocalUpdateArgs) Run() {
-	rt := runtime.NewLocalUpdateRuntime()
+	rt, err := runtime.NewLocalUpdateRuntime(args.Offline)
+	if err != nil {
+		logger.Error("Failed to read the global config", err)
+		logger.Panic("Failed to update local project")
+	}
 	cwd, err := os.Getwd()
 	if err != nil {
 		logger.Panic("What local directory")
 	}
 
-	err = rt.UseCase.DoLocalUpdate(cwd, args.Template, args.Tag, args.Method)
+	opts := runtime.LocalUpdateOptions{
+		Tag:          args.Tag,
+		DryRun:       args.DryRun,
+		AllowRejects: args.AllowRejects,
+		ToNext:       args.ToNext,
+		Stepwise:     args.Stepwise,
+	}
+	if args.ToNext && args.Tag != "" {
+		logger.Panic("Use either --tag or --to-next")
+	}
+	if args.All && args.Template != "" {
+		logger.Panic("Use either a template or --all")
+	}
+	if args.Template == "" {
+		err = rt.UseCase.DoLocalUpdateAll(cwd, args.Method, opts)
+	} else {
+		err = rt.UseCase.DoLocalUpdate(cwd, args.Template, args.Method, opts)
+	}
 	if err != nil {
-		logger.Panic("Failed to do dry copy")
+		logger.Error("Failed to update local project", err)
+		logger.Panic("Failed to update local project")
 	}
 }
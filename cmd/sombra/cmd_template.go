@@ -5,13 +5,16 @@ import (
 )
 
 type TemplateSubcommand struct {
-	TemplateInit *TemplateInitArgs `arg:"subcommand:init"`
+	TemplateInit        *TemplateInitArgs        `arg:"subcommand:init"`
+	TemplateListFlavors *TemplateListFlavorsArgs `arg:"subcommand:list-flavors"`
 }
 
 func (args *TemplateSubcommand) Run() {
 	switch {
 	case args.TemplateInit != nil:
 		args.TemplateInit.Run()
+	case args.TemplateListFlavors != nil:
+		args.TemplateListFlavors.Run()
 
 	default:
 		logger.Panic("command not supported")
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"github.com/sombrahq/sombra-cli/internal/runtime"
+)
+
+type TemplateListFlavorsArgs struct {
+	Template string `arg:"positional,required" help:"Git Repository of the template"`
+	Tag      string `arg:"--tag" help:"Git tag to read the definitions from, the default branch when it is not set"`
+	Offline  bool   `arg:"--offline" help:"Only use the templates already in the cache"`
+}
+
+func (args *TemplateListFlavorsArgs) Run() {
+	rt, err := runtime.NewTemplateFlavorsRuntime(args.Offline)
+	if err != nil {
+		logger.Error("Failed to read the global config", err)
+		logger.Panic("Failed to list flavors")
+	}
+
+	err = rt.UseCase.DoListFlavors(args.Template, args.Tag)
+	if err != nil {
+		logger.Error("Failed to list flavors", err)
+		logger.Panic("Failed to list flavors")
+	}
+}
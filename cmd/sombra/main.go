@@ -12,6 +12,7 @@ COMMANDS
 var args struct {
 	Local    *LocalSubcommand    `arg:"subcommand:local"`
 	Template *TemplateSubcommand `arg:"subcommand:template"`
+	Cache    *CacheSubcommand    `arg:"subcommand:cache"`
 }
 
 /***********
@@ -27,6 +28,8 @@ func main() {
 		args.Local.Run()
 	case args.Template != nil:
 		args.Template.Run()
+	case args.Cache != nil:
+		args.Cache.Run()
 	default:
 		logger.Panic("No command specified")
 	}
@@ -15,3 +15,68 @@ func ConvertToWildcards(strs []string) []Wildcard {
 	}
 	return wildcards
 }
+
+// NormaliseURI drops the scheme, the user, the port and the .git suffix of a repository URI, the host is case-insensitive.
+// The same repository written in different ways, like https://github.com/org/repo.git and git@github.com:org/repo, gets the same value.
+func NormaliseURI(uri string) string {
+	res := strings.TrimSpace(uri)
+	if _, rest, ok := strings.Cut(res, "://"); ok {
+		res = rest
+	} else if colon, slash := strings.Index(res, ":"), strings.Index(res, "/"); colon > 0 && (slash < 0 || colon < slash) {
+		// scp-like syntax, user@host:path
+		res = res[:colon] + "/" + res[colon+1:]
+	}
+	res = strings.TrimSuffix(strings.TrimSuffix(res, "/"), ".git")
+
+	host, path, _ := strings.Cut(res, "/")
+	if _, after, ok := strings.Cut(host, "@"); ok {
+		host = after
+	}
+	host, _, _ = strings.Cut(host, ":")
+	return strings.ToLower(host) + "/" + strings.TrimPrefix(path, "/")
+}
+
+// SplitSubdir splits the URI//subdir syntax of the templates in a subdirectory of a repository.
+// The // of the scheme is not a separator, so https://github.com/org/repo//python-api is split after repo.
+func SplitSubdir(uri string) (string, string) {
+	start := 0
+	if i := strings.Index(uri, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	i := strings.Index(uri[start:], "//")
+	if i < 0 {
+		return uri, ""
+	}
+	return uri[:start+i], strings.Trim(uri[start+i+2:], "/")
+}
+
+// MappingKey is a key of the mappings with its options, written as prefixes of the text to search
+type MappingKey struct {
+	Text string
+	// Regex is set by re:, the text is an RE2 expression and the value can use its groups, like $1
+	Regex bool
+	// Case is set by case:, the mapping is expanded to the camel, pascal, snake, kebab, upper and lower forms of the text and the value
+	Case bool
+	// Word is set by word:, the text only matches whole words, the identifiers of the language in the content and the path segments in the names
+	Word bool
+}
+
+// ParseMappingKey reads the options of a mapping key, like re:^name, case:MyService or word:api
+func ParseMappingKey(key string) MappingKey {
+	res := MappingKey{Text: key}
+	for {
+		switch {
+		case strings.HasPrefix(res.Text, "re:") && !res.Regex:
+			res.Regex = true
+			res.Text = strings.TrimPrefix(res.Text, "re:")
+		case strings.HasPrefix(res.Text, "case:") && !res.Case:
+			res.Case = true
+			res.Text = strings.TrimPrefix(res.Text, "case:")
+		case strings.HasPrefix(res.Text, "word:") && !res.Word:
+			res.Word = true
+			res.Text = strings.TrimPrefix(res.Text, "word:")
+		default:
+			return res
+		}
+	}
+}
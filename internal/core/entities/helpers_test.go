@@ -0,0 +1,68 @@
+package entities
+
+import "testing"
+
+func TestNormaliseURI(t *testing.T) {
+	tests := []struct {
+		uri      string
+		expected string
+	}{
+		{uri: "github.com/user/repo", expected: "github.com/user/repo"},
+		{uri: "https://github.com/user/repo.git", expected: "github.com/user/repo"},
+		{uri: "https://GitHub.com/user/repo/", expected: "github.com/user/repo"},
+		{uri: "git@github.com:user/repo.git", expected: "github.com/user/repo"},
+		{uri: "ssh://git@github.com:22/user/repo.git", expected: "github.com/user/repo"},
+		{uri: "https://token@gitlab.example.com/group/sub/repo", expected: "gitlab.example.com/group/sub/repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			if res := NormaliseURI(tt.uri); res != tt.expected {
+				t.Errorf("NormaliseURI(%s) = %s, expected %s", tt.uri, res, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitSubdir(t *testing.T) {
+	tests := []struct {
+		uri    string
+		repo   string
+		subdir string
+	}{
+		{uri: "github.com/org/repo", repo: "github.com/org/repo", subdir: ""},
+		{uri: "github.com/org/repo//python-api", repo: "github.com/org/repo", subdir: "python-api"},
+		{uri: "https://github.com/org/repo.git//templates/python-api/", repo: "https://github.com/org/repo.git", subdir: "templates/python-api"},
+		{uri: "https://github.com/org/repo", repo: "https://github.com/org/repo", subdir: ""},
+		{uri: "git@github.com:org/repo.git//api", repo: "git@github.com:org/repo.git", subdir: "api"},
+		{uri: "/home/user/templates//api", repo: "/home/user/templates", subdir: "api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			repo, subdir := SplitSubdir(tt.uri)
+			if repo != tt.repo || subdir != tt.subdir {
+				t.Errorf("SplitSubdir(%s) = %s, %s, expected %s, %s", tt.uri, repo, subdir, tt.repo, tt.subdir)
+			}
+		})
+	}
+}
+
+func TestParseMappingKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected MappingKey
+	}{
+		{key: "acme", expected: MappingKey{Text: "acme"}},
+		{key: "re:description = .*\n", expected: MappingKey{Text: "description = .*\n", Regex: true}},
+		{key: "case:MyService", expected: MappingKey{Text: "MyService", Case: true}},
+		{key: "word:case:api", expected: MappingKey{Text: "api", Case: true, Word: true}},
+		{key: "re:re:x", expected: MappingKey{Text: "re:x", Regex: true}},
+		{key: "https://acme.com", expected: MappingKey{Text: "https://acme.com"}},
+	}
+	for _, tt := range tests {
+		if res := ParseMappingKey(tt.key); res != tt.expected {
+			t.Errorf("ParseMappingKey(%q) = %+v, expected %+v", tt.key, res, tt.expected)
+		}
+	}
+}
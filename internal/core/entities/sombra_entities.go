@@ -1,5 +1,10 @@
 package entities
 
+import (
+	"fmt"
+	"strings"
+)
+
 type RepoUpdateInfo struct {
 	Branch         string
 	CurrentVersion string
@@ -45,10 +50,50 @@ type FileAnalysis struct {
 type Mappings map[string]string
 
 type TemplateConfig struct {
-	URI     string   `yaml:"uri" validate:"required"`
-	Path    string   `yaml:"path,omitempty"`
-	Current Version  `yaml:"current,omitempty"`
-	Vars    Mappings `yaml:"vars" validate:"required"`
+	URI     string  `yaml:"uri" validate:"required"`
+	Path    string  `yaml:"path,omitempty"`
+	Current Version `yaml:"current,omitempty"`
+	// Constraint limits the versions used by the updates, e.g. ^1.4 or ~2.0
+	Constraint string `yaml:"constraint,omitempty"`
+	// Branch makes the updates follow the head of the branch instead of the tags, Current keeps the commit applied
+	Branch string   `yaml:"branch,omitempty"`
+	Vars   Mappings `yaml:"vars" validate:"required"`
+	// Auth sets the credentials of a private template, the global configuration is used when it is not set
+	Auth *TemplateAuth `yaml:"auth,omitempty"`
+	// Subdir is the directory of the template in a repository holding several ones, it wins over the URI//subdir syntax
+	Subdir string `yaml:"subdir,omitempty"`
+	// TagPrefix keeps the tags of the template only, e.g. python-api/ for python-api/v1.2.0
+	TagPrefix string `yaml:"tag_prefix,omitempty"`
+	// Flavor is the definition of the template applied, .sombra/<flavor>.yaml, the default one when it is empty
+	Flavor string `yaml:"flavor,omitempty"`
+}
+
+// Source returns the repository of the template and its directory in it
+func (t *TemplateConfig) Source() (string, string) {
+	uri, subdir := SplitSubdir(t.URI)
+	if t.Subdir != "" {
+		subdir = strings.Trim(t.Subdir, "/")
+	}
+	return uri, subdir
+}
+
+// TemplateAuth tells where the credentials of a template are, the secrets themselves are never stored in sombra.yaml
+type TemplateAuth struct {
+	// SSHKey is the path of the private key used with ssh URIs
+	SSHKey string `yaml:"ssh_key,omitempty"`
+	// TokenEnv is the environment variable holding the token used with https URIs
+	TokenEnv string `yaml:"token_env,omitempty"`
+	// Username goes with the token, some providers expect a specific one
+	Username string `yaml:"username,omitempty"`
+	// Netrc is the path of a netrc file with the login of the host
+	Netrc string `yaml:"netrc,omitempty"`
+}
+
+// Credentials are the secrets read from a TemplateAuth
+type Credentials struct {
+	SSHKey   string
+	Username string
+	Password string
 }
 
 type Pattern struct {
@@ -61,15 +106,91 @@ type Pattern struct {
 	Name     Mappings   `yaml:"name,omitempty"`
 	Content  Mappings   `yaml:"content,omitempty"`
 	Except   []Wildcard `yaml:"except,omitempty"`
+	// Render runs the files through Go templates with the variables of the template, before the content mappings
+	Render bool `yaml:"render,omitempty"`
+	// Delims are the left and right delimiters of the Go templates, {{ and }} when they are not set
+	Delims []string `yaml:"delims,omitempty"`
+	// When is the condition of the pattern, rendered with the variables of the template to true or false
+	When *string `yaml:"when,omitempty"`
 }
 
+// Enabled evaluates the condition of the pattern, a pattern without condition is always enabled
+// and a condition rendered empty is false
+func (p *Pattern) Enabled() (bool, error) {
+	if p.When == nil {
+		return true, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(*p.When)) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "", "false", "no", "off", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("the condition of %s must be true or false, got %q", p.Pattern, *p.When)
+}
+
+// DefaultFlavor is the definition used when the template config has no flavor
+const DefaultFlavor = "default"
+
 type TemplateDef struct {
-	Vars     []string   `yaml:"vars"`
+	// Extends is the definition this one is based on, relative to its directory
+	Extends string `yaml:"extends,omitempty"`
+	// Include lists the fragments merged after the extended definition, relative to its directory
+	Include  []string   `yaml:"include,omitempty"`
+	Vars     []*Var     `yaml:"vars"`
 	Patterns []*Pattern `yaml:"patterns" validate:"required"`
 }
 
+const (
+	VarString = "string"
+	VarBool   = "bool"
+	VarInt    = "int"
+	VarChoice = "choice"
+	// VarList values are comma separated
+	VarList = "list"
+)
+
+// Var is a variable of the template, written as its name alone or with its schema
+type Var struct {
+	Name string `yaml:"name" validate:"required"`
+	Type string `yaml:"type,omitempty"`
+	// Default is a Go template rendered with the variables read before
+	Default     string   `yaml:"default,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Regex       string   `yaml:"regex,omitempty"`
+	Choices     []string `yaml:"choices,omitempty"`
+}
+
+// HasSchema tells if the values of the variable are checked, a variable declared by its name takes any value
+func (v *Var) HasSchema() bool {
+	return v.Type != "" || v.Regex != "" || len(v.Choices) > 0
+}
+
+// UnmarshalYAML reads the variables declared by their name alone
+func (v *Var) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*v = Var{Name: name}
+		return nil
+	}
+	type plain Var
+	return unmarshal((*plain)(v))
+}
+
+// MarshalYAML writes the variables without schema by their name
+func (v *Var) MarshalYAML() (interface{}, error) {
+	if !v.HasSchema() && v.Default == "" && v.Description == "" {
+		return v.Name, nil
+	}
+	type plain Var
+	return (*plain)(v), nil
+}
+
 type Version string
 
+// WorkingTree is the version of a local template with its uncommitted changes
+const WorkingTree Version = "worktree"
+
 type MapItem struct {
 	Key   string
 	Value string
@@ -81,6 +202,9 @@ type MapResult struct {
 	Path    MapList
 	Name    MapList
 	Content MapList
+	// Render is set when the content is a Go template, with the Delims of the last pattern setting them
+	Render bool
+	Delims []string
 }
 
 type SombraTemplateUpdateInfo struct {
@@ -97,3 +221,144 @@ type SombraUpdateInfo struct {
 type SombraDef struct {
 	Templates []*TemplateConfig `yaml:"templates" validate:"required"`
 }
+
+type FileOperation string
+
+const (
+	FileCreate FileOperation = "create"
+	FileModify FileOperation = "modify"
+	FileRename FileOperation = "rename"
+	FileDelete FileOperation = "delete"
+	// FileSkip is a file left as it is, the reason of the change says why
+	FileSkip FileOperation = "skip"
+)
+
+type FileChange struct {
+	Operation FileOperation
+	File      File
+	// From is the previous name of the file when it is renamed
+	From File
+	// Conflict is set when the local changes could not be combined with the template ones
+	Conflict bool
+	// Reason explains why a file is skipped
+	Reason string
+}
+
+type UpdatePlan struct {
+	URI     string
+	Path    string
+	From    Version
+	To      Version
+	DryRun  bool
+	Changes []*FileChange
+	// Patch is the outcome of applying the template changes, only set by the diff method
+	Patch *PatchResult
+	// Incomplete is set when some changes were rejected and the new version was not stored
+	Incomplete bool
+}
+
+type RenderedFile struct {
+	// Source is the file in the template directory
+	Source  File
+	File    File
+	Content []byte
+}
+
+type TextDiff struct {
+	Patch   []byte
+	Added   int
+	Removed int
+}
+
+type FileDiff struct {
+	Operation FileOperation
+	File      File
+	Diff      *TextDiff
+}
+
+type TemplateDiff struct {
+	URI     string
+	Path    string
+	Version Version
+	Files   []*FileDiff
+}
+
+type MergeResult struct {
+	Content   []byte
+	Conflicts int
+}
+
+type HunkStatus string
+
+const (
+	HunkApplied HunkStatus = "applied"
+	// HunkFuzzed is a hunk applied at a different line or ignoring some context lines
+	HunkFuzzed   HunkStatus = "fuzzed"
+	HunkRejected HunkStatus = "rejected"
+)
+
+type PatchHunkResult struct {
+	Number int
+	Status HunkStatus
+	Line   int
+}
+
+type PatchFileResult struct {
+	File  File
+	Hunks []*PatchHunkResult
+	// RejectFile keeps the hunks that could not be applied
+	RejectFile File
+	// Message explains why the whole file was skipped
+	Message string
+}
+
+func (f *PatchFileResult) Count(status HunkStatus) int {
+	count := 0
+	for _, hunk := range f.Hunks {
+		if hunk.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+type PatchResult struct {
+	Files []*PatchFileResult
+}
+
+// Rejected returns the number of hunks that could not be applied
+func (r *PatchResult) Rejected() int {
+	count := 0
+	for _, file := range r.Files {
+		count += file.Count(HunkRejected)
+	}
+	return count
+}
+
+type UpdateStatus string
+
+const (
+	UpdateApplied UpdateStatus = "updated"
+	UpdatePlanned UpdateStatus = "planned"
+	UpdateCurrent UpdateStatus = "up to date"
+	// UpdateFailed is a template whose changes were rolled back
+	UpdateFailed UpdateStatus = "failed"
+	// UpdateSkipped is a template not updated because a previous one failed
+	UpdateSkipped UpdateStatus = "skipped"
+)
+
+type TemplateUpdate struct {
+	URI    string
+	Status UpdateStatus
+	Plans  []*UpdatePlan
+	Error  string
+}
+
+// CacheEntry is a template repository kept in the local cache
+type CacheEntry struct {
+	URI  string
+	Dir  string
+	Size int64
+	// LastUsed is the date of the last update that read the template
+	LastUsed string
+}
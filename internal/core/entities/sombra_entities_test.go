@@ -0,0 +1,32 @@
+package entities
+
+import "testing"
+
+func TestPattern_Enabled(t *testing.T) {
+	value := func(s string) *string { return &s }
+	tests := []struct {
+		name     string
+		when     *string
+		expected bool
+		wantErr  bool
+	}{
+		{name: "no condition", when: nil, expected: true},
+		{name: "true", when: value("true"), expected: true},
+		{name: "yes", when: value(" Yes "), expected: true},
+		{name: "false", when: value("false"), expected: false},
+		{name: "rendered empty", when: value(""), expected: false},
+		{name: "missing variable", when: value("<no value>"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := (&Pattern{Pattern: "docker/**", When: tt.when}).Enabled()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Enabled() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res != tt.expected {
+				t.Errorf("Enabled() = %v, expected %v", res, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package usecases
+
+import "fmt"
+
+type CliCacheCase interface {
+	DoCacheList() error
+	DoCachePrune(days int) error
+	DoCacheClear() error
+}
+
+type CliCacheInteractor struct {
+	cache    RepositoryCachePort
+	reporter CacheReporterPort
+}
+
+func (l *CliCacheInteractor) DoCacheList() error {
+	entries, err := l.cache.List()
+	if err != nil {
+		return err
+	}
+	l.reporter.ReportCache(entries)
+	return nil
+}
+
+func (l *CliCacheInteractor) DoCachePrune(days int) error {
+	if days < 0 {
+		return fmt.Errorf("the number of days must be positive, got %d", days)
+	}
+	entries, err := l.cache.Prune(days)
+	if err != nil {
+		return err
+	}
+	l.reporter.ReportCacheRemoved(entries)
+	return nil
+}
+
+func (l *CliCacheInteractor) DoCacheClear() error {
+	entries, err := l.cache.Clear()
+	if err != nil {
+		return err
+	}
+	l.reporter.ReportCacheRemoved(entries)
+	return nil
+}
+
+func NewCliCacheInteractor(cache RepositoryCachePort, reporter CacheReporterPort) *CliCacheInteractor {
+	return &CliCacheInteractor{cache: cache, reporter: reporter}
+}
+
+var _ CliCacheCase = (*CliCacheInteractor)(nil)
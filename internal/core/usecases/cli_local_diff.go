@@ -0,0 +1,27 @@
+package usecases
+
+type CliLocalDiffCase interface {
+	DoLocalDiff(target, uri, tag string, stat bool) error
+}
+
+type CliLocalDiffInteractor struct {
+	diffCase LocalDiffCase
+	reporter DiffReporterPort
+}
+
+func (l *CliLocalDiffInteractor) DoLocalDiff(target, uri, tag string, stat bool) error {
+	diffs, err := l.diffCase.LocalDiff(target, uri, tag)
+	if err != nil {
+		return err
+	}
+	for _, diff := range diffs {
+		l.reporter.ReportDiff(diff, stat)
+	}
+	return nil
+}
+
+func NewCliLocalDiffInteractor(diffCase LocalDiffCase, reporter DiffReporterPort) *CliLocalDiffInteractor {
+	return &CliLocalDiffInteractor{diffCase: diffCase, reporter: reporter}
+}
+
+var _ CliLocalDiffCase = (*CliLocalDiffInteractor)(nil)
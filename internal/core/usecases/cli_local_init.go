@@ -1,15 +1,15 @@
 package usecases
 
 type CliLocalInitCase interface {
-	DoLocalInit(target, uri string) error
+	DoLocalInit(target, uri, flavor string) error
 }
 
 type CliLocalInitInteractor struct {
 	localInitCase LocalInitCase
 }
 
-func (l *CliLocalInitInteractor) DoLocalInit(target, uri string) error {
-	return l.localInitCase.LocalInit(target, uri)
+func (l *CliLocalInitInteractor) DoLocalInit(target, uri, flavor string) error {
+	return l.localInitCase.LocalInit(target, uri, flavor)
 }
 
 func NewCliLocalInitInteractor(localInitCase LocalInitCase) *CliLocalInitInteractor {
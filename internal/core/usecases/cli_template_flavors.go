@@ -0,0 +1,25 @@
+package usecases
+
+type CliTemplateFlavorsCase interface {
+	DoListFlavors(uri, tag string) error
+}
+
+type CliTemplateFlavorsInteractor struct {
+	flavorsCase TemplateFlavorsCase
+	reporter    TemplateReporterPort
+}
+
+func (l *CliTemplateFlavorsInteractor) DoListFlavors(uri, tag string) error {
+	flavors, err := l.flavorsCase.ListFlavors(uri, tag)
+	if err != nil {
+		return err
+	}
+	l.reporter.ReportFlavors(uri, flavors)
+	return nil
+}
+
+func NewCliTemplateFlavorsInteractor(flavorsCase TemplateFlavorsCase, reporter TemplateReporterPort) *CliTemplateFlavorsInteractor {
+	return &CliTemplateFlavorsInteractor{flavorsCase: flavorsCase, reporter: reporter}
+}
+
+var _ CliTemplateFlavorsCase = (*CliTemplateFlavorsInteractor)(nil)
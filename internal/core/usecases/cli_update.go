@@ -2,40 +2,85 @@ package usecases
 
 import (
 	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
 )
 
+type LocalUpdateOptions struct {
+	Tag    string
+	DryRun bool
+	// AllowRejects stores the new version even when some changes could not be applied
+	AllowRejects bool
+	// ToNext updates to the release right after the current one instead of the latest one
+	ToNext bool
+	// Stepwise applies every release between the current version and the target one in turn
+	Stepwise bool
+}
+
 type LocalUpdateCase interface {
-	LocalUpdate(target, uri, tag string) error
+	LocalUpdate(target, uri string, opts LocalUpdateOptions) ([]*entities.UpdatePlan, error)
 }
 
 type CliUpdateCase interface {
-	DoLocalUpdate(target, uri, tag, method string) error
+	DoLocalUpdate(target, uri, method string, opts LocalUpdateOptions) error
+	DoLocalUpdateAll(target, method string, opts LocalUpdateOptions) error
 }
 
 type CliUpdateInteractor struct {
-	copyCase LocalUpdateCase
-	diffCase LocalUpdateCase
+	copyCase  LocalUpdateCase
+	diffCase  LocalUpdateCase
+	mergeCase LocalUpdateCase
+	allCase   LocalUpdateAllCase
+	reporter  UpdateReporterPort
+}
+
+func (l *CliUpdateInteractor) DoLocalUpdate(target, uri, method string, opts LocalUpdateOptions) error {
+	useCase, err := l.useCaseFor(method, opts)
+	if err != nil {
+		return err
+	}
+	// plans are reported even on failure, so the user knows what was already changed
+	plans, err := useCase.LocalUpdate(target, uri, opts)
+	for _, plan := range plans {
+		l.reporter.ReportPlan(plan)
+	}
+	return err
 }
 
-func (l *CliUpdateInteractor) DoLocalUpdate(target, uri, tag, method string) error {
-	var useCase LocalUpdateCase
+func (l *CliUpdateInteractor) DoLocalUpdateAll(target, method string, opts LocalUpdateOptions) error {
+	useCase, err := l.useCaseFor(method, opts)
+	if err != nil {
+		return err
+	}
+	updates, err := l.allCase.LocalUpdateAll(target, useCase, opts)
+	for _, update := range updates {
+		for _, plan := range update.Plans {
+			l.reporter.ReportPlan(plan)
+		}
+	}
+	if len(updates) > 0 {
+		l.reporter.ReportSummary(updates)
+	}
+	return err
+}
+
+func (l *CliUpdateInteractor) useCaseFor(method string, opts LocalUpdateOptions) (LocalUpdateCase, error) {
+	if opts.Stepwise && method != "diff" {
+		return nil, fmt.Errorf("stepwise updates are only supported by the diff method")
+	}
 	switch method {
 	case "diff":
-		useCase = l.diffCase
+		return l.diffCase, nil
 	case "copy":
-		useCase = l.copyCase
+		return l.copyCase, nil
+	case "merge":
+		return l.mergeCase, nil
 	default:
-		return fmt.Errorf("method %s not supported", method)
-	}
-	err := useCase.LocalUpdate(target, uri, tag)
-	if err != nil {
-		return err
+		return nil, fmt.Errorf("method %s not supported", method)
 	}
-	return nil
 }
 
-func NewCliUpdateInteractor(copyCase LocalUpdateCase, diffCase LocalUpdateCase) *CliUpdateInteractor {
-	return &CliUpdateInteractor{copyCase: copyCase, diffCase: diffCase}
+func NewCliUpdateInteractor(copyCase LocalUpdateCase, diffCase LocalUpdateCase, mergeCase LocalUpdateCase, allCase LocalUpdateAllCase, reporter UpdateReporterPort) *CliUpdateInteractor {
+	return &CliUpdateInteractor{copyCase: copyCase, diffCase: diffCase, mergeCase: mergeCase, allCase: allCase, reporter: reporter}
 }
 
 var _ CliUpdateCase = (*CliUpdateInteractor)(nil)
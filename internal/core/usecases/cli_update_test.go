@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/usecases/cli_update.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/usecases/cli_update.go -destination=internal/core/usecases/cli_update_test.go -package=usecases
+//
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	reflect "reflect"
+
+	entities "github.com/sombrahq/sombra-cli/internal/core/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLocalUpdateCase is a mock of LocalUpdateCase interface.
+type MockLocalUpdateCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockLocalUpdateCaseMockRecorder
+	isgomock struct{}
+}
+
+// MockLocalUpdateCaseMockRecorder is the mock recorder for MockLocalUpdateCase.
+type MockLocalUpdateCaseMockRecorder struct {
+	mock *MockLocalUpdateCase
+}
+
+// NewMockLocalUpdateCase creates a new mock instance.
+func NewMockLocalUpdateCase(ctrl *gomock.Controller) *MockLocalUpdateCase {
+	mock := &MockLocalUpdateCase{ctrl: ctrl}
+	mock.recorder = &MockLocalUpdateCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLocalUpdateCase) EXPECT() *MockLocalUpdateCaseMockRecorder {
+	return m.recorder
+}
+
+// LocalUpdate mocks base method.
+func (m *MockLocalUpdateCase) LocalUpdate(target, uri string, opts LocalUpdateOptions) ([]*entities.UpdatePlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LocalUpdate", target, uri, opts)
+	ret0, _ := ret[0].([]*entities.UpdatePlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LocalUpdate indicates an expected call of LocalUpdate.
+func (mr *MockLocalUpdateCaseMockRecorder) LocalUpdate(target, uri, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalUpdate", reflect.TypeOf((*MockLocalUpdateCase)(nil).LocalUpdate), target, uri, opts)
+}
+
+// MockCliUpdateCase is a mock of CliUpdateCase interface.
+type MockCliUpdateCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockCliUpdateCaseMockRecorder
+	isgomock struct{}
+}
+
+// MockCliUpdateCaseMockRecorder is the mock recorder for MockCliUpdateCase.
+type MockCliUpdateCaseMockRecorder struct {
+	mock *MockCliUpdateCase
+}
+
+// NewMockCliUpdateCase creates a new mock instance.
+func NewMockCliUpdateCase(ctrl *gomock.Controller) *MockCliUpdateCase {
+	mock := &MockCliUpdateCase{ctrl: ctrl}
+	mock.recorder = &MockCliUpdateCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCliUpdateCase) EXPECT() *MockCliUpdateCaseMockRecorder {
+	return m.recorder
+}
+
+// DoLocalUpdate mocks base method.
+func (m *MockCliUpdateCase) DoLocalUpdate(target, uri, method string, opts LocalUpdateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoLocalUpdate", target, uri, method, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DoLocalUpdate indicates an expected call of DoLocalUpdate.
+func (mr *MockCliUpdateCaseMockRecorder) DoLocalUpdate(target, uri, method, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoLocalUpdate", reflect.TypeOf((*MockCliUpdateCase)(nil).DoLocalUpdate), target, uri, method, opts)
+}
+
+// DoLocalUpdateAll mocks base method.
+func (m *MockCliUpdateCase) DoLocalUpdateAll(target, method string, opts LocalUpdateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoLocalUpdateAll", target, method, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DoLocalUpdateAll indicates an expected call of DoLocalUpdateAll.
+func (mr *MockCliUpdateCaseMockRecorder) DoLocalUpdateAll(target, method, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoLocalUpdateAll", reflect.TypeOf((*MockCliUpdateCase)(nil).DoLocalUpdateAll), target, method, opts)
+}
@@ -0,0 +1,11 @@
+package usecases
+
+import "github.com/sombrahq/sombra-cli/internal/core/entities"
+
+// RepositoryCachePort manages the template repositories kept between runs
+type RepositoryCachePort interface {
+	List() ([]*entities.CacheEntry, error)
+	// Prune removes the entries not used in the given number of days
+	Prune(days int) ([]*entities.CacheEntry, error)
+	Clear() ([]*entities.CacheEntry, error)
+}
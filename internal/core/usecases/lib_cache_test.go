@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/usecases/lib_cache.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/usecases/lib_cache.go -destination=internal/core/usecases/lib_cache_test.go -package=usecases
+//
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	reflect "reflect"
+
+	entities "github.com/sombrahq/sombra-cli/internal/core/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepositoryCachePort is a mock of RepositoryCachePort interface.
+type MockRepositoryCachePort struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryCachePortMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryCachePortMockRecorder is the mock recorder for MockRepositoryCachePort.
+type MockRepositoryCachePortMockRecorder struct {
+	mock *MockRepositoryCachePort
+}
+
+// NewMockRepositoryCachePort creates a new mock instance.
+func NewMockRepositoryCachePort(ctrl *gomock.Controller) *MockRepositoryCachePort {
+	mock := &MockRepositoryCachePort{ctrl: ctrl}
+	mock.recorder = &MockRepositoryCachePortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepositoryCachePort) EXPECT() *MockRepositoryCachePortMockRecorder {
+	return m.recorder
+}
+
+// Clear mocks base method.
+func (m *MockRepositoryCachePort) Clear() ([]*entities.CacheEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clear")
+	ret0, _ := ret[0].([]*entities.CacheEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockRepositoryCachePortMockRecorder) Clear() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockRepositoryCachePort)(nil).Clear))
+}
+
+// List mocks base method.
+func (m *MockRepositoryCachePort) List() ([]*entities.CacheEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]*entities.CacheEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRepositoryCachePortMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepositoryCachePort)(nil).List))
+}
+
+// Prune mocks base method.
+func (m *MockRepositoryCachePort) Prune(days int) ([]*entities.CacheEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prune", days)
+	ret0, _ := ret[0].([]*entities.CacheEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prune indicates an expected call of Prune.
+func (mr *MockRepositoryCachePortMockRecorder) Prune(days any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockRepositoryCachePort)(nil).Prune), days)
+}
@@ -1,16 +1,32 @@
 package usecases
 
+import "github.com/sombrahq/sombra-cli/internal/core/entities"
+
 type RepositoryPort interface {
 	Clone() error
 	Clean() error
 	Dir() string
 	Use(version string) (string, error)
-	Diff(commit string) ([]byte, error)
+	// Diff compares the commit with the version in use, limited to the subdirectory and relative to it when it is set
+	Diff(commit, subdir string) ([]byte, error)
 	GetTags() ([]string, error)
+	// IsAncestor tells if the ancestor ref is part of the history of the commit ref
+	IsAncestor(ancestor, commit string) (bool, error)
 }
 
 type PatchPort interface {
-	Apply(dir string, patch []byte) error
+	Apply(dir string, patch []byte) (*entities.PatchResult, error)
+}
+
+// PatchTransformPort rewrites a template diff with the mappings of the patterns, returning the files it changes
+type PatchTransformPort interface {
+	Transform(patch []byte, patterns []*entities.Pattern) ([]byte, []*entities.FileChange, error)
+}
+
+// CredentialsPort finds the credentials of a template, from its auth settings or the global configuration.
+// It returns nil when the template has none, the ambient credentials of git are used then.
+type CredentialsPort interface {
+	Resolve(uri string, auth *entities.TemplateAuth) (*entities.Credentials, error)
 }
 
-type RepositoryFactory func(uri string) (RepositoryPort, error)
+type RepositoryFactory func(uri string, credentials *entities.Credentials) (RepositoryPort, error)
@@ -6,12 +6,13 @@
 //	mockgen -source=internal/core/usecases/lib_cvs.go -destination=internal/core/usecases/lib_cvs_test.go -package=usecases
 //
 
-// Package mocks is a generated GoMock package.
+// Package usecases is a generated GoMock package.
 package usecases
 
 import (
 	reflect "reflect"
 
+	entities "github.com/sombrahq/sombra-cli/internal/core/entities"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -68,18 +69,18 @@ func (mr *MockRepositoryPortMockRecorder) Clone() *gomock.Call {
 }
 
 // Diff mocks base method.
-func (m *MockRepositoryPort) Diff(commit string) ([]byte, error) {
+func (m *MockRepositoryPort) Diff(commit, subdir string) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Diff", commit)
+	ret := m.ctrl.Call(m, "Diff", commit, subdir)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Diff indicates an expected call of Diff.
-func (mr *MockRepositoryPortMockRecorder) Diff(commit any) *gomock.Call {
+func (mr *MockRepositoryPortMockRecorder) Diff(commit, subdir any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Diff", reflect.TypeOf((*MockRepositoryPort)(nil).Diff), commit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Diff", reflect.TypeOf((*MockRepositoryPort)(nil).Diff), commit, subdir)
 }
 
 // Dir mocks base method.
@@ -111,6 +112,21 @@ func (mr *MockRepositoryPortMockRecorder) GetTags() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockRepositoryPort)(nil).GetTags))
 }
 
+// IsAncestor mocks base method.
+func (m *MockRepositoryPort) IsAncestor(ancestor, commit string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAncestor", ancestor, commit)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAncestor indicates an expected call of IsAncestor.
+func (mr *MockRepositoryPortMockRecorder) IsAncestor(ancestor, commit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAncestor", reflect.TypeOf((*MockRepositoryPort)(nil).IsAncestor), ancestor, commit)
+}
+
 // Use mocks base method.
 func (m *MockRepositoryPort) Use(version string) (string, error) {
 	m.ctrl.T.Helper()
@@ -151,11 +167,12 @@ func (m *MockPatchPort) EXPECT() *MockPatchPortMockRecorder {
 }
 
 // Apply mocks base method.
-func (m *MockPatchPort) Apply(dir string, patch []byte) error {
+func (m *MockPatchPort) Apply(dir string, patch []byte) (*entities.PatchResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Apply", dir, patch)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*entities.PatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Apply indicates an expected call of Apply.
@@ -163,3 +180,82 @@ func (mr *MockPatchPortMockRecorder) Apply(dir, patch any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockPatchPort)(nil).Apply), dir, patch)
 }
+
+// MockPatchTransformPort is a mock of PatchTransformPort interface.
+type MockPatchTransformPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockPatchTransformPortMockRecorder
+	isgomock struct{}
+}
+
+// MockPatchTransformPortMockRecorder is the mock recorder for MockPatchTransformPort.
+type MockPatchTransformPortMockRecorder struct {
+	mock *MockPatchTransformPort
+}
+
+// NewMockPatchTransformPort creates a new mock instance.
+func NewMockPatchTransformPort(ctrl *gomock.Controller) *MockPatchTransformPort {
+	mock := &MockPatchTransformPort{ctrl: ctrl}
+	mock.recorder = &MockPatchTransformPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPatchTransformPort) EXPECT() *MockPatchTransformPortMockRecorder {
+	return m.recorder
+}
+
+// Transform mocks base method.
+func (m *MockPatchTransformPort) Transform(patch []byte, patterns []*entities.Pattern) ([]byte, []*entities.FileChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transform", patch, patterns)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].([]*entities.FileChange)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Transform indicates an expected call of Transform.
+func (mr *MockPatchTransformPortMockRecorder) Transform(patch, patterns any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transform", reflect.TypeOf((*MockPatchTransformPort)(nil).Transform), patch, patterns)
+}
+
+// MockCredentialsPort is a mock of CredentialsPort interface.
+type MockCredentialsPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockCredentialsPortMockRecorder
+	isgomock struct{}
+}
+
+// MockCredentialsPortMockRecorder is the mock recorder for MockCredentialsPort.
+type MockCredentialsPortMockRecorder struct {
+	mock *MockCredentialsPort
+}
+
+// NewMockCredentialsPort creates a new mock instance.
+func NewMockCredentialsPort(ctrl *gomock.Controller) *MockCredentialsPort {
+	mock := &MockCredentialsPort{ctrl: ctrl}
+	mock.recorder = &MockCredentialsPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCredentialsPort) EXPECT() *MockCredentialsPortMockRecorder {
+	return m.recorder
+}
+
+// Resolve mocks base method.
+func (m *MockCredentialsPort) Resolve(uri string, auth *entities.TemplateAuth) (*entities.Credentials, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", uri, auth)
+	ret0, _ := ret[0].(*entities.Credentials)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockCredentialsPortMockRecorder) Resolve(uri, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockCredentialsPort)(nil).Resolve), uri, auth)
+}
@@ -12,6 +12,17 @@ type DirectoryManagerPort interface {
 
 type FileManagerPort interface {
 	EnsureDir(dir string, fn entities.File) error
+	Exists(dir string, fn entities.File) bool
 	Read(dir string, fn entities.File) ([]byte, error)
 	Write(dir string, fn entities.File, content []byte) error
+	Remove(dir string, fn entities.File) error
+}
+
+// SnapshotPort keeps a copy of a directory so it can be restored when an update fails
+type SnapshotPort interface {
+	Snapshot(dir string) (string, error)
+	// Update copies the paths of the directory to the snapshot, the paths missing from the directory are removed from it
+	Update(dir, snapshot string, paths []string) error
+	Restore(dir, snapshot string) error
+	Discard(snapshot string) error
 }
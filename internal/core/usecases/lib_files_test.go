@@ -6,7 +6,7 @@
 //	mockgen -source=internal/core/usecases/lib_files.go -destination=internal/core/usecases/lib_files_test.go -package=usecases
 //
 
-// Package usecases_test is a generated GoMock package.
+// Package usecases is a generated GoMock package.
 package usecases
 
 import (
@@ -122,6 +122,20 @@ func (mr *MockFileManagerPortMockRecorder) EnsureDir(dir, fn any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureDir", reflect.TypeOf((*MockFileManagerPort)(nil).EnsureDir), dir, fn)
 }
 
+// Exists mocks base method.
+func (m *MockFileManagerPort) Exists(dir string, fn entities.File) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", dir, fn)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockFileManagerPortMockRecorder) Exists(dir, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockFileManagerPort)(nil).Exists), dir, fn)
+}
+
 // Read mocks base method.
 func (m *MockFileManagerPort) Read(dir string, fn entities.File) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -137,6 +151,20 @@ func (mr *MockFileManagerPortMockRecorder) Read(dir, fn any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockFileManagerPort)(nil).Read), dir, fn)
 }
 
+// Remove mocks base method.
+func (m *MockFileManagerPort) Remove(dir string, fn entities.File) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", dir, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockFileManagerPortMockRecorder) Remove(dir, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockFileManagerPort)(nil).Remove), dir, fn)
+}
+
 // Write mocks base method.
 func (m *MockFileManagerPort) Write(dir string, fn entities.File, content []byte) error {
 	m.ctrl.T.Helper()
@@ -150,3 +178,84 @@ func (mr *MockFileManagerPortMockRecorder) Write(dir, fn, content any) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockFileManagerPort)(nil).Write), dir, fn, content)
 }
+
+// MockSnapshotPort is a mock of SnapshotPort interface.
+type MockSnapshotPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockSnapshotPortMockRecorder
+	isgomock struct{}
+}
+
+// MockSnapshotPortMockRecorder is the mock recorder for MockSnapshotPort.
+type MockSnapshotPortMockRecorder struct {
+	mock *MockSnapshotPort
+}
+
+// NewMockSnapshotPort creates a new mock instance.
+func NewMockSnapshotPort(ctrl *gomock.Controller) *MockSnapshotPort {
+	mock := &MockSnapshotPort{ctrl: ctrl}
+	mock.recorder = &MockSnapshotPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSnapshotPort) EXPECT() *MockSnapshotPortMockRecorder {
+	return m.recorder
+}
+
+// Discard mocks base method.
+func (m *MockSnapshotPort) Discard(snapshot string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Discard", snapshot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Discard indicates an expected call of Discard.
+func (mr *MockSnapshotPortMockRecorder) Discard(snapshot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Discard", reflect.TypeOf((*MockSnapshotPort)(nil).Discard), snapshot)
+}
+
+// Restore mocks base method.
+func (m *MockSnapshotPort) Restore(dir, snapshot string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", dir, snapshot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockSnapshotPortMockRecorder) Restore(dir, snapshot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockSnapshotPort)(nil).Restore), dir, snapshot)
+}
+
+// Snapshot mocks base method.
+func (m *MockSnapshotPort) Snapshot(dir string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", dir)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockSnapshotPortMockRecorder) Snapshot(dir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockSnapshotPort)(nil).Snapshot), dir)
+}
+
+// Update mocks base method.
+func (m *MockSnapshotPort) Update(dir, snapshot string, paths []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", dir, snapshot, paths)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockSnapshotPortMockRecorder) Update(dir, snapshot, paths any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSnapshotPort)(nil).Update), dir, snapshot, paths)
+}
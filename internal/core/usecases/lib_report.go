@@ -0,0 +1,21 @@
+package usecases
+
+import "github.com/sombrahq/sombra-cli/internal/core/entities"
+
+type UpdateReporterPort interface {
+	ReportPlan(plan *entities.UpdatePlan)
+	ReportSummary(updates []*entities.TemplateUpdate)
+}
+
+type DiffReporterPort interface {
+	ReportDiff(diff *entities.TemplateDiff, stat bool)
+}
+
+type TemplateReporterPort interface {
+	ReportFlavors(uri string, flavors []string)
+}
+
+type CacheReporterPort interface {
+	ReportCache(entries []*entities.CacheEntry)
+	ReportCacheRemoved(entries []*entities.CacheEntry)
+}
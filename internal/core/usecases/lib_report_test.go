@@ -0,0 +1,185 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/usecases/lib_report.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/usecases/lib_report.go -destination=internal/core/usecases/lib_report_test.go -package=usecases
+//
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	reflect "reflect"
+
+	entities "github.com/sombrahq/sombra-cli/internal/core/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUpdateReporterPort is a mock of UpdateReporterPort interface.
+type MockUpdateReporterPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockUpdateReporterPortMockRecorder
+	isgomock struct{}
+}
+
+// MockUpdateReporterPortMockRecorder is the mock recorder for MockUpdateReporterPort.
+type MockUpdateReporterPortMockRecorder struct {
+	mock *MockUpdateReporterPort
+}
+
+// NewMockUpdateReporterPort creates a new mock instance.
+func NewMockUpdateReporterPort(ctrl *gomock.Controller) *MockUpdateReporterPort {
+	mock := &MockUpdateReporterPort{ctrl: ctrl}
+	mock.recorder = &MockUpdateReporterPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUpdateReporterPort) EXPECT() *MockUpdateReporterPortMockRecorder {
+	return m.recorder
+}
+
+// ReportPlan mocks base method.
+func (m *MockUpdateReporterPort) ReportPlan(plan *entities.UpdatePlan) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportPlan", plan)
+}
+
+// ReportPlan indicates an expected call of ReportPlan.
+func (mr *MockUpdateReporterPortMockRecorder) ReportPlan(plan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportPlan", reflect.TypeOf((*MockUpdateReporterPort)(nil).ReportPlan), plan)
+}
+
+// ReportSummary mocks base method.
+func (m *MockUpdateReporterPort) ReportSummary(updates []*entities.TemplateUpdate) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportSummary", updates)
+}
+
+// ReportSummary indicates an expected call of ReportSummary.
+func (mr *MockUpdateReporterPortMockRecorder) ReportSummary(updates any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportSummary", reflect.TypeOf((*MockUpdateReporterPort)(nil).ReportSummary), updates)
+}
+
+// MockDiffReporterPort is a mock of DiffReporterPort interface.
+type MockDiffReporterPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockDiffReporterPortMockRecorder
+	isgomock struct{}
+}
+
+// MockDiffReporterPortMockRecorder is the mock recorder for MockDiffReporterPort.
+type MockDiffReporterPortMockRecorder struct {
+	mock *MockDiffReporterPort
+}
+
+// NewMockDiffReporterPort creates a new mock instance.
+func NewMockDiffReporterPort(ctrl *gomock.Controller) *MockDiffReporterPort {
+	mock := &MockDiffReporterPort{ctrl: ctrl}
+	mock.recorder = &MockDiffReporterPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDiffReporterPort) EXPECT() *MockDiffReporterPortMockRecorder {
+	return m.recorder
+}
+
+// ReportDiff mocks base method.
+func (m *MockDiffReporterPort) ReportDiff(diff *entities.TemplateDiff, stat bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportDiff", diff, stat)
+}
+
+// ReportDiff indicates an expected call of ReportDiff.
+func (mr *MockDiffReporterPortMockRecorder) ReportDiff(diff, stat any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportDiff", reflect.TypeOf((*MockDiffReporterPort)(nil).ReportDiff), diff, stat)
+}
+
+// MockTemplateReporterPort is a mock of TemplateReporterPort interface.
+type MockTemplateReporterPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockTemplateReporterPortMockRecorder
+	isgomock struct{}
+}
+
+// MockTemplateReporterPortMockRecorder is the mock recorder for MockTemplateReporterPort.
+type MockTemplateReporterPortMockRecorder struct {
+	mock *MockTemplateReporterPort
+}
+
+// NewMockTemplateReporterPort creates a new mock instance.
+func NewMockTemplateReporterPort(ctrl *gomock.Controller) *MockTemplateReporterPort {
+	mock := &MockTemplateReporterPort{ctrl: ctrl}
+	mock.recorder = &MockTemplateReporterPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTemplateReporterPort) EXPECT() *MockTemplateReporterPortMockRecorder {
+	return m.recorder
+}
+
+// ReportFlavors mocks base method.
+func (m *MockTemplateReporterPort) ReportFlavors(uri string, flavors []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportFlavors", uri, flavors)
+}
+
+// ReportFlavors indicates an expected call of ReportFlavors.
+func (mr *MockTemplateReporterPortMockRecorder) ReportFlavors(uri, flavors any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportFlavors", reflect.TypeOf((*MockTemplateReporterPort)(nil).ReportFlavors), uri, flavors)
+}
+
+// MockCacheReporterPort is a mock of CacheReporterPort interface.
+type MockCacheReporterPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockCacheReporterPortMockRecorder
+	isgomock struct{}
+}
+
+// MockCacheReporterPortMockRecorder is the mock recorder for MockCacheReporterPort.
+type MockCacheReporterPortMockRecorder struct {
+	mock *MockCacheReporterPort
+}
+
+// NewMockCacheReporterPort creates a new mock instance.
+func NewMockCacheReporterPort(ctrl *gomock.Controller) *MockCacheReporterPort {
+	mock := &MockCacheReporterPort{ctrl: ctrl}
+	mock.recorder = &MockCacheReporterPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCacheReporterPort) EXPECT() *MockCacheReporterPortMockRecorder {
+	return m.recorder
+}
+
+// ReportCache mocks base method.
+func (m *MockCacheReporterPort) ReportCache(entries []*entities.CacheEntry) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportCache", entries)
+}
+
+// ReportCache indicates an expected call of ReportCache.
+func (mr *MockCacheReporterPortMockRecorder) ReportCache(entries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportCache", reflect.TypeOf((*MockCacheReporterPort)(nil).ReportCache), entries)
+}
+
+// ReportCacheRemoved mocks base method.
+func (m *MockCacheReporterPort) ReportCacheRemoved(entries []*entities.CacheEntry) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportCacheRemoved", entries)
+}
+
+// ReportCacheRemoved indicates an expected call of ReportCacheRemoved.
+func (mr *MockCacheReporterPortMockRecorder) ReportCacheRemoved(entries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportCacheRemoved", reflect.TypeOf((*MockCacheReporterPort)(nil).ReportCacheRemoved), entries)
+}
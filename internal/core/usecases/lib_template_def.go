@@ -3,7 +3,12 @@ package usecases
 import "github.com/sombrahq/sombra-cli/internal/core/entities"
 
 type TemplateDefManagerPort interface {
-	GetFile(dir string) entities.File
+	// GetFile returns the definition of the flavor, the default one when the flavor is empty
+	GetFile(dir, flavor string) entities.File
+	// ListFlavors returns the names of the definitions of the template
+	ListFlavors(dir string) ([]string, error)
+	// RenderValue renders a Go template of the definition, like the default of a variable
+	RenderValue(value string, vars entities.Mappings) (string, error)
 	Load(def entities.File) (*entities.TemplateDef, error)
 	Save(def entities.File, templateDef *entities.TemplateDef) error
 	Render(def entities.File, vars entities.Mappings) (*entities.TemplateDef, error)
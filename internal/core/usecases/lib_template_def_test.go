@@ -41,17 +41,32 @@ func (m *MockTemplateDefManagerPort) EXPECT() *MockTemplateDefManagerPortMockRec
 }
 
 // GetFile mocks base method.
-func (m *MockTemplateDefManagerPort) GetFile(dir string) entities.File {
+func (m *MockTemplateDefManagerPort) GetFile(dir, flavor string) entities.File {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFile", dir)
+	ret := m.ctrl.Call(m, "GetFile", dir, flavor)
 	ret0, _ := ret[0].(entities.File)
 	return ret0
 }
 
 // GetFile indicates an expected call of GetFile.
-func (mr *MockTemplateDefManagerPortMockRecorder) GetFile(dir any) *gomock.Call {
+func (mr *MockTemplateDefManagerPortMockRecorder) GetFile(dir, flavor any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFile", reflect.TypeOf((*MockTemplateDefManagerPort)(nil).GetFile), dir)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFile", reflect.TypeOf((*MockTemplateDefManagerPort)(nil).GetFile), dir, flavor)
+}
+
+// ListFlavors mocks base method.
+func (m *MockTemplateDefManagerPort) ListFlavors(dir string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFlavors", dir)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFlavors indicates an expected call of ListFlavors.
+func (mr *MockTemplateDefManagerPortMockRecorder) ListFlavors(dir any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFlavors", reflect.TypeOf((*MockTemplateDefManagerPort)(nil).ListFlavors), dir)
 }
 
 // Load mocks base method.
@@ -84,6 +99,21 @@ func (mr *MockTemplateDefManagerPortMockRecorder) Render(def, vars any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Render", reflect.TypeOf((*MockTemplateDefManagerPort)(nil).Render), def, vars)
 }
 
+// RenderValue mocks base method.
+func (m *MockTemplateDefManagerPort) RenderValue(value string, vars entities.Mappings) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderValue", value, vars)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderValue indicates an expected call of RenderValue.
+func (mr *MockTemplateDefManagerPortMockRecorder) RenderValue(value, vars any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderValue", reflect.TypeOf((*MockTemplateDefManagerPort)(nil).RenderValue), value, vars)
+}
+
 // Save mocks base method.
 func (m *MockTemplateDefManagerPort) Save(def entities.File, templateDef *entities.TemplateDef) error {
 	m.ctrl.T.Helper()
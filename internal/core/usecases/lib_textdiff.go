@@ -0,0 +1,11 @@
+package usecases
+
+import "github.com/sombrahq/sombra-cli/internal/core/entities"
+
+type TextDiffPort interface {
+	Unified(fromFile, toFile string, old, new []byte) *entities.TextDiff
+}
+
+type MergePort interface {
+	Merge(base, ours, theirs []byte, oursLabel, theirsLabel string) *entities.MergeResult
+}
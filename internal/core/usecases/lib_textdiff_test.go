@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/usecases/lib_textdiff.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/usecases/lib_textdiff.go -destination=internal/core/usecases/lib_textdiff_test.go -package=usecases
+//
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	reflect "reflect"
+
+	entities "github.com/sombrahq/sombra-cli/internal/core/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTextDiffPort is a mock of TextDiffPort interface.
+type MockTextDiffPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockTextDiffPortMockRecorder
+	isgomock struct{}
+}
+
+// MockTextDiffPortMockRecorder is the mock recorder for MockTextDiffPort.
+type MockTextDiffPortMockRecorder struct {
+	mock *MockTextDiffPort
+}
+
+// NewMockTextDiffPort creates a new mock instance.
+func NewMockTextDiffPort(ctrl *gomock.Controller) *MockTextDiffPort {
+	mock := &MockTextDiffPort{ctrl: ctrl}
+	mock.recorder = &MockTextDiffPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTextDiffPort) EXPECT() *MockTextDiffPortMockRecorder {
+	return m.recorder
+}
+
+// Unified mocks base method.
+func (m *MockTextDiffPort) Unified(fromFile, toFile string, old, new []byte) *entities.TextDiff {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unified", fromFile, toFile, old, new)
+	ret0, _ := ret[0].(*entities.TextDiff)
+	return ret0
+}
+
+// Unified indicates an expected call of Unified.
+func (mr *MockTextDiffPortMockRecorder) Unified(fromFile, toFile, old, new any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unified", reflect.TypeOf((*MockTextDiffPort)(nil).Unified), fromFile, toFile, old, new)
+}
+
+// MockMergePort is a mock of MergePort interface.
+type MockMergePort struct {
+	ctrl     *gomock.Controller
+	recorder *MockMergePortMockRecorder
+	isgomock struct{}
+}
+
+// MockMergePortMockRecorder is the mock recorder for MockMergePort.
+type MockMergePortMockRecorder struct {
+	mock *MockMergePort
+}
+
+// NewMockMergePort creates a new mock instance.
+func NewMockMergePort(ctrl *gomock.Controller) *MockMergePort {
+	mock := &MockMergePort{ctrl: ctrl}
+	mock.recorder = &MockMergePortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMergePort) EXPECT() *MockMergePortMockRecorder {
+	return m.recorder
+}
+
+// Merge mocks base method.
+func (m *MockMergePort) Merge(base, ours, theirs []byte, oursLabel, theirsLabel string) *entities.MergeResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", base, ours, theirs, oursLabel, theirsLabel)
+	ret0, _ := ret[0].(*entities.MergeResult)
+	return ret0
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockMergePortMockRecorder) Merge(base, ours, theirs, oursLabel, theirsLabel any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockMergePort)(nil).Merge), base, ours, theirs, oursLabel, theirsLabel)
+}
@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"path/filepath"
+	"strings"
+)
+
+type LocalDiffCase interface {
+	LocalDiff(target, uri, tag string) ([]*entities.TemplateDiff, error)
+}
+
+type LocalDiffInteractor struct {
+	repoPrepare        RepositoryPrepareCase
+	templateDefManager TemplateDefManagerPort
+	sombraDefManager   SombraDefManagerPort
+	versionManager     VersionManagerPort
+	localFiles         FileManagerPort
+	render             TemplateRenderCase
+	textDiff           TextDiffPort
+}
+
+func NewLocalDiffInteractor(
+	repoPrepare RepositoryPrepareCase,
+	templateDefManager TemplateDefManagerPort,
+	sombraDefManager SombraDefManagerPort,
+	versionManager VersionManagerPort,
+	localFiles FileManagerPort,
+	render TemplateRenderCase,
+	textDiff TextDiffPort,
+) *LocalDiffInteractor {
+	return &LocalDiffInteractor{
+		repoPrepare:        repoPrepare,
+		templateDefManager: templateDefManager,
+		sombraDefManager:   sombraDefManager,
+		versionManager:     versionManager,
+		localFiles:         localFiles,
+		render:             render,
+		textDiff:           textDiff,
+	}
+}
+
+// LocalDiff renders the template in memory and compares it with the files in the project, nothing is written
+func (l *LocalDiffInteractor) LocalDiff(target, uri, tag string) ([]*entities.TemplateDiff, error) {
+	// Read sombra file
+	sombraFile := l.sombraDefManager.GetFile(target)
+	def, err := l.sombraDefManager.Load(sombraFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Download and prepare the version, a template in a subdirectory clones the whole repository
+	repoURI, _ := entities.SplitSubdir(uri)
+	repo, err := l.repoPrepare.Prepare(repoURI, "", templateAuth(def, uri))
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Clean()
+
+	// If the tag variable is empty, each template looks for its version in the tags
+	var version entities.Version
+	var tags []string
+	if tag == "" {
+		tags, err = repo.GetTags()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Iterate over all templates
+	var tpl *entities.TemplateDef
+	var files []*entities.RenderedFile
+	var fileDiffs []*entities.FileDiff
+	res := make([]*entities.TemplateDiff, 0)
+	for _, template := range def.Templates {
+		if template.URI != uri {
+			continue
+		}
+
+		// the same version that the update would apply
+		version, err = targetVersion(l.versionManager, repo, tags, template, LocalUpdateOptions{Tag: tag})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = repo.Use(string(version))
+		if err != nil {
+			return nil, err
+		}
+
+		// Render TemplateConfig Definition using Sombra configuration
+		dir := templateDir(repo, template)
+		fn := l.templateDefManager.GetFile(dir, template.Flavor)
+		tpl, err = l.templateDefManager.Render(fn, template.Vars)
+		if err != nil {
+			return nil, err
+		}
+
+		files, err = l.render.RenderTree(dir, tpl, template.Vars)
+		if err != nil {
+			return nil, err
+		}
+
+		fileDiffs, err = l.compareFiles(target, template.Path, files)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, &entities.TemplateDiff{
+			URI:     uri,
+			Path:    template.Path,
+			Version: version,
+			Files:   fileDiffs,
+		})
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("template %s not found in %s", uri, sombraFile)
+	}
+
+	return res, nil
+}
+
+func (l *LocalDiffInteractor) compareFiles(target, path string, files []*entities.RenderedFile) ([]*entities.FileDiff, error) {
+	targetDir := filepath.Join(target, path)
+	res := make([]*entities.FileDiff, 0)
+	for _, file := range files {
+		// names in the patch are relative to the project root
+		name := strings.TrimPrefix(filepath.ToSlash(filepath.Join(path, string(file.File))), "/")
+
+		if !l.localFiles.Exists(targetDir, file.File) {
+			res = append(res, &entities.FileDiff{
+				Operation: entities.FileCreate,
+				File:      file.File,
+				Diff:      l.textDiff.Unified("/dev/null", "b/"+name, nil, file.Content),
+			})
+			continue
+		}
+
+		current, err := l.localFiles.Read(targetDir, file.File)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(current, file.Content) {
+			continue
+		}
+
+		res = append(res, &entities.FileDiff{
+			Operation: entities.FileModify,
+			File:      file.File,
+			Diff:      l.textDiff.Unified("a/"+name, "b/"+name, current, file.Content),
+		})
+	}
+	return res, nil
+}
+
+var _ LocalDiffCase = (*LocalDiffInteractor)(nil)
@@ -0,0 +1,266 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"go.uber.org/mock/gomock"
+)
+
+type localDiffMocks struct {
+	repo               *MockRepositoryPort
+	repoPrepare        *MockRepositoryPrepareCase
+	templateDefManager *MockTemplateDefManagerPort
+	sombraDefManager   *MockSombraDefManagerPort
+	versionManager     *MockVersionManagerPort
+	localFiles         *MockFileManagerPort
+	render             *MockTemplateRenderCase
+	textDiff           *MockTextDiffPort
+}
+
+func TestLocalDiffInteractor_LocalDiff(t *testing.T) {
+	sombraFile := entities.File("/path/to/project/sombra.yaml")
+	templateFile := entities.File("/tmp/repo/.sombra/default.yaml")
+	tplDef := &entities.TemplateDef{
+		Patterns: []*entities.Pattern{
+			{Pattern: "**/*"},
+		},
+	}
+	sombraDef := func() *entities.SombraDef {
+		return &entities.SombraDef{
+			Templates: []*entities.TemplateConfig{
+				{
+					URI:     "github.com/user/repo",
+					Path:    "src",
+					Current: "v0.9.0",
+					Vars: entities.Mappings{
+						"projectName": "test-project",
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		uri         string
+		tag         string
+		setup       func(m *localDiffMocks)
+		shouldError bool
+		errorMsg    string
+		check       func(t *testing.T, diffs []*entities.TemplateDiff)
+	}{
+		{
+			name: "successful diff with explicit tag",
+			uri:  "github.com/user/repo",
+			tag:  "v1.0.0",
+			setup: func(m *localDiffMocks) {
+				m.sombraDefManager.EXPECT().GetFile("/path/to/project").Return(sombraFile)
+				m.sombraDefManager.EXPECT().Load(sombraFile).Return(sombraDef(), nil)
+				m.repoPrepare.EXPECT().Prepare("github.com/user/repo", "", nil).Return(m.repo, nil)
+				m.repo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				m.repo.EXPECT().Clean().Return(nil)
+				m.repo.EXPECT().Use("v1.0.0").Return("v1.0.0", nil)
+				m.templateDefManager.EXPECT().GetFile("/tmp/repo", "").Return(templateFile)
+				m.templateDefManager.EXPECT().Render(templateFile, gomock.Any()).Return(tplDef, nil)
+
+				m.render.EXPECT().RenderTree("/tmp/repo", tplDef, gomock.Any()).Return([]*entities.RenderedFile{
+					{Source: "/main.go", File: "/main.go", Content: []byte("new main")},
+					{Source: "/new.go", File: "/new.go", Content: []byte("new file")},
+					{Source: "/same.go", File: "/same.go", Content: []byte("same")},
+				}, nil)
+
+				m.localFiles.EXPECT().Exists("/path/to/project/src", entities.File("/main.go")).Return(true)
+				m.localFiles.EXPECT().Read("/path/to/project/src", entities.File("/main.go")).Return([]byte("old main"), nil)
+				m.localFiles.EXPECT().Exists("/path/to/project/src", entities.File("/new.go")).Return(false)
+				m.localFiles.EXPECT().Exists("/path/to/project/src", entities.File("/same.go")).Return(true)
+				m.localFiles.EXPECT().Read("/path/to/project/src", entities.File("/same.go")).Return([]byte("same"), nil)
+
+				m.textDiff.EXPECT().
+					Unified("a/src/main.go", "b/src/main.go", []byte("old main"), []byte("new main")).
+					Return(&entities.TextDiff{Patch: []byte("main patch"), Added: 1, Removed: 1})
+				m.textDiff.EXPECT().
+					Unified("/dev/null", "b/src/new.go", nil, []byte("new file")).
+					Return(&entities.TextDiff{Patch: []byte("new patch"), Added: 1})
+			},
+			check: func(t *testing.T, diffs []*entities.TemplateDiff) {
+				if len(diffs) != 1 {
+					t.Fatalf("Expected 1 diff, got %d", len(diffs))
+				}
+				if diffs[0].Version != "v1.0.0" || diffs[0].Path != "src" {
+					t.Errorf("Unexpected template diff %+v", diffs[0])
+				}
+				files := diffs[0].Files
+				if len(files) != 2 {
+					t.Fatalf("Expected 2 files, got %d", len(files))
+				}
+				if files[0].Operation != entities.FileModify || string(files[0].Diff.Patch) != "main patch" {
+					t.Errorf("Unexpected diff for main.go %+v", files[0])
+				}
+				if files[1].Operation != entities.FileCreate || string(files[1].Diff.Patch) != "new patch" {
+					t.Errorf("Unexpected diff for new.go %+v", files[1])
+				}
+			},
+		},
+		{
+			name: "latest tag is used when no tag is provided",
+			uri:  "github.com/user/repo",
+			tag:  "",
+			setup: func(m *localDiffMocks) {
+				m.sombraDefManager.EXPECT().GetFile("/path/to/project").Return(sombraFile)
+				m.sombraDefManager.EXPECT().Load(sombraFile).Return(sombraDef(), nil)
+				m.repoPrepare.EXPECT().Prepare("github.com/user/repo", "", nil).Return(m.repo, nil)
+				m.repo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				m.repo.EXPECT().Clean().Return(nil)
+				m.repo.EXPECT().GetTags().Return([]string{"v0.9.0", "v1.1.0"}, nil)
+				m.versionManager.EXPECT().GetLatest([]string{"v0.9.0", "v1.1.0"}, "*").Return(entities.Version("v1.1.0"), nil)
+				m.repo.EXPECT().Use("v1.1.0").Return("v1.1.0", nil)
+				m.templateDefManager.EXPECT().GetFile("/tmp/repo", "").Return(templateFile)
+				m.templateDefManager.EXPECT().Render(templateFile, gomock.Any()).Return(tplDef, nil)
+				m.render.EXPECT().RenderTree("/tmp/repo", tplDef, gomock.Any()).Return([]*entities.RenderedFile{}, nil)
+			},
+			check: func(t *testing.T, diffs []*entities.TemplateDiff) {
+				if len(diffs) != 1 || diffs[0].Version != "v1.1.0" || len(diffs[0].Files) != 0 {
+					t.Errorf("Unexpected diffs %+v", diffs)
+				}
+			},
+		},
+		{
+			name: "version constraint of the template is kept",
+			uri:  "github.com/user/repo",
+			setup: func(m *localDiffMocks) {
+				def := sombraDef()
+				def.Templates[0].Constraint = "~0.9"
+				m.sombraDefManager.EXPECT().GetFile("/path/to/project").Return(sombraFile)
+				m.sombraDefManager.EXPECT().Load(sombraFile).Return(def, nil)
+				m.repoPrepare.EXPECT().Prepare("github.com/user/repo", "", nil).Return(m.repo, nil)
+				m.repo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				m.repo.EXPECT().Clean().Return(nil)
+				m.repo.EXPECT().GetTags().Return([]string{"v0.9.0", "v0.9.1", "v1.1.0"}, nil)
+				m.versionManager.EXPECT().GetLatest([]string{"v0.9.0", "v0.9.1", "v1.1.0"}, "~0.9").Return(entities.Version("v0.9.1"), nil)
+				m.repo.EXPECT().Use("v0.9.1").Return("v0.9.1", nil)
+				m.templateDefManager.EXPECT().GetFile("/tmp/repo", "").Return(templateFile)
+				m.templateDefManager.EXPECT().Render(templateFile, gomock.Any()).Return(tplDef, nil)
+				m.render.EXPECT().RenderTree("/tmp/repo", tplDef, gomock.Any()).Return([]*entities.RenderedFile{}, nil)
+			},
+			check: func(t *testing.T, diffs []*entities.TemplateDiff) {
+				if len(diffs) != 1 || diffs[0].Version != "v0.9.1" {
+					t.Errorf("Unexpected diffs %+v", diffs)
+				}
+			},
+		},
+		{
+			name: "branch of the template is followed",
+			uri:  "github.com/user/repo",
+			setup: func(m *localDiffMocks) {
+				def := sombraDef()
+				def.Templates[0].Branch = "main"
+				m.sombraDefManager.EXPECT().GetFile("/path/to/project").Return(sombraFile)
+				m.sombraDefManager.EXPECT().Load(sombraFile).Return(def, nil)
+				m.repoPrepare.EXPECT().Prepare("github.com/user/repo", "", nil).Return(m.repo, nil)
+				m.repo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				m.repo.EXPECT().Clean().Return(nil)
+				m.repo.EXPECT().GetTags().Return([]string{"v0.9.0"}, nil)
+				m.repo.EXPECT().Use("main").Return("4f2a9c1", nil)
+				m.repo.EXPECT().Use("4f2a9c1").Return("4f2a9c1", nil)
+				m.templateDefManager.EXPECT().GetFile("/tmp/repo", "").Return(templateFile)
+				m.templateDefManager.EXPECT().Render(templateFile, gomock.Any()).Return(tplDef, nil)
+				m.render.EXPECT().RenderTree("/tmp/repo", tplDef, gomock.Any()).Return([]*entities.RenderedFile{}, nil)
+			},
+			check: func(t *testing.T, diffs []*entities.TemplateDiff) {
+				if len(diffs) != 1 || diffs[0].Version != "4f2a9c1" {
+					t.Errorf("Unexpected diffs %+v", diffs)
+				}
+			},
+		},
+		{
+			name: "template not configured in sombra file",
+			uri:  "github.com/other/repo",
+			tag:  "v1.0.0",
+			setup: func(m *localDiffMocks) {
+				m.sombraDefManager.EXPECT().GetFile("/path/to/project").Return(sombraFile)
+				m.sombraDefManager.EXPECT().Load(sombraFile).Return(sombraDef(), nil)
+				m.repoPrepare.EXPECT().Prepare("github.com/other/repo", "", nil).Return(m.repo, nil)
+				m.repo.EXPECT().Clean().Return(nil)
+			},
+			shouldError: true,
+			errorMsg:    "template github.com/other/repo not found in /path/to/project/sombra.yaml",
+		},
+		{
+			name: "repository use version failure",
+			uri:  "github.com/user/repo",
+			tag:  "v1.0.0",
+			setup: func(m *localDiffMocks) {
+				m.sombraDefManager.EXPECT().GetFile("/path/to/project").Return(sombraFile)
+				m.sombraDefManager.EXPECT().Load(sombraFile).Return(sombraDef(), nil)
+				m.repoPrepare.EXPECT().Prepare("github.com/user/repo", "", nil).Return(m.repo, nil)
+				m.repo.EXPECT().Clean().Return(nil)
+				m.repo.EXPECT().Use("v1.0.0").Return("", errors.New("unknown revision"))
+			},
+			shouldError: true,
+			errorMsg:    "unknown revision",
+		},
+		{
+			name: "render tree failure",
+			uri:  "github.com/user/repo",
+			tag:  "v1.0.0",
+			setup: func(m *localDiffMocks) {
+				m.sombraDefManager.EXPECT().GetFile("/path/to/project").Return(sombraFile)
+				m.sombraDefManager.EXPECT().Load(sombraFile).Return(sombraDef(), nil)
+				m.repoPrepare.EXPECT().Prepare("github.com/user/repo", "", nil).Return(m.repo, nil)
+				m.repo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				m.repo.EXPECT().Clean().Return(nil)
+				m.repo.EXPECT().Use("v1.0.0").Return("v1.0.0", nil)
+				m.templateDefManager.EXPECT().GetFile("/tmp/repo", "").Return(templateFile)
+				m.templateDefManager.EXPECT().Render(templateFile, gomock.Any()).Return(tplDef, nil)
+				m.render.EXPECT().RenderTree("/tmp/repo", tplDef, gomock.Any()).Return(nil, errors.New("scan error"))
+			},
+			shouldError: true,
+			errorMsg:    "scan error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := &localDiffMocks{
+				repo:               NewMockRepositoryPort(ctrl),
+				repoPrepare:        NewMockRepositoryPrepareCase(ctrl),
+				templateDefManager: NewMockTemplateDefManagerPort(ctrl),
+				sombraDefManager:   NewMockSombraDefManagerPort(ctrl),
+				versionManager:     NewMockVersionManagerPort(ctrl),
+				localFiles:         NewMockFileManagerPort(ctrl),
+				render:             NewMockTemplateRenderCase(ctrl),
+				textDiff:           NewMockTextDiffPort(ctrl),
+			}
+			tt.setup(m)
+
+			interactor := NewLocalDiffInteractor(
+				m.repoPrepare,
+				m.templateDefManager,
+				m.sombraDefManager,
+				m.versionManager,
+				m.localFiles,
+				m.render,
+				m.textDiff,
+			)
+
+			diffs, err := interactor.LocalDiff("/path/to/project", tt.uri, tt.tag)
+
+			if (err != nil) != tt.shouldError {
+				t.Errorf("LocalDiff() error = %v, shouldError = %v", err, tt.shouldError)
+			}
+
+			if tt.shouldError && err != nil && tt.errorMsg != "" && err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message %q, got %q", tt.errorMsg, err.Error())
+			}
+
+			if tt.check != nil {
+				tt.check(t, diffs)
+			}
+		})
+	}
+}
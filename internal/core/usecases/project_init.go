@@ -1,15 +1,20 @@
 package usecases
 
 import (
+	"fmt"
 	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"path/filepath"
+	"strings"
 )
 
 type VariableReaderPort interface {
-	GetValues(vars []string) *entities.Mappings
+	// GetValue reads the value of the variable, def is proposed when it is not empty.
+	// ok is false when the source has no value, a source asking again checks the new values with validate.
+	GetValue(v *entities.Var, def string, validate func(string) error) (value string, ok bool, err error)
 }
 
 type LocalInitCase interface {
-	LocalInit(target, uri string) error
+	LocalInit(target, uri, flavor string) error
 }
 
 type LocalInitInteractor struct {
@@ -33,40 +38,54 @@ func NewLocalInitInteractor(
 	}
 }
 
-func (l *LocalInitInteractor) LocalInit(target, uri string) error {
-	// Download and prepare the version
-	repo, err := l.repoPrepare.Prepare(uri, "")
+// LocalInit adds the template to the sombra file, with the definition of the flavor
+func (l *LocalInitInteractor) LocalInit(target, uri, flavor string) error {
+	// Read sombra file, a template applied again keeps its credentials
+	sombraFile := l.sombraDefManager.GetFile(target)
+	def, err := l.sombraDefManager.Load(sombraFile)
+	if err != nil {
+		return err
+	}
+	auth := templateAuth(def, uri)
+
+	// Download and prepare the version, the URI keeps the subdirectory of the template
+	repoURI, subdir := entities.SplitSubdir(uri)
+	repo, err := l.repoPrepare.Prepare(repoURI, "", auth)
 	if err != nil {
 		return err
 	}
 	defer repo.Clean()
 
 	// Read the template configuration
-	fn := l.templateDefManager.GetFile(repo.Dir())
+	dir := filepath.Join(repo.Dir(), subdir)
+	if flavor != "" {
+		err = l.checkFlavor(dir, uri, flavor)
+		if err != nil {
+			return err
+		}
+	}
+	fn := l.templateDefManager.GetFile(dir, flavor)
 	tpl, err := l.templateDefManager.Load(fn)
 	if err != nil {
 		return err
 	}
 
 	// Read missing variables
-	vars := tpl.Vars
-	mappings := l.varsSource.GetValues(vars)
-
-	// Read sombra file
-	fn = l.sombraDefManager.GetFile(target)
-	def, err := l.sombraDefManager.Load(fn)
+	mappings, err := l.readVars(tpl.Vars)
 	if err != nil {
 		return err
 	}
 
 	// Update sombra file
 	def.Templates = append(def.Templates, &entities.TemplateConfig{
-		URI:  uri,
-		Vars: *mappings,
+		URI:    uri,
+		Vars:   mappings,
+		Auth:   auth,
+		Flavor: flavor,
 	})
 
 	// Store sombra file
-	err = l.sombraDefManager.Save(fn, def)
+	err = l.sombraDefManager.Save(sombraFile, def)
 	if err != nil {
 		return err
 	}
@@ -74,4 +93,55 @@ func (l *LocalInitInteractor) LocalInit(target, uri string) error {
 	return nil
 }
 
+// readVars reads the variables in order, so the defaults can use the values read before.
+// The variables without value nor default are reported together.
+func (l *LocalInitInteractor) readVars(vars []*entities.Var) (entities.Mappings, error) {
+	mappings := entities.Mappings{}
+	var missing []string
+	for _, v := range vars {
+		def, err := l.templateDefManager.RenderValue(v.Default, mappings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the default of %s: %w", v.Name, err)
+		}
+
+		value, ok, err := l.varsSource.GetValue(v, def, func(value string) error {
+			return validateVar(v, value)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if def == "" {
+				missing = append(missing, v.Name)
+				continue
+			}
+			value = def
+		}
+
+		err = validateVar(v, value)
+		if err != nil {
+			return nil, err
+		}
+		mappings[v.Name] = value
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no value for the variables: %s", strings.Join(missing, ", "))
+	}
+	return mappings, nil
+}
+
+// checkFlavor tells the available flavors when the requested one does not exist
+func (l *LocalInitInteractor) checkFlavor(dir, uri, flavor string) error {
+	flavors, err := l.templateDefManager.ListFlavors(dir)
+	if err != nil {
+		return err
+	}
+	for _, name := range flavors {
+		if name == flavor {
+			return nil
+		}
+	}
+	return fmt.Errorf("flavor %s not found in %s, the available flavors are: %s", flavor, uri, strings.Join(flavors, ", "))
+}
+
 var _ LocalInitCase = (*LocalInitInteractor)(nil)
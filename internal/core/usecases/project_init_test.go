@@ -30,16 +30,18 @@ func (m *MockVariableReaderPort) EXPECT() *MockVariableReaderPortMockRecorder {
 	return m.recorder
 }
 
-func (m *MockVariableReaderPort) GetValues(vars []string) *entities.Mappings {
+func (m *MockVariableReaderPort) GetValue(v *entities.Var, def string, validate func(string) error) (string, bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetValues", vars)
-	ret0, _ := ret[0].(*entities.Mappings)
-	return ret0
+	ret := m.ctrl.Call(m, "GetValue", v, def, validate)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
-func (mr *MockVariableReaderPortMockRecorder) GetValues(vars interface{}) *gomock.Call {
+func (mr *MockVariableReaderPortMockRecorder) GetValue(v, def, validate interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValues", reflect.TypeOf((*MockVariableReaderPort)(nil).GetValues), vars)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValue", reflect.TypeOf((*MockVariableReaderPort)(nil).GetValue), v, def, validate)
 }
 
 func TestLocalInitInteractor_LocalInit(t *testing.T) {
@@ -47,6 +49,7 @@ func TestLocalInitInteractor_LocalInit(t *testing.T) {
 		name   string
 		target string
 		uri    string
+		flavor string
 		setUp  func(ctrl *gomock.Controller) (
 			*MockRepositoryPrepareCase,
 			*MockTemplateDefManagerPort,
@@ -74,29 +77,29 @@ func TestLocalInitInteractor_LocalInit(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				mockTemplateDefManager.EXPECT().
 					Load(templateFile).
 					Return(&entities.TemplateDef{
-						Vars: []string{"projectName", "projectVersion"},
+						Vars: []*entities.Var{{Name: "projectName"}, {Name: "projectVersion"}},
 					}, nil)
 
-				// Setup VariableReader mock
-				expectedMappings := &entities.Mappings{
-					"projectName":    "test-project",
-					"projectVersion": "1.0.0",
-				}
+				// Setup VariableReader mock, the variables have no default
+				mockTemplateDefManager.EXPECT().RenderValue("", gomock.Any()).Return("", nil).Times(2)
+				mockVarReader.EXPECT().
+					GetValue(&entities.Var{Name: "projectName"}, "", gomock.Any()).
+					Return("test-project", true, nil)
 				mockVarReader.EXPECT().
-					GetValues([]string{"projectName", "projectVersion"}).
-					Return(expectedMappings)
+					GetValue(&entities.Var{Name: "projectVersion"}, "", gomock.Any()).
+					Return("1.0.0", true, nil)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/target/sombra.yaml")
@@ -149,27 +152,166 @@ func TestLocalInitInteractor_LocalInit(t *testing.T) {
 			shouldError: false,
 		},
 		{
-			name:   "repository preparation failure",
+			name:   "template applied again keeps its credentials",
 			target: "/path/to/target",
-			uri:    "invalid-uri",
+			uri:    "github.com/user/repo",
 			setUp: func(ctrl *gomock.Controller) (*MockRepositoryPrepareCase, *MockTemplateDefManagerPort, *MockSombraDefManagerPort, *MockVariableReaderPort, *MockRepositoryPort) {
+				mockRepo := NewMockRepositoryPort(ctrl)
 				mockRepoPrepare := NewMockRepositoryPrepareCase(ctrl)
 				mockTemplateDefManager := NewMockTemplateDefManagerPort(ctrl)
 				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
 				mockVarReader := NewMockVariableReaderPort(ctrl)
 
-				// Setup RepositoryPrepareCase mock to fail
+				auth := &entities.TemplateAuth{TokenEnv: "GITHUB_TOKEN"}
+				sombraFile := entities.File("/path/to/target/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/target").
+					Return(sombraFile)
+
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(&entities.SombraDef{
+						Templates: []*entities.TemplateConfig{
+							{URI: "github.com/user/repo", Vars: entities.Mappings{"name": "api"}, Auth: auth},
+						},
+					}, nil)
+
+				// The credentials of the template are used to clone it
+				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Clean().Return(nil)
 				mockRepoPrepare.EXPECT().
-					Prepare("invalid-uri", "").
-					Return(nil, errors.New("repository preparation failed"))
+					Prepare("github.com/user/repo", "", auth).
+					Return(mockRepo, nil)
 
-				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVarReader, nil
+				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
+				mockTemplateDefManager.EXPECT().
+					GetFile("/tmp/repo", "").
+					Return(templateFile)
+
+				mockTemplateDefManager.EXPECT().
+					Load(templateFile).
+					Return(&entities.TemplateDef{
+						Vars: []*entities.Var{{Name: "name"}},
+					}, nil)
+
+				// Setup VariableReader mock, the variables have no default
+				mockTemplateDefManager.EXPECT().RenderValue("", gomock.Any()).Return("", nil).Times(1)
+				mockVarReader.EXPECT().
+					GetValue(&entities.Var{Name: "name"}, "", gomock.Any()).
+					Return("web", true, nil)
+
+				mockSombraDefManager.EXPECT().
+					Save(sombraFile, gomock.Any()).
+					DoAndReturn(func(fn entities.File, def *entities.SombraDef) error {
+						if len(def.Templates) != 2 || def.Templates[1].Auth != auth {
+							t.Errorf("Expected the new template to keep the credentials, got %v", def.Templates)
+						}
+						return nil
+					})
+
+				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVarReader, mockRepo
+			},
+			shouldError: false,
+		},
+		{
+			name:   "initialization with a flavor",
+			target: "/path/to/target",
+			uri:    "github.com/user/repo",
+			flavor: "full",
+			setUp: func(ctrl *gomock.Controller) (*MockRepositoryPrepareCase, *MockTemplateDefManagerPort, *MockSombraDefManagerPort, *MockVariableReaderPort, *MockRepositoryPort) {
+				mockRepo := NewMockRepositoryPort(ctrl)
+				mockRepoPrepare := NewMockRepositoryPrepareCase(ctrl)
+				mockTemplateDefManager := NewMockTemplateDefManagerPort(ctrl)
+				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
+				mockVarReader := NewMockVariableReaderPort(ctrl)
+
+				sombraFile := entities.File("/path/to/target/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/target").
+					Return(sombraFile)
+
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(&entities.SombraDef{}, nil)
+
+				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Clean().Return(nil)
+				mockRepoPrepare.EXPECT().
+					Prepare("github.com/user/repo", "", nil).
+					Return(mockRepo, nil)
+
+				// The definition of the flavor is read
+				templateFile := entities.File("/tmp/repo/.sombra/full.yaml")
+				mockTemplateDefManager.EXPECT().
+					ListFlavors("/tmp/repo").
+					Return([]string{"default", "full", "minimal"}, nil)
+				mockTemplateDefManager.EXPECT().
+					GetFile("/tmp/repo", "full").
+					Return(templateFile)
+
+				mockTemplateDefManager.EXPECT().
+					Load(templateFile).
+					Return(&entities.TemplateDef{
+						Vars: []*entities.Var{{Name: "name"}},
+					}, nil)
+
+				// Setup VariableReader mock, the variables have no default
+				mockTemplateDefManager.EXPECT().RenderValue("", gomock.Any()).Return("", nil).Times(1)
+				mockVarReader.EXPECT().
+					GetValue(&entities.Var{Name: "name"}, "", gomock.Any()).
+					Return("web", true, nil)
+
+				mockSombraDefManager.EXPECT().
+					Save(sombraFile, gomock.Any()).
+					DoAndReturn(func(fn entities.File, def *entities.SombraDef) error {
+						if len(def.Templates) != 1 || def.Templates[0].Flavor != "full" {
+							t.Errorf("Expected the flavor to be recorded, got %v", def.Templates)
+						}
+						return nil
+					})
+
+				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVarReader, mockRepo
+			},
+			shouldError: false,
+		},
+		{
+			name:   "unknown flavor",
+			target: "/path/to/target",
+			uri:    "github.com/user/repo",
+			flavor: "huge",
+			setUp: func(ctrl *gomock.Controller) (*MockRepositoryPrepareCase, *MockTemplateDefManagerPort, *MockSombraDefManagerPort, *MockVariableReaderPort, *MockRepositoryPort) {
+				mockRepo := NewMockRepositoryPort(ctrl)
+				mockRepoPrepare := NewMockRepositoryPrepareCase(ctrl)
+				mockTemplateDefManager := NewMockTemplateDefManagerPort(ctrl)
+				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
+				mockVarReader := NewMockVariableReaderPort(ctrl)
+
+				sombraFile := entities.File("/path/to/target/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/target").
+					Return(sombraFile)
+
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(&entities.SombraDef{}, nil)
+
+				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Clean().Return(nil)
+				mockRepoPrepare.EXPECT().
+					Prepare("github.com/user/repo", "", nil).
+					Return(mockRepo, nil)
+
+				mockTemplateDefManager.EXPECT().
+					ListFlavors("/tmp/repo").
+					Return([]string{"default", "full"}, nil)
+
+				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVarReader, mockRepo
 			},
 			shouldError: true,
-			errorMsg:    "repository preparation failed",
+			errorMsg:    "flavor huge not found in github.com/user/repo, the available flavors are: default, full",
 		},
 		{
-			name:   "template definition load failure",
+			name:   "variables with a schema",
 			target: "/path/to/target",
 			uri:    "github.com/user/repo",
 			setUp: func(ctrl *gomock.Controller) (*MockRepositoryPrepareCase, *MockTemplateDefManagerPort, *MockSombraDefManagerPort, *MockVariableReaderPort, *MockRepositoryPort) {
@@ -179,32 +321,152 @@ func TestLocalInitInteractor_LocalInit(t *testing.T) {
 				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
 				mockVarReader := NewMockVariableReaderPort(ctrl)
 
-				// Setup RepositoryPort mock
+				sombraFile := entities.File("/path/to/target/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/target").
+					Return(sombraFile)
+
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(&entities.SombraDef{}, nil)
+
 				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
 				mockRepo.EXPECT().Clean().Return(nil)
+				mockRepoPrepare.EXPECT().
+					Prepare("github.com/user/repo", "", nil).
+					Return(mockRepo, nil)
 
-				// Setup RepositoryPrepareCase mock
+				name := &entities.Var{Name: "name", Regex: "^[a-z-]+$"}
+				module := &entities.Var{Name: "module", Default: "github.com/acme/{{ .name }}"}
+				port := &entities.Var{Name: "port", Type: entities.VarInt}
+				templateFile := entities.File("/tmp/repo/.sombra/default.yaml")
+				mockTemplateDefManager.EXPECT().
+					GetFile("/tmp/repo", "").
+					Return(templateFile)
+
+				mockTemplateDefManager.EXPECT().
+					Load(templateFile).
+					Return(&entities.TemplateDef{
+						Vars: []*entities.Var{name, module, port},
+					}, nil)
+
+				// The source checks the values with the schema
+				mockTemplateDefManager.EXPECT().RenderValue("", gomock.Any()).Return("", nil).Times(2)
+				mockVarReader.EXPECT().
+					GetValue(name, "", gomock.Any()).
+					DoAndReturn(func(v *entities.Var, def string, validate func(string) error) (string, bool, error) {
+						if validate("My API") == nil {
+							t.Errorf("Expected the value to be checked with the regex")
+						}
+						return "api", true, nil
+					})
+
+				// The default uses the values read before, it is taken when the source has no value
+				mockTemplateDefManager.EXPECT().
+					RenderValue("github.com/acme/{{ .name }}", entities.Mappings{"name": "api"}).
+					Return("github.com/acme/api", nil)
+				mockVarReader.EXPECT().
+					GetValue(module, "github.com/acme/api", gomock.Any()).
+					Return("", false, nil)
+
+				// Invalid values of a source asking once are refused
+				mockVarReader.EXPECT().
+					GetValue(port, "", gomock.Any()).
+					Return("http", true, nil)
+
+				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVarReader, mockRepo
+			},
+			shouldError: true,
+			errorMsg:    `port must be an integer, got "http"`,
+		},
+		{
+			name:   "missing variables are listed",
+			target: "/path/to/target",
+			uri:    "github.com/user/repo",
+			setUp: func(ctrl *gomock.Controller) (*MockRepositoryPrepareCase, *MockTemplateDefManagerPort, *MockSombraDefManagerPort, *MockVariableReaderPort, *MockRepositoryPort) {
+				mockRepo := NewMockRepositoryPort(ctrl)
+				mockRepoPrepare := NewMockRepositoryPrepareCase(ctrl)
+				mockTemplateDefManager := NewMockTemplateDefManagerPort(ctrl)
+				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
+				mockVarReader := NewMockVariableReaderPort(ctrl)
+
+				sombraFile := entities.File("/path/to/target/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/target").
+					Return(sombraFile)
+
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(&entities.SombraDef{}, nil)
+
+				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Clean().Return(nil)
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
-				// Setup TemplateDefManager mock to fail on load
-				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
+				templateFile := entities.File("/tmp/repo/.sombra/default.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				mockTemplateDefManager.EXPECT().
 					Load(templateFile).
-					Return(nil, errors.New("template definition load failed"))
+					Return(&entities.TemplateDef{
+						Vars: []*entities.Var{{Name: "name"}, {Name: "owner"}, {Name: "port"}},
+					}, nil)
+
+				// Every variable is read before failing
+				mockTemplateDefManager.EXPECT().RenderValue("", gomock.Any()).Return("", nil).Times(3)
+				mockVarReader.EXPECT().
+					GetValue(&entities.Var{Name: "name"}, "", gomock.Any()).
+					Return("", false, nil)
+				mockVarReader.EXPECT().
+					GetValue(&entities.Var{Name: "owner"}, "", gomock.Any()).
+					Return("acme", true, nil)
+				mockVarReader.EXPECT().
+					GetValue(&entities.Var{Name: "port"}, "", gomock.Any()).
+					Return("", false, nil)
 
 				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVarReader, mockRepo
 			},
 			shouldError: true,
-			errorMsg:    "template definition load failed",
+			errorMsg:    "no value for the variables: name, port",
 		},
 		{
-			name:   "sombra definition load failure",
+			name:   "repository preparation failure",
+			target: "/path/to/target",
+			uri:    "invalid-uri",
+			setUp: func(ctrl *gomock.Controller) (*MockRepositoryPrepareCase, *MockTemplateDefManagerPort, *MockSombraDefManagerPort, *MockVariableReaderPort, *MockRepositoryPort) {
+				mockRepoPrepare := NewMockRepositoryPrepareCase(ctrl)
+				mockTemplateDefManager := NewMockTemplateDefManagerPort(ctrl)
+				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
+				mockVarReader := NewMockVariableReaderPort(ctrl)
+
+				// Setup SombraDefManager mock
+				sombraFile := entities.File("/path/to/target/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/target").
+					Return(sombraFile)
+
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(&entities.SombraDef{
+						Templates: []*entities.TemplateConfig{},
+					}, nil)
+
+				// Setup RepositoryPrepareCase mock to fail
+				mockRepoPrepare.EXPECT().
+					Prepare("invalid-uri", "", nil).
+					Return(nil, errors.New("repository preparation failed"))
+
+				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVarReader, nil
+			},
+			shouldError: true,
+			errorMsg:    "repository preparation failed",
+		},
+		{
+			name:   "template definition load failure",
 			target: "/path/to/target",
 			uri:    "github.com/user/repo",
 			setUp: func(ctrl *gomock.Controller) (*MockRepositoryPrepareCase, *MockTemplateDefManagerPort, *MockSombraDefManagerPort, *MockVariableReaderPort, *MockRepositoryPort) {
@@ -220,28 +482,45 @@ func TestLocalInitInteractor_LocalInit(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
-				// Setup TemplateDefManager mock
+				// Setup SombraDefManager mock
+				sombraFile := entities.File("/path/to/target/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/target").
+					Return(sombraFile)
+
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(&entities.SombraDef{
+						Templates: []*entities.TemplateConfig{},
+					}, nil)
+
+				// Setup TemplateDefManager mock to fail on load
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				mockTemplateDefManager.EXPECT().
 					Load(templateFile).
-					Return(&entities.TemplateDef{
-						Vars: []string{"projectName"},
-					}, nil)
+					Return(nil, errors.New("template definition load failed"))
 
-				// Setup VariableReader mock
-				expectedMappings := &entities.Mappings{
-					"projectName": "test-project",
-				}
-				mockVarReader.EXPECT().
-					GetValues([]string{"projectName"}).
-					Return(expectedMappings)
+				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVarReader, mockRepo
+			},
+			shouldError: true,
+			errorMsg:    "template definition load failed",
+		},
+		{
+			name:   "sombra definition load failure",
+			target: "/path/to/target",
+			uri:    "github.com/user/repo",
+			setUp: func(ctrl *gomock.Controller) (*MockRepositoryPrepareCase, *MockTemplateDefManagerPort, *MockSombraDefManagerPort, *MockVariableReaderPort, *MockRepositoryPort) {
+				mockRepoPrepare := NewMockRepositoryPrepareCase(ctrl)
+				mockTemplateDefManager := NewMockTemplateDefManagerPort(ctrl)
+				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
+				mockVarReader := NewMockVariableReaderPort(ctrl)
 
 				// Setup SombraDefManager mock to fail on load
 				sombraFile := entities.File("/path/to/target/sombra.yaml")
@@ -253,7 +532,7 @@ func TestLocalInitInteractor_LocalInit(t *testing.T) {
 					Load(sombraFile).
 					Return(nil, errors.New("sombra definition load failed"))
 
-				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVarReader, mockRepo
+				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVarReader, nil
 			},
 			shouldError: true,
 			errorMsg:    "sombra definition load failed",
@@ -275,28 +554,26 @@ func TestLocalInitInteractor_LocalInit(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				mockTemplateDefManager.EXPECT().
 					Load(templateFile).
 					Return(&entities.TemplateDef{
-						Vars: []string{"projectName"},
+						Vars: []*entities.Var{{Name: "projectName"}},
 					}, nil)
 
-				// Setup VariableReader mock
-				expectedMappings := &entities.Mappings{
-					"projectName": "test-project",
-				}
+				// Setup VariableReader mock, the variables have no default
+				mockTemplateDefManager.EXPECT().RenderValue("", gomock.Any()).Return("", nil).Times(1)
 				mockVarReader.EXPECT().
-					GetValues([]string{"projectName"}).
-					Return(expectedMappings)
+					GetValue(&entities.Var{Name: "projectName"}, "", gomock.Any()).
+					Return("test-project", true, nil)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/target/sombra.yaml")
@@ -339,7 +616,7 @@ func TestLocalInitInteractor_LocalInit(t *testing.T) {
 			)
 
 			// Execute
-			err := interactor.LocalInit(tt.target, tt.uri)
+			err := interactor.LocalInit(tt.target, tt.uri, tt.flavor)
 
 			// Check error
 			if (err != nil) != tt.shouldError {
@@ -0,0 +1,160 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"path/filepath"
+	"strings"
+)
+
+type LocalUpdateAllCase interface {
+	LocalUpdateAll(target string, useCase LocalUpdateCase, opts LocalUpdateOptions) ([]*entities.TemplateUpdate, error)
+}
+
+type LocalUpdateAllInteractor struct {
+	sombraDefManager SombraDefManagerPort
+	snapshots        SnapshotPort
+}
+
+func NewLocalUpdateAllInteractor(sombraDefManager SombraDefManagerPort, snapshots SnapshotPort) *LocalUpdateAllInteractor {
+	return &LocalUpdateAllInteractor{
+		sombraDefManager: sombraDefManager,
+		snapshots:        snapshots,
+	}
+}
+
+// LocalUpdateAll updates every template of the sombra file in order, each one to its own latest version.
+// A failed template is rolled back and the next ones are skipped, so the tree is never left half updated.
+func (all *LocalUpdateAllInteractor) LocalUpdateAll(target string, useCase LocalUpdateCase, opts LocalUpdateOptions) ([]*entities.TemplateUpdate, error) {
+	if opts.Tag != "" {
+		return nil, fmt.Errorf("a tag can only be used to update a single template")
+	}
+
+	sombraFile := all.sombraDefManager.GetFile(target)
+	def, err := all.sombraDefManager.Load(sombraFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// templates applied in several paths are updated at once
+	uris := make([]string, 0, len(def.Templates))
+	seen := make(map[string]bool)
+	for _, template := range def.Templates {
+		if seen[template.URI] {
+			continue
+		}
+		seen[template.URI] = true
+		uris = append(uris, template.URI)
+	}
+
+	// a single snapshot is kept for the whole run, it follows the templates already updated
+	run := &updateRun{target: target, sombraFile: sombraFile}
+	defer all.discard(run)
+
+	var failure error
+	updates := make([]*entities.TemplateUpdate, 0, len(uris))
+	for _, uri := range uris {
+		update := &entities.TemplateUpdate{URI: uri}
+		updates = append(updates, update)
+		if failure != nil {
+			update.Status = entities.UpdateSkipped
+			continue
+		}
+
+		update.Plans, err = all.update(run, uri, useCase, opts)
+		if err != nil {
+			update.Status = entities.UpdateFailed
+			update.Error = err.Error()
+			failure = fmt.Errorf("failed to update %s: %w", uri, err)
+			continue
+		}
+
+		switch {
+		case len(update.Plans) == 0:
+			update.Status = entities.UpdateCurrent
+		case opts.DryRun:
+			update.Status = entities.UpdatePlanned
+		default:
+			update.Status = entities.UpdateApplied
+		}
+	}
+
+	return updates, failure
+}
+
+// updateRun holds the snapshot of the run, taken before the first template is updated
+type updateRun struct {
+	target     string
+	sombraFile entities.File
+	snapshot   string
+}
+
+// update runs the update of a single template, restoring the target directory if it fails
+func (all *LocalUpdateAllInteractor) update(run *updateRun, uri string, useCase LocalUpdateCase, opts LocalUpdateOptions) ([]*entities.UpdatePlan, error) {
+	// a dry run does not write anything, there is nothing to roll back
+	if opts.DryRun {
+		return useCase.LocalUpdate(run.target, uri, opts)
+	}
+
+	if run.snapshot == "" {
+		snapshot, err := all.snapshots.Snapshot(run.target)
+		if err != nil {
+			return nil, err
+		}
+		run.snapshot = snapshot
+	}
+
+	plans, err := useCase.LocalUpdate(run.target, uri, opts)
+	if err != nil {
+		restoreErr := all.snapshots.Restore(run.target, run.snapshot)
+		if restoreErr != nil {
+			return nil, fmt.Errorf("%w, and the rollback failed: %v", err, restoreErr)
+		}
+		return nil, fmt.Errorf("%w, the changes were rolled back", err)
+	}
+
+	// only the files of the plans are copied, a later failure rolls back its own template
+	err = all.snapshots.Update(run.target, run.snapshot, touchedFiles(run.target, run.sombraFile, plans))
+	if err != nil {
+		return plans, fmt.Errorf("the changes were kept, but the next templates cannot be rolled back: %w", err)
+	}
+	return plans, nil
+}
+
+func (all *LocalUpdateAllInteractor) discard(run *updateRun) {
+	if run.snapshot != "" {
+		_ = all.snapshots.Discard(run.snapshot)
+	}
+}
+
+// touchedFiles lists the files written by the plans, relative to the target directory, with the sombra file
+func touchedFiles(target string, sombraFile entities.File, plans []*entities.UpdatePlan) []string {
+	res := make([]string, 0)
+	seen := make(map[string]bool)
+	if rel, err := filepath.Rel(target, string(sombraFile)); err == nil {
+		res = append(res, rel)
+		seen[rel] = true
+	}
+	for _, plan := range plans {
+		add := func(fn entities.File) {
+			rel := strings.TrimPrefix(filepath.ToSlash(filepath.Join(plan.Path, string(fn))), "/")
+			if fn != "" && !seen[rel] {
+				res = append(res, rel)
+				seen[rel] = true
+			}
+		}
+		for _, change := range plan.Changes {
+			add(change.File)
+			add(change.From)
+		}
+		if plan.Patch != nil {
+			for _, file := range plan.Patch.Files {
+				add(file.File)
+				add(file.RejectFile)
+			}
+		}
+	}
+	return res
+}
+
+var _ LocalUpdateAllCase = (*LocalUpdateAllInteractor)(nil)
@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"go.uber.org/mock/gomock"
+)
+
+type localUpdateAllMocks struct {
+	sombraDefManager *MockSombraDefManagerPort
+	snapshots        *MockSnapshotPort
+	useCase          *MockLocalUpdateCase
+}
+
+func TestLocalUpdateAllInteractor_LocalUpdateAll(t *testing.T) {
+	target := "/path/to/project"
+	sombraFile := entities.File("/path/to/project/sombra.yaml")
+	sombraDef := &entities.SombraDef{
+		Templates: []*entities.TemplateConfig{
+			{URI: "github.com/user/base", Current: "v1.0.0"},
+			{URI: "github.com/user/ci", Current: "v2.0.0"},
+			{URI: "github.com/user/base", Path: "docs", Current: "v1.0.0"},
+			{URI: "github.com/user/lint", Current: "v0.1.0"},
+		},
+	}
+	plan := func(uri string, from, to entities.Version) *entities.UpdatePlan {
+		return &entities.UpdatePlan{URI: uri, From: from, To: to}
+	}
+	load := func(m *localUpdateAllMocks) {
+		m.sombraDefManager.EXPECT().GetFile(target).Return(sombraFile)
+		m.sombraDefManager.EXPECT().Load(sombraFile).Return(sombraDef, nil)
+	}
+
+	tests := []struct {
+		name        string
+		opts        LocalUpdateOptions
+		setup       func(m *localUpdateAllMocks)
+		shouldError bool
+		errorMsg    string
+		expected    []entities.UpdateStatus
+	}{
+		{
+			name: "every template is updated once in order",
+			setup: func(m *localUpdateAllMocks) {
+				load(m)
+				m.snapshots.EXPECT().Snapshot(target).Return("/tmp/snapshot", nil)
+				m.snapshots.EXPECT().Discard("/tmp/snapshot").Return(nil)
+				m.snapshots.EXPECT().Update(target, "/tmp/snapshot", []string{"sombra.yaml"}).Return(nil).Times(3)
+				gomock.InOrder(
+					m.useCase.EXPECT().LocalUpdate(target, "github.com/user/base", gomock.Any()).
+						Return([]*entities.UpdatePlan{plan("github.com/user/base", "v1.0.0", "v1.1.0"), plan("github.com/user/base", "v1.0.0", "v1.1.0")}, nil),
+					m.useCase.EXPECT().LocalUpdate(target, "github.com/user/ci", gomock.Any()).
+						Return([]*entities.UpdatePlan{}, nil),
+					m.useCase.EXPECT().LocalUpdate(target, "github.com/user/lint", gomock.Any()).
+						Return([]*entities.UpdatePlan{plan("github.com/user/lint", "v0.1.0", "v0.2.0")}, nil),
+				)
+			},
+			expected: []entities.UpdateStatus{entities.UpdateApplied, entities.UpdateCurrent, entities.UpdateApplied},
+		},
+		{
+			name: "failed template is rolled back and the next ones are skipped",
+			setup: func(m *localUpdateAllMocks) {
+				load(m)
+				m.snapshots.EXPECT().Snapshot(target).Return("/tmp/snapshot", nil)
+				m.snapshots.EXPECT().Discard("/tmp/snapshot").Return(nil)
+				base := plan("github.com/user/base", "v1.0.0", "v1.1.0")
+				base.Path = "docs"
+				base.Changes = []*entities.FileChange{
+					{Operation: entities.FileModify, File: "/README.md"},
+					{Operation: entities.FileRename, File: "/new.md", From: "/old.md"},
+				}
+				base.Patch = &entities.PatchResult{Files: []*entities.PatchFileResult{{File: "/README.md", RejectFile: "/README.md.rej"}}}
+				m.useCase.EXPECT().LocalUpdate(target, "github.com/user/base", gomock.Any()).
+					Return([]*entities.UpdatePlan{base}, nil)
+				// the snapshot follows the first template, so the failure only rolls back the second one
+				m.snapshots.EXPECT().Update(target, "/tmp/snapshot", []string{"sombra.yaml", "docs/README.md", "docs/new.md", "docs/old.md", "docs/README.md.rej"}).Return(nil)
+				m.useCase.EXPECT().LocalUpdate(target, "github.com/user/ci", gomock.Any()).
+					Return([]*entities.UpdatePlan{plan("github.com/user/ci", "v2.0.0", "v2.1.0")}, errors.New("patch failed"))
+				m.snapshots.EXPECT().Restore(target, "/tmp/snapshot").Return(nil)
+			},
+			shouldError: true,
+			errorMsg:    "failed to update github.com/user/ci: patch failed, the changes were rolled back",
+			expected:    []entities.UpdateStatus{entities.UpdateApplied, entities.UpdateFailed, entities.UpdateSkipped},
+		},
+		{
+			name: "rollback failure is reported",
+			setup: func(m *localUpdateAllMocks) {
+				load(m)
+				m.snapshots.EXPECT().Snapshot(target).Return("/tmp/snapshot", nil)
+				m.snapshots.EXPECT().Discard("/tmp/snapshot").Return(nil)
+				m.useCase.EXPECT().LocalUpdate(target, "github.com/user/base", gomock.Any()).
+					Return(nil, errors.New("patch failed"))
+				m.snapshots.EXPECT().Restore(target, "/tmp/snapshot").Return(errors.New("disk full"))
+			},
+			shouldError: true,
+			errorMsg:    "failed to update github.com/user/base: patch failed, and the rollback failed: disk full",
+			expected:    []entities.UpdateStatus{entities.UpdateFailed, entities.UpdateSkipped, entities.UpdateSkipped},
+		},
+		{
+			name: "snapshot update failure stops the run",
+			setup: func(m *localUpdateAllMocks) {
+				load(m)
+				m.snapshots.EXPECT().Snapshot(target).Return("/tmp/snapshot", nil)
+				m.snapshots.EXPECT().Discard("/tmp/snapshot").Return(nil)
+				m.useCase.EXPECT().LocalUpdate(target, "github.com/user/base", gomock.Any()).
+					Return([]*entities.UpdatePlan{plan("github.com/user/base", "v1.0.0", "v1.1.0")}, nil)
+				m.snapshots.EXPECT().Update(target, "/tmp/snapshot", gomock.Any()).Return(errors.New("disk full"))
+			},
+			shouldError: true,
+			errorMsg:    "failed to update github.com/user/base: the changes were kept, but the next templates cannot be rolled back: disk full",
+			expected:    []entities.UpdateStatus{entities.UpdateFailed, entities.UpdateSkipped, entities.UpdateSkipped},
+		},
+		{
+			name: "snapshot failure stops before updating",
+			setup: func(m *localUpdateAllMocks) {
+				load(m)
+				m.snapshots.EXPECT().Snapshot(target).Return("", errors.New("no space left"))
+			},
+			shouldError: true,
+			errorMsg:    "failed to update github.com/user/base: no space left",
+			expected:    []entities.UpdateStatus{entities.UpdateFailed, entities.UpdateSkipped, entities.UpdateSkipped},
+		},
+		{
+			name: "dry run does not take snapshots",
+			opts: LocalUpdateOptions{DryRun: true},
+			setup: func(m *localUpdateAllMocks) {
+				load(m)
+				m.useCase.EXPECT().LocalUpdate(target, "github.com/user/base", LocalUpdateOptions{DryRun: true}).
+					Return([]*entities.UpdatePlan{plan("github.com/user/base", "v1.0.0", "v1.1.0")}, nil)
+				m.useCase.EXPECT().LocalUpdate(target, "github.com/user/ci", LocalUpdateOptions{DryRun: true}).
+					Return([]*entities.UpdatePlan{}, nil)
+				m.useCase.EXPECT().LocalUpdate(target, "github.com/user/lint", LocalUpdateOptions{DryRun: true}).
+					Return([]*entities.UpdatePlan{plan("github.com/user/lint", "v0.1.0", "v0.2.0")}, nil)
+			},
+			expected: []entities.UpdateStatus{entities.UpdatePlanned, entities.UpdateCurrent, entities.UpdatePlanned},
+		},
+		{
+			name:        "tag is not allowed",
+			opts:        LocalUpdateOptions{Tag: "v1.0.0"},
+			setup:       func(m *localUpdateAllMocks) {},
+			shouldError: true,
+			errorMsg:    "a tag can only be used to update a single template",
+		},
+		{
+			name: "sombra definition load failure",
+			setup: func(m *localUpdateAllMocks) {
+				m.sombraDefManager.EXPECT().GetFile(target).Return(sombraFile)
+				m.sombraDefManager.EXPECT().Load(sombraFile).Return(nil, errors.New("failed to load sombra definition"))
+			},
+			shouldError: true,
+			errorMsg:    "failed to load sombra definition",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := &localUpdateAllMocks{
+				sombraDefManager: NewMockSombraDefManagerPort(ctrl),
+				snapshots:        NewMockSnapshotPort(ctrl),
+				useCase:          NewMockLocalUpdateCase(ctrl),
+			}
+			tt.setup(m)
+
+			interactor := NewLocalUpdateAllInteractor(m.sombraDefManager, m.snapshots)
+			updates, err := interactor.LocalUpdateAll(target, m.useCase, tt.opts)
+
+			if (err != nil) != tt.shouldError {
+				t.Errorf("LocalUpdateAll() error = %v, shouldError = %v", err, tt.shouldError)
+			}
+			if tt.shouldError && err != nil && tt.errorMsg != "" && err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message %q, got %q", tt.errorMsg, err.Error())
+			}
+
+			if len(updates) != len(tt.expected) {
+				t.Fatalf("Expected %d updates, got %d", len(tt.expected), len(updates))
+			}
+			for i, update := range updates {
+				if update.Status != tt.expected[i] {
+					t.Errorf("Expected %s to be %s, got %s", update.URI, tt.expected[i], update.Status)
+				}
+			}
+		})
+	}
+}
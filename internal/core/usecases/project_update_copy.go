@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"bytes"
 	"github.com/sombrahq/sombra-cli/internal/core/entities"
 	"path/filepath"
 )
@@ -35,124 +36,206 @@ func NewLocalCopyInteractor(
 	}
 }
 
-func (copy *LocalCopyInteractor) LocalUpdate(target, uri, tag string) error {
+func (copy *LocalCopyInteractor) LocalUpdate(target, uri string, opts LocalUpdateOptions) ([]*entities.UpdatePlan, error) {
 	// Read sombra file
 	sombraFile := copy.sombraDefManager.GetFile(target)
 	def, err := copy.sombraDefManager.Load(sombraFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Download and prepare the version
-	repo, err := copy.repoPrepare.Prepare(uri, "")
+	// Download and prepare the version, a template in a subdirectory clones the whole repository
+	repoURI, _ := entities.SplitSubdir(uri)
+	repo, err := copy.repoPrepare.Prepare(repoURI, "", templateAuth(def, uri))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer repo.Clean()
 
-	// If the tag variable is empty, find the latest tag
+	// If the tag variable is empty, each template looks for its version in the tags
 	var version entities.Version
 	var tags []string
-	if tag == "" {
+	if opts.Tag == "" {
 		tags, err = repo.GetTags()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		version, err = copy.versionManager.GetLatest(tags, "*")
-		if err != nil {
-			return err
-		}
-	} else {
-		version = entities.Version(tag)
 	}
 
 	// Iterate over all templates
 	var tpl *entities.TemplateDef
 	var fn entities.File
+	plans := make([]*entities.UpdatePlan, 0)
 	for _, template := range def.Templates {
 		if template.URI != uri {
 			continue
 		}
 
+		version, err = targetVersion(copy.versionManager, repo, tags, template, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		// Templates with different constraints may use different versions
+		_, err = repo.Use(string(version))
+		if err != nil {
+			return nil, err
+		}
+
 		// Render TemplateConfig Definition using Sombra configuration
-		fn = copy.templateDefManager.GetFile(repo.Dir())
+		dir := templateDir(repo, template)
+		fn = copy.templateDefManager.GetFile(dir, template.Flavor)
 		tpl, err = copy.templateDefManager.Render(fn, template.Vars)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		err = validateVars(tpl.Vars, template.Vars)
+		if err != nil {
+			return nil, err
 		}
 
 		// Execute the mappings
-		err = copy.copyFiles(repo.Dir(), filepath.Join(target, template.Path), tpl)
+		plan := &entities.UpdatePlan{
+			URI:    uri,
+			Path:   template.Path,
+			From:   template.Current,
+			To:     version,
+			DryRun: opts.DryRun,
+		}
+		plan.Changes, err = copy.copyFiles(dir, filepath.Join(target, template.Path), tpl, template.Vars, opts.DryRun)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		plans = append(plans, plan)
 
 		// Update the template configuration
 		template.Current = version
 	}
 
+	// A dry run only reports the plan, nothing is stored
+	if opts.DryRun {
+		return plans, nil
+	}
+
 	// Store sombra file
 	err = copy.sombraDefManager.Save(sombraFile, def)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return plans, nil
 }
 
-func (copy *LocalCopyInteractor) copyFiles(templateDir, targetDir string, templateConfig *entities.TemplateDef) error {
+func (copy *LocalCopyInteractor) copyFiles(templateDir, targetDir string, templateConfig *entities.TemplateDef, vars entities.Mappings, dryRun bool) ([]*entities.FileChange, error) {
 	tree := copy.scanner.ScanTree(templateDir, []entities.Wildcard{"**/*"}, nil)
 	var fn entities.File
 	var items *entities.MapResult
+	var change *entities.FileChange
+	changes := make([]*entities.FileChange, 0)
 	for result := range tree {
 		if result.Err != nil {
-			return result.Err
+			return nil, result.Err
 		}
 
 		fn = result.File
 
 		match, res, err := copy.engine.Match(fn, templateConfig.Patterns)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// Notice that match can be false even if res is not empty
 		// This is because the mapping engine only matches a file
 		// if there are non-abstract mappings matching the file
 		if !match {
+			if result.IsDir {
+				continue
+			}
+			change, err = copy.skipFile(targetDir, fn, res, templateConfig.Patterns)
+			if err != nil {
+				return nil, err
+			}
+			if change != nil {
+				changes = append(changes, change)
+			}
 			continue
 		}
 
 		items = copy.engine.Combine(res)
 
 		if result.IsDir {
-			err = copy.processDir(targetDir, fn, items)
+			err = copy.processDir(targetDir, fn, items, dryRun)
 		} else {
-			err = copy.processFile(templateDir, targetDir, fn, items)
+			change, err = copy.processFile(templateDir, targetDir, fn, items, vars, dryRun)
+			if change != nil {
+				changes = append(changes, change)
+			}
 		}
 
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
-	return nil
+	return changes, nil
 }
 
-func (copy *LocalCopyInteractor) processDir(target string, path entities.File, res *entities.MapResult) error {
+func (copy *LocalCopyInteractor) processDir(target string, path entities.File, res *entities.MapResult, dryRun bool) error {
 	newDir := copy.engine.NewFile(path, res.Path, res.Name)
+	if dryRun {
+		return nil
+	}
 	return copy.localFiles.EnsureDir(target, newDir)
 }
 
-func (copy *LocalCopyInteractor) processFile(src string, target string, file entities.File, res *entities.MapResult) error {
+func (copy *LocalCopyInteractor) processFile(src string, target string, file entities.File, res *entities.MapResult, vars entities.Mappings, dryRun bool) (*entities.FileChange, error) {
 	newFile := copy.engine.NewFile(file, res.Path, res.Name)
 	content, err := copy.localFiles.Read(src, file)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	newContent, err := copy.engine.RenderContent(file, content, res, vars)
+	if err != nil {
+		return nil, err
+	}
+	change, err := copy.detectChange(target, newFile, newContent)
+	if err != nil || change == nil || dryRun {
+		return change, err
 	}
 
-	newContent := copy.engine.NewContent(content, res.Content)
 	err = copy.localFiles.Write(target, newFile, newContent)
-	return err
+	return change, err
+}
+
+// skipFile reports the local files of the patterns whose condition is false, they are kept as they are
+func (copy *LocalCopyInteractor) skipFile(target string, file entities.File, matching, patterns []*entities.Pattern) (*entities.FileChange, error) {
+	skipped, err := copy.engine.Skipped(file, patterns)
+	if err != nil || !skipped {
+		return nil, err
+	}
+
+	items := copy.engine.Combine(matching)
+	newFile := copy.engine.NewFile(file, items.Path, items.Name)
+	if !copy.localFiles.Exists(target, newFile) {
+		return nil, nil
+	}
+	return &entities.FileChange{Operation: entities.FileSkip, File: newFile, Reason: "condition is false, the file is kept"}, nil
+}
+
+// detectChange compares the rendered content with the local file, it returns nil when nothing changes
+func (copy *LocalCopyInteractor) detectChange(target string, file entities.File, content []byte) (*entities.FileChange, error) {
+	if !copy.localFiles.Exists(target, file) {
+		return &entities.FileChange{Operation: entities.FileCreate, File: file}, nil
+	}
+
+	current, err := copy.localFiles.Read(target, file)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(current, content) {
+		return nil, nil
+	}
+	return &entities.FileChange{Operation: entities.FileModify, File: file}, nil
 }
 
 var _ LocalUpdateCase = (*LocalCopyInteractor)(nil)
@@ -26,6 +26,7 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 		target string
 		uri    string
 		tag    string
+		dryRun bool
 		setup  func(ctrl *gomock.Controller) (
 			*MockRepositoryPrepareCase,
 			*MockTemplateDefManagerPort,
@@ -38,6 +39,7 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 		)
 		shouldError bool
 		errorMsg    string
+		checkPlans  func(t *testing.T, plans []*entities.UpdatePlan)
 	}{
 		{
 			name:   "successful update with explicit tag",
@@ -95,21 +97,22 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock
 				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Use("v1.0.0").Return("v1.0.0", nil)
 				mockRepo.EXPECT().Clean().Return(nil)
 
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
+					Vars: []*entities.Var{{Name: "projectName"}},
 					Patterns: []*entities.Pattern{
 						{
 							Pattern: "src/**/*",
@@ -200,9 +203,12 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 						newContent := []byte("package main\n\nfunc main() {\n  // test-project\n}\n")
 						mockSombraEngine.EXPECT().
-							NewContent(fileContent, mapResult.Content).
-							Return(newContent)
+							RenderContent(gomock.Any(), fileContent, mapResult, gomock.Any()).
+							Return(newContent, nil)
 
+						mockFileManager.EXPECT().
+							Exists(filepath.Join("/path/to/project", "src"), newFile).
+							Return(false)
 						mockFileManager.EXPECT().
 							Write(filepath.Join("/path/to/project", "src"), newFile, newContent).
 							Return(nil)
@@ -280,7 +286,7 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock for GetTags
@@ -296,16 +302,17 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPort mock
 				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Use("v1.1.0").Return("v1.1.0", nil)
 				mockRepo.EXPECT().Clean().Return(nil)
 
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
+					Vars: []*entities.Var{{Name: "projectName"}},
 					Patterns: []*entities.Pattern{
 						{
 							Pattern: "src/**/*",
@@ -375,9 +382,12 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				newContent := []byte("package main\n\nfunc main() {\n  // test-project\n}\n")
 				mockSombraEngine.EXPECT().
-					NewContent(fileContent, mapResult.Content).
-					Return(newContent)
+					RenderContent(gomock.Any(), fileContent, mapResult, gomock.Any()).
+					Return(newContent, nil)
 
+				mockFileManager.EXPECT().
+					Exists(filepath.Join("/path/to/project", "src"), newFile).
+					Return(false)
 				mockFileManager.EXPECT().
 					Write(filepath.Join("/path/to/project", "src"), newFile, newContent).
 					Return(nil)
@@ -488,7 +498,7 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock to fail
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(nil, errors.New("repository preparation failed"))
 
 				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
@@ -544,7 +554,7 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock for GetTags to fail
@@ -608,7 +618,7 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock for GetTags
@@ -666,6 +676,7 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 							URI:     "github.com/user/repo",
 							Path:    "src",
 							Current: "v0.9.0",
+							Flavor:  "full",
 							Vars: entities.Mappings{
 								"projectName": "test-project",
 							},
@@ -678,17 +689,18 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock
 				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Use("v1.0.0").Return("v1.0.0", nil)
 				mockRepo.EXPECT().Clean().Return(nil)
 
-				// Setup TemplateDefManager mock to fail on render
-				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
+				// Setup TemplateDefManager mock to fail on render, the flavor of the template is kept
+				templateFile := entities.File("/tmp/repo/.sombra/full.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "full").
 					Return(templateFile)
 
 				mockTemplateDefManager.EXPECT().
@@ -748,21 +760,22 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock
 				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Use("v1.0.0").Return("v1.0.0", nil)
 				mockRepo.EXPECT().Clean().Return(nil)
 
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
+					Vars: []*entities.Var{{Name: "projectName"}},
 					Patterns: []*entities.Pattern{
 						{
 							Pattern: "src/**/*",
@@ -841,21 +854,22 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock
 				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Use("v1.0.0").Return("v1.0.0", nil)
 				mockRepo.EXPECT().Clean().Return(nil)
 
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
+					Vars: []*entities.Var{{Name: "projectName"}},
 					Patterns: []*entities.Pattern{
 						{
 							Pattern: "src/**/*",
@@ -939,21 +953,22 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock
 				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Use("v1.0.0").Return("v1.0.0", nil)
 				mockRepo.EXPECT().Clean().Return(nil)
 
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
+					Vars: []*entities.Var{{Name: "projectName"}},
 					Patterns: []*entities.Pattern{
 						{
 							Pattern: "src/**/*",
@@ -1072,21 +1087,22 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock
 				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Use("v1.0.0").Return("v1.0.0", nil)
 				mockRepo.EXPECT().Clean().Return(nil)
 
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
+					Vars: []*entities.Var{{Name: "projectName"}},
 					Patterns: []*entities.Pattern{
 						{
 							Pattern: "src/**/*",
@@ -1154,10 +1170,13 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 
 				newContent := []byte("package main\n\nfunc main() {\n  // test-project\n}\n")
 				mockSombraEngine.EXPECT().
-					NewContent(fileContent, mapResult.Content).
-					Return(newContent)
+					RenderContent(gomock.Any(), fileContent, mapResult, gomock.Any()).
+					Return(newContent, nil)
 
 				// File write error
+				mockFileManager.EXPECT().
+					Exists(filepath.Join("/path/to/project", "src"), newFile).
+					Return(false)
 				mockFileManager.EXPECT().
 					Write(filepath.Join("/path/to/project", "src"), newFile, newContent).
 					Return(errors.New("file write error"))
@@ -1167,6 +1186,156 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 			shouldError: true,
 			errorMsg:    "file write error",
 		},
+		{
+			name:   "dry run reports changes without writing",
+			target: "/path/to/project",
+			uri:    "github.com/user/repo",
+			tag:    "v1.0.0",
+			dryRun: true,
+			setup: func(ctrl *gomock.Controller) (
+				*MockRepositoryPrepareCase,
+				*MockTemplateDefManagerPort,
+				*MockSombraDefManagerPort,
+				*MockVersionManagerPort,
+				*MockDirectoryManagerPort,
+				*MockFileManagerPort,
+				*MockSombraEngineCase,
+				*MockRepositoryPort,
+			) {
+				mockRepo := NewMockRepositoryPort(ctrl)
+				mockRepoPrepare := NewMockRepositoryPrepareCase(ctrl)
+				mockTemplateDefManager := NewMockTemplateDefManagerPort(ctrl)
+				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
+				mockVersionManager := NewMockVersionManagerPort(ctrl)
+				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
+				mockFileManager := NewMockFileManagerPort(ctrl)
+				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+
+				// Setup SombraDefManager mock, Save must not be called
+				sombraFile := entities.File("/path/to/project/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/project").
+					Return(sombraFile)
+
+				sombraDef := &entities.SombraDef{
+					Templates: []*entities.TemplateConfig{
+						{
+							URI:     "github.com/user/repo",
+							Path:    "src",
+							Current: "v0.9.0",
+							Vars: entities.Mappings{
+								"projectName": "test-project",
+							},
+						},
+					},
+				}
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(sombraDef, nil)
+
+				// Setup RepositoryPrepareCase mock
+				mockRepoPrepare.EXPECT().
+					Prepare("github.com/user/repo", "", nil).
+					Return(mockRepo, nil)
+
+				// Setup RepositoryPort mock
+				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Use("v1.0.0").Return("v1.0.0", nil)
+				mockRepo.EXPECT().Clean().Return(nil)
+
+				// Setup TemplateDefManager mock
+				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
+				mockTemplateDefManager.EXPECT().
+					GetFile("/tmp/repo", "").
+					Return(templateFile)
+
+				tplDef := &entities.TemplateDef{
+					Vars: []*entities.Var{{Name: "projectName"}},
+					Patterns: []*entities.Pattern{
+						{
+							Pattern: "src/**/*",
+						},
+					},
+				}
+				mockTemplateDefManager.EXPECT().
+					Render(templateFile, gomock.Any()).
+					Return(tplDef, nil)
+
+				// Setup DirectoryManager mock for file scan
+				scanResults := []entities.FileScanResult{
+					{File: "src/main.go"},
+					{File: "src/new.go"},
+					{File: "src/same.go"},
+				}
+				mockDirectoryManager.EXPECT().
+					ScanTree("/tmp/repo", []entities.Wildcard{"**/*"}, nil).
+					Return(createScanResultChannel(scanResults))
+
+				mapResult := &entities.MapResult{}
+				mockSombraEngine.EXPECT().
+					Match(gomock.Any(), tplDef.Patterns).
+					Return(true, tplDef.Patterns, nil).
+					Times(3)
+				mockSombraEngine.EXPECT().
+					Combine(tplDef.Patterns).
+					Return(mapResult).
+					Times(3)
+				mockSombraEngine.EXPECT().
+					NewFile(gomock.Any(), mapResult.Path, mapResult.Name).
+					DoAndReturn(func(fn entities.File, paths, names entities.MapList) entities.File {
+						return fn
+					}).
+					Times(3)
+				mockSombraEngine.EXPECT().
+					RenderContent(gomock.Any(), gomock.Any(), mapResult, gomock.Any()).
+					DoAndReturn(func(file entities.File, content []byte, items *entities.MapResult, vars entities.Mappings) ([]byte, error) {
+						return content, nil
+					}).
+					Times(3)
+
+				targetDir := filepath.Join("/path/to/project", "src")
+				for _, fn := range []entities.File{"src/main.go", "src/new.go", "src/same.go"} {
+					mockFileManager.EXPECT().
+						Read("/tmp/repo", fn).
+						Return([]byte("template"), nil)
+				}
+
+				// main.go exists with different content
+				mockFileManager.EXPECT().Exists(targetDir, entities.File("src/main.go")).Return(true)
+				mockFileManager.EXPECT().Read(targetDir, entities.File("src/main.go")).Return([]byte("local"), nil)
+
+				// new.go does not exist yet
+				mockFileManager.EXPECT().Exists(targetDir, entities.File("src/new.go")).Return(false)
+
+				// same.go is already up to date
+				mockFileManager.EXPECT().Exists(targetDir, entities.File("src/same.go")).Return(true)
+				mockFileManager.EXPECT().Read(targetDir, entities.File("src/same.go")).Return([]byte("template"), nil)
+
+				return mockRepoPrepare, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+			},
+			shouldError: false,
+			checkPlans: func(t *testing.T, plans []*entities.UpdatePlan) {
+				if len(plans) != 1 {
+					t.Fatalf("Expected 1 plan, got %d", len(plans))
+				}
+				plan := plans[0]
+				if !plan.DryRun || plan.From != "v0.9.0" || plan.To != "v1.0.0" {
+					t.Errorf("Unexpected plan header %+v", plan)
+				}
+				expected := []entities.FileChange{
+					{Operation: entities.FileModify, File: "src/main.go"},
+					{Operation: entities.FileCreate, File: "src/new.go"},
+				}
+				if len(plan.Changes) != len(expected) {
+					t.Fatalf("Expected %d changes, got %d", len(expected), len(plan.Changes))
+				}
+				for i, change := range plan.Changes {
+					if *change != expected[i] {
+						t.Errorf("Expected change %+v, got %+v", expected[i], *change)
+					}
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1189,7 +1358,7 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 			)
 
 			// Execute
-			err := interactor.LocalUpdate(tt.target, tt.uri, tt.tag)
+			plans, err := interactor.LocalUpdate(tt.target, tt.uri, LocalUpdateOptions{Tag: tt.tag, DryRun: tt.dryRun})
 
 			// Check error
 			if (err != nil) != tt.shouldError {
@@ -1199,6 +1368,10 @@ func TestLocalCopyInteractor_LocalUpdate(t *testing.T) {
 			if tt.shouldError && err != nil && tt.errorMsg != "" && err.Error() != tt.errorMsg {
 				t.Errorf("Expected error message %q, got %q", tt.errorMsg, err.Error())
 			}
+
+			if tt.checkPlans != nil {
+				tt.checkPlans(t, plans)
+			}
 		})
 	}
 }
@@ -1,11 +1,9 @@
 package usecases
 
 import (
-	"bytes"
 	"fmt"
 	"github.com/sombrahq/sombra-cli/internal/core/entities"
 	"path/filepath"
-	"regexp"
 )
 
 type DirectoryLocalDiffInteractor struct {
@@ -16,7 +14,7 @@ type DirectoryLocalDiffInteractor struct {
 	versionManager     VersionManagerPort
 	scanner            DirectoryManagerPort
 	localFiles         FileManagerPort
-	engine             SombraEngineCase
+	patchTransform     PatchTransformPort
 }
 
 func NewDirectoryLocalDiffInteractor(
@@ -27,7 +25,7 @@ func NewDirectoryLocalDiffInteractor(
 	versionManager VersionManagerPort,
 	scanner DirectoryManagerPort,
 	localFiles FileManagerPort,
-	engine SombraEngineCase,
+	patchTransform PatchTransformPort,
 ) *DirectoryLocalDiffInteractor {
 	return &DirectoryLocalDiffInteractor{
 		repoPrepare:        repoPrepare,
@@ -37,54 +35,62 @@ func NewDirectoryLocalDiffInteractor(
 		versionManager:     versionManager,
 		scanner:            scanner,
 		localFiles:         localFiles,
-		engine:             engine,
+		patchTransform:     patchTransform,
 	}
 }
 
-func (diff *DirectoryLocalDiffInteractor) LocalUpdate(target, uri, tag string) error {
+func (diff *DirectoryLocalDiffInteractor) LocalUpdate(target, uri string, opts LocalUpdateOptions) ([]*entities.UpdatePlan, error) {
 	// Read sombra file
 	sombraFile := diff.sombraDefManager.GetFile(target)
 	def, err := diff.sombraDefManager.Load(sombraFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Download and prepare the version
-	repo, err := diff.repoPrepare.Prepare(uri, "")
+	// Download and prepare the version, a template in a subdirectory clones the whole repository
+	repoURI, _ := entities.SplitSubdir(uri)
+	repo, err := diff.repoPrepare.Prepare(repoURI, "", templateAuth(def, uri))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer repo.Clean()
 
-	// If the tag variable is empty, find the latest tag
+	// If the tag variable is empty, each template looks for its version in the tags
+	// stepwise updates always need them to find the intermediate versions
 	var version entities.Version
 	var tags []string
-	if tag == "" {
+	if opts.Tag == "" || opts.Stepwise {
 		tags, err = repo.GetTags()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		version, err = diff.versionManager.GetLatest(tags, "*")
-		if err != nil {
-			return err
-		}
-	} else {
-		version = entities.Version(tag)
 	}
 
 	// Iterate over all templates
 	var fromVersion entities.Version
 	var sig int8
+	var steps []entities.Version
 	var tpl *entities.TemplateDef
 	var fn entities.File
+	rejected := 0
+	plans := make([]*entities.UpdatePlan, 0)
 	for _, template := range def.Templates {
 		if template.URI != uri {
 			continue
 		}
+
+		version, err = targetVersion(diff.versionManager, repo, tags, template, opts)
+		if err != nil {
+			return nil, err
+		}
 		if template.Current != "" {
-			sig, err = diff.versionManager.Compare(template.Current, version)
+			err = checkFixedVersion(template)
+			if err != nil {
+				return nil, err
+			}
+			sig, err = compareVersions(diff.versionManager, repo, template, template.Current, version)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			if sig >= 0 {
 				continue
@@ -95,123 +101,136 @@ func (diff *DirectoryLocalDiffInteractor) LocalUpdate(target, uri, tag string) e
 			fromVersion = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
 		}
 
-		// Render TemplateConfig Definition using Sombra configuration
-		fn = diff.templateDefManager.GetFile(repo.Dir())
-		tpl, err = diff.templateDefManager.Render(fn, template.Vars)
-		if err != nil {
-			return err
+		// templates following a branch have no releases to walk through
+		steps = []entities.Version{version}
+		if opts.Stepwise && template.Current != "" && template.Branch == "" {
+			steps, err = diff.steps(tags, template, version)
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		// prepare the diff
-		err = diff.applyDiff(repo, filepath.Join(target, template.Path), tpl.Patterns, fromVersion, version)
-		if err != nil {
-			return err
+		for _, step := range steps {
+			// the patterns of the step are the ones of its version
+			_, err = repo.Use(string(step))
+			if err != nil {
+				return nil, err
+			}
+
+			// Render TemplateConfig Definition using Sombra configuration
+			fn = diff.templateDefManager.GetFile(templateDir(repo, template), template.Flavor)
+			tpl, err = diff.templateDefManager.Render(fn, template.Vars)
+			if err != nil {
+				return nil, err
+			}
+			err = validateVars(tpl.Vars, template.Vars)
+			if err != nil {
+				return nil, err
+			}
+
+			// prepare the diff
+			plan := &entities.UpdatePlan{
+				URI:    uri,
+				Path:   template.Path,
+				From:   template.Current,
+				To:     step,
+				DryRun: opts.DryRun,
+			}
+			plan.Changes, plan.Patch, err = diff.applyDiff(repo, template, filepath.Join(target, template.Path), tpl.Patterns, fromVersion, opts.DryRun)
+			if err != nil {
+				return nil, err
+			}
+			plans = append(plans, plan)
+
+			// Rejected changes keep the current version, so the update can be retried after fixing them
+			if plan.Patch != nil && plan.Patch.Rejected() > 0 && !opts.AllowRejects {
+				rejected += plan.Patch.Rejected()
+				plan.Incomplete = true
+				break
+			}
+
+			// Update the template configuration
+			template.Current = step
+			fromVersion = step
+
+			// Each step is stored, so an interrupted update resumes from the last good version
+			if opts.Stepwise && !opts.DryRun {
+				err = diff.sombraDefManager.Save(sombraFile, def)
+				if err != nil {
+					return nil, err
+				}
+			}
 		}
+	}
 
-		// Update the template configuration
-		template.Current = version
+	// A dry run only reports the plan, nothing is stored
+	if opts.DryRun {
+		return plans, nil
 	}
 
 	// Store sombra file
 	err = diff.sombraDefManager.Save(sombraFile, def)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	if rejected > 0 {
+		return plans, fmt.Errorf("%d hunks could not be applied, review the .rej files or use --allow-rejects to keep the new version", rejected)
+	}
+
+	return plans, nil
 }
 
-func (diff *DirectoryLocalDiffInteractor) applyDiff(repo RepositoryPort, targetDir string, patterns []*entities.Pattern, fromVersion, toVersion entities.Version) error {
-	_, err := repo.Use(string(toVersion))
-	if err != nil {
-		return err
+// steps lists the releases between the current version of the template and the target one, which is always the last step
+func (diff *DirectoryLocalDiffInteractor) steps(tags []string, template *entities.TemplateConfig, version entities.Version) ([]entities.Version, error) {
+	steps := make([]entities.Version, 0)
+	tags = templateTags(tags, template)
+	current := trimTagPrefix(template, template.Current)
+	target := trimTagPrefix(template, version)
+	for {
+		next, err := diff.versionManager.GetNext(tags, templateConstraint(template), current)
+		if err != nil {
+			return nil, err
+		}
+
+		// stop at the target, or when there are no more releases
+		sig, err := diff.versionManager.Compare(next, target)
+		if err != nil {
+			return nil, err
+		}
+		if sig >= 0 || next == current {
+			break
+		}
+
+		steps = append(steps, entities.Version(template.TagPrefix)+next)
+		current = next
 	}
+	return append(steps, version), nil
+}
 
-	patch, err := repo.Diff(string(fromVersion))
+// applyDiff transforms the diff from the version to the checked out one, the step of the update
+func (diff *DirectoryLocalDiffInteractor) applyDiff(repo RepositoryPort, template *entities.TemplateConfig, targetDir string, patterns []*entities.Pattern, fromVersion entities.Version, dryRun bool) ([]*entities.FileChange, *entities.PatchResult, error) {
+	// the paths of a template in a subdirectory are relative to it
+	_, subdir := template.Source()
+	patch, err := repo.Diff(string(fromVersion), subdir)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	newDiff, err := diff.transformPatch(patch, patterns)
+	newDiff, changes, err := diff.patchTransform.Transform(patch, patterns)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	return diff.patchManager.Apply(targetDir, newDiff)
-}
-
-func (diff *DirectoryLocalDiffInteractor) transformPatch(content []byte, patterns []*entities.Pattern) ([]byte, error) {
-	// TODO: Move this code to a port/service
-	// https://git-scm.com/docs/diff-format
-	lines := bytes.Split(content, []byte("\n"))
-
-	var isMatch bool
-	var err error
-	var all []*entities.Pattern
-	var res *entities.MapResult
-	data := make([][]byte, 0)
-
-	// NOTE: notice how the regex defines the file name from the '/'
-	// because filenames for sombra work in the same way that gitignore works
-	startLine := regexp.MustCompile(`^diff\s+(--[a-z]+)?\s+a(/.*)\s+b(/.*)$`)
-
-	for _, line := range lines {
-		strLine := string(line)
-		isDiffStart := startLine.MatchString(strLine)
-
-		// `diff` lines state which file is being compared
-		// mappings need to be collected for this file
-		// if not mappings apply to the changes, it shouldn't be added
-		if isDiffStart {
-			groups := startLine.FindStringSubmatch(strLine)
-			target := groups[2]
-			isMatch, all, err = diff.engine.Match(entities.File(target), patterns)
-			if err != nil {
-				return nil, err
-			}
-
-			if all != nil {
-				res = diff.engine.Combine(all)
-			}
-		}
-
-		// diff heading need to be change based on collected mappings
-		if isMatch && isDiffStart {
-			groups := startLine.FindStringSubmatch(strLine)
-			aFile := groups[2]
-			newA := diff.engine.NewFile(entities.File(aFile), res.Path, res.Name)
-			bFile := groups[3]
-			newB := diff.engine.NewFile(entities.File(bFile), res.Path, res.Name)
-			line = []byte(fmt.Sprintf("diff %s a%s b%s", groups[1], string(newA), string(newB)))
-		} else
-
-		// both file names need to be change based on collected mappings
-		if isMatch && len(line) > 5 && (bytes.Equal([]byte("---"), line[:3]) || bytes.Equal([]byte("+++"), line[:3])) {
-			target := string(line[5:])
-			newFile := diff.engine.NewFile(entities.File(target), res.Path, res.Name)
-			newPath := []byte(string(newFile))
-			line = append(line[:5], newPath...)
-		} else
-
-		// change context needs to be updated following the mappings of the file
-		if isMatch && len(line) > 2 && bytes.Equal([]byte("@@"), line[:2]) {
-			n := bytes.Index(line[2:], []byte("@@")) + 2
-			newContent := diff.engine.NewContent(line[n:], res.Content)
-			line = append(line[:n], newContent...)
-		} else
-
-		// file content needs to be updated following the mappings of the file
-		if isMatch && len(line) > 1 && (bytes.Equal([]byte("-"), line[:1]) || bytes.Equal([]byte("+"), line[:1])) {
-			line = diff.engine.NewContent(line, res.Content)
-		}
-
-		if isMatch {
-			data = append(data, line)
-		}
+	if dryRun {
+		return changes, nil, nil
 	}
 
-	return bytes.Join(data, []byte("\n")), nil
-
+	res, err := diff.patchManager.Apply(targetDir, newDiff)
+	if err != nil {
+		return nil, nil, err
+	}
+	return changes, res, nil
 }
 
 var _ LocalUpdateCase = (*DirectoryLocalDiffInteractor)(nil)
@@ -10,11 +10,13 @@ import (
 
 func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 	tests := []struct {
-		name   string
-		target string
-		uri    string
-		tag    string
-		setup  func(ctrl *gomock.Controller) (
+		name         string
+		target       string
+		uri          string
+		tag          string
+		dryRun       bool
+		allowRejects bool
+		setup        func(ctrl *gomock.Controller) (
 			*MockRepositoryPrepareCase,
 			*MockPatchPort,
 			*MockTemplateDefManagerPort,
@@ -22,11 +24,12 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 			*MockVersionManagerPort,
 			*MockDirectoryManagerPort,
 			*MockFileManagerPort,
-			*MockSombraEngineCase,
+			*MockPatchTransformPort,
 			*MockRepositoryPort,
 		)
 		shouldError bool
 		errorMsg    string
+		checkPlans  func(t *testing.T, plans []*entities.UpdatePlan)
 	}{
 		{
 			name:   "successful update with explicit tag",
@@ -41,7 +44,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				*MockVersionManagerPort,
 				*MockDirectoryManagerPort,
 				*MockFileManagerPort,
-				*MockSombraEngineCase,
+				*MockPatchTransformPort,
 				*MockRepositoryPort,
 			) {
 				mockRepo := NewMockRepositoryPort(ctrl)
@@ -52,7 +55,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockVersionManager := NewMockVersionManagerPort(ctrl)
 				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
 				mockFileManager := NewMockFileManagerPort(ctrl)
-				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/project/sombra.yaml")
@@ -78,7 +81,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup VersionManager mock
@@ -93,11 +96,11 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
+					Vars: []*entities.Var{{Name: "projectName"}},
 					Patterns: []*entities.Pattern{
 						{
 							Pattern: "src/**/*",
@@ -128,51 +131,161 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
  }`)
 
 				mockRepo.EXPECT().
-					Diff("v0.9.0").
+					Diff("v0.9.0", "").
 					Return(patchContent, nil)
 
-				// Setup SombraEngine mock for patch transformation
-				mockSombraEngine.EXPECT().
-					Match(entities.File("/src/main.go"), tplDef.Patterns).
-					Return(true, tplDef.Patterns, nil)
+				// Transform the patch with the template mappings
+				transformedPatch := []byte(`diff --git a/src/main.go b/src/main.go
+--- a/src/main.go
++++ b/src/main.go
+@@ -1,5 +1,5 @@
+ package main
 
-				mapResult := &entities.MapResult{
-					Path: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-					Name: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-					Content: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-				}
-				mockSombraEngine.EXPECT().
-					Combine(tplDef.Patterns).
-					Return(mapResult)
-
-				// Transform file paths in diff
-				mockSombraEngine.EXPECT().
-					NewFile(entities.File("/src/main.go"), mapResult.Path, mapResult.Name).
-					Return(entities.File("/src/main.go")).AnyTimes()
-
-				// Transform content
-				mockSombraEngine.EXPECT().
-					NewContent(gomock.Any(), mapResult.Content).
-					DoAndReturn(func(content []byte, mappings entities.MapList) []byte {
-						if string(content) == " func main() {" {
-							return []byte(" func main() {")
+ func main() {
+-  // old code
++  // new code for test-project
+ }`)
+				mockPatchTransform.EXPECT().
+					Transform(patchContent, tplDef.Patterns).
+					Return(transformedPatch, []*entities.FileChange{{Operation: entities.FileModify, File: "/src/main.go"}}, nil)
+
+				mockPatchManager.EXPECT().
+					Apply("/path/to/project/src", gomock.Any()).
+					DoAndReturn(func(targetDir string, patchContent []byte) (*entities.PatchResult, error) {
+						if string(patchContent) != string(transformedPatch) {
+							t.Errorf("Expected transformed patch to be \n%s\n but got \n%s", transformedPatch, patchContent)
 						}
-						if string(content) == "-  // old code" {
-							return []byte("-  // old code")
+						return &entities.PatchResult{}, nil
+					})
+
+				// Check that SombraDef is saved with updated version
+				mockSombraDefManager.EXPECT().
+					Save(sombraFile, gomock.Any()).
+					DoAndReturn(func(fn entities.File, def *entities.SombraDef) error {
+						if len(def.Templates) != 1 {
+							t.Errorf("Expected 1 template, got %d", len(def.Templates))
 						}
-						if string(content) == "+  // new code for {{projectName}}" {
-							return []byte("+  // new code for test-project")
+
+						if def.Templates[0].Current != "v1.0.0" {
+							t.Errorf("Expected current version to be v1.0.0, got %s", def.Templates[0].Current)
 						}
-						return content
-					}).Times(3)
 
-				// Apply the transformed patch
+						return nil
+					})
+
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
+			},
+			shouldError: false,
+		},
+		{
+			name:   "template in a subdirectory with prefixed tags",
+			target: "/path/to/project",
+			uri:    "github.com/user/repo//api",
+			tag:    "",
+			setup: func(ctrl *gomock.Controller) (
+				*MockRepositoryPrepareCase,
+				*MockPatchPort,
+				*MockTemplateDefManagerPort,
+				*MockSombraDefManagerPort,
+				*MockVersionManagerPort,
+				*MockDirectoryManagerPort,
+				*MockFileManagerPort,
+				*MockPatchTransformPort,
+				*MockRepositoryPort,
+			) {
+				mockRepo := NewMockRepositoryPort(ctrl)
+				mockRepoPrepare := NewMockRepositoryPrepareCase(ctrl)
+				mockPatchManager := NewMockPatchPort(ctrl)
+				mockTemplateDefManager := NewMockTemplateDefManagerPort(ctrl)
+				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
+				mockVersionManager := NewMockVersionManagerPort(ctrl)
+				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
+				mockFileManager := NewMockFileManagerPort(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
+
+				// Setup SombraDefManager mock
+				sombraFile := entities.File("/path/to/project/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/project").
+					Return(sombraFile)
+
+				sombraDef := &entities.SombraDef{
+					Templates: []*entities.TemplateConfig{
+						{
+							URI:       "github.com/user/repo//api",
+							Path:      "src",
+							Current:   entities.Version("api/v0.9.0"),
+							TagPrefix: "api/",
+							Vars: entities.Mappings{
+								"projectName": "test-project",
+							},
+						},
+					},
+				}
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(sombraDef, nil)
+
+				// Setup RepositoryPrepareCase mock
+				mockRepoPrepare.EXPECT().
+					Prepare("github.com/user/repo", "", nil).
+					Return(mockRepo, nil)
+
+				// The whole repository is cloned, only the tags of the template are read
+				mockRepo.EXPECT().GetTags().Return([]string{"api/v0.9.0", "api/v1.0.0", "web/v2.0.0"}, nil)
+				mockVersionManager.EXPECT().
+					GetLatest([]string{"v0.9.0", "v1.0.0"}, "*").
+					Return(entities.Version("v1.0.0"), nil)
+				mockVersionManager.EXPECT().
+					Compare(entities.Version("v0.9.0"), entities.Version("v1.0.0")).
+					Return(int8(-1), nil)
+
+				// Setup RepositoryPort mock
+				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Clean().Return(nil)
+
+				// Setup TemplateDefManager mock
+				templateFile := entities.File("/tmp/repo/api/sombra-template.yaml")
+				mockTemplateDefManager.EXPECT().
+					GetFile("/tmp/repo/api", "").
+					Return(templateFile)
+
+				tplDef := &entities.TemplateDef{
+					Vars: []*entities.Var{{Name: "projectName"}},
+					Patterns: []*entities.Pattern{
+						{
+							Pattern: "src/**/*",
+							Default: entities.Mappings{
+								"projectName": "{{projectName}}",
+							},
+						},
+					},
+				}
+				mockTemplateDefManager.EXPECT().
+					Render(templateFile, gomock.Any()).
+					Return(tplDef, nil)
+
+				// The diff is limited to the subdirectory, its paths are relative to it
+				mockRepo.EXPECT().
+					Use("api/v1.0.0").
+					Return("some-commit-hash", nil)
+
+				patchContent := []byte(`diff --git a/src/main.go b/src/main.go
+--- a/src/main.go
++++ b/src/main.go
+@@ -1,5 +1,5 @@
+ package main
+
+ func main() {
+-  // old code
++  // new code for {{projectName}}
+ }`)
+
+				mockRepo.EXPECT().
+					Diff("api/v0.9.0", "api").
+					Return(patchContent, nil)
+
+				// Transform the patch with the template mappings
 				transformedPatch := []byte(`diff --git a/src/main.go b/src/main.go
 --- a/src/main.go
 +++ b/src/main.go
@@ -183,14 +296,17 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 -  // old code
 +  // new code for test-project
  }`)
+				mockPatchTransform.EXPECT().
+					Transform(patchContent, tplDef.Patterns).
+					Return(transformedPatch, []*entities.FileChange{{Operation: entities.FileModify, File: "/src/main.go"}}, nil)
 
 				mockPatchManager.EXPECT().
 					Apply("/path/to/project/src", gomock.Any()).
-					DoAndReturn(func(targetDir string, patchContent []byte) error {
+					DoAndReturn(func(targetDir string, patchContent []byte) (*entities.PatchResult, error) {
 						if string(patchContent) != string(transformedPatch) {
 							t.Errorf("Expected transformed patch to be \n%s\n but got \n%s", transformedPatch, patchContent)
 						}
-						return nil
+						return &entities.PatchResult{}, nil
 					})
 
 				// Check that SombraDef is saved with updated version
@@ -201,14 +317,14 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 							t.Errorf("Expected 1 template, got %d", len(def.Templates))
 						}
 
-						if def.Templates[0].Current != "v1.0.0" {
-							t.Errorf("Expected current version to be v1.0.0, got %s", def.Templates[0].Current)
+						if def.Templates[0].Current != "api/v1.0.0" {
+							t.Errorf("Expected current version to be api/v1.0.0, got %s", def.Templates[0].Current)
 						}
 
 						return nil
 					})
 
-				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
 			},
 			shouldError: false,
 		},
@@ -225,7 +341,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				*MockVersionManagerPort,
 				*MockDirectoryManagerPort,
 				*MockFileManagerPort,
-				*MockSombraEngineCase,
+				*MockPatchTransformPort,
 				*MockRepositoryPort,
 			) {
 				mockRepo := NewMockRepositoryPort(ctrl)
@@ -236,7 +352,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockVersionManager := NewMockVersionManagerPort(ctrl)
 				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
 				mockFileManager := NewMockFileManagerPort(ctrl)
-				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/project/sombra.yaml")
@@ -262,7 +378,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock for GetTags
@@ -287,11 +403,11 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
+					Vars: []*entities.Var{{Name: "projectName"}},
 					Patterns: []*entities.Pattern{
 						{
 							Pattern: "src/**/*",
@@ -322,51 +438,10 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
  }`)
 
 				mockRepo.EXPECT().
-					Diff("v0.9.0").
+					Diff("v0.9.0", "").
 					Return(patchContent, nil)
 
-				// Setup SombraEngine mock for patch transformation
-				mockSombraEngine.EXPECT().
-					Match(entities.File("/src/main.go"), tplDef.Patterns).
-					Return(true, tplDef.Patterns, nil)
-
-				mapResult := &entities.MapResult{
-					Path: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-					Name: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-					Content: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-				}
-				mockSombraEngine.EXPECT().
-					Combine(tplDef.Patterns).
-					Return(mapResult)
-
-				// Transform file paths in diff
-				mockSombraEngine.EXPECT().
-					NewFile(entities.File("/src/main.go"), mapResult.Path, mapResult.Name).
-					Return(entities.File("/src/main.go")).AnyTimes()
-
-				// Transform content
-				mockSombraEngine.EXPECT().
-					NewContent(gomock.Any(), mapResult.Content).
-					DoAndReturn(func(content []byte, mappings entities.MapList) []byte {
-						if string(content) == " func main() {" {
-							return []byte(" func main() {")
-						}
-						if string(content) == "-  // old code" {
-							return []byte("-  // old code")
-						}
-						if string(content) == "+  // new code for {{projectName}}" {
-							return []byte("+  // new code for test-project")
-						}
-						return content
-					}).Times(3)
-
-				// Apply the transformed patch
+				// Transform the patch with the template mappings
 				transformedPatch := []byte(`diff --git a/src/main.go b/src/main.go
 --- a/src/main.go
 +++ b/src/main.go
@@ -377,14 +452,17 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 -  // old code
 +  // new code for test-project
  }`)
+				mockPatchTransform.EXPECT().
+					Transform(patchContent, tplDef.Patterns).
+					Return(transformedPatch, []*entities.FileChange{{Operation: entities.FileModify, File: "/src/main.go"}}, nil)
 
 				mockPatchManager.EXPECT().
 					Apply("/path/to/project/src", gomock.Any()).
-					DoAndReturn(func(targetDir string, patchContent []byte) error {
+					DoAndReturn(func(targetDir string, patchContent []byte) (*entities.PatchResult, error) {
 						if string(patchContent) != string(transformedPatch) {
 							t.Errorf("Expected transformed patch to be \n%s\n but got \n%s", transformedPatch, patchContent)
 						}
-						return nil
+						return &entities.PatchResult{}, nil
 					})
 
 				// Check that SombraDef is saved with updated version
@@ -402,7 +480,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 						return nil
 					})
 
-				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
 			},
 			shouldError: false,
 		},
@@ -419,7 +497,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				*MockVersionManagerPort,
 				*MockDirectoryManagerPort,
 				*MockFileManagerPort,
-				*MockSombraEngineCase,
+				*MockPatchTransformPort,
 				*MockRepositoryPort,
 			) {
 				mockRepo := NewMockRepositoryPort(ctrl)
@@ -430,7 +508,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockVersionManager := NewMockVersionManagerPort(ctrl)
 				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
 				mockFileManager := NewMockFileManagerPort(ctrl)
-				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/project/sombra.yaml")
@@ -456,7 +534,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock
@@ -466,11 +544,11 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
+					Vars: []*entities.Var{{Name: "projectName"}},
 					Patterns: []*entities.Pattern{
 						{
 							Pattern: "src/**/*",
@@ -503,64 +581,31 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 +}`)
 
 				mockRepo.EXPECT().
-					Diff(emptyTreeHash).
+					Diff(emptyTreeHash, "").
 					Return(patchContent, nil)
 
-				// Setup SombraEngine mock for patch transformation
-				mockSombraEngine.EXPECT().
-					Match(entities.File("/src/main.go"), tplDef.Patterns).
-					Return(true, tplDef.Patterns, nil)
-
-				mapResult := &entities.MapResult{
-					Path: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-					Name: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-					Content: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-				}
-				mockSombraEngine.EXPECT().
-					Combine(tplDef.Patterns).
-					Return(mapResult)
-
-				// Transform file paths in diff
-				mockSombraEngine.EXPECT().
-					NewFile(entities.File("/src/main.go"), mapResult.Path, mapResult.Name).
-					Return(entities.File("/src/main.go")).AnyTimes()
-
-				// Transform content (only addition lines in this case)
-				mockSombraEngine.EXPECT().
-					NewContent(gomock.Any(), mapResult.Content).
-					DoAndReturn(func(content []byte, mappings entities.MapList) []byte {
-						if string(content) == "+package main" {
-							return []byte("+package main")
-						}
-						if string(content) == "+" {
-							return []byte("+")
-						}
-						if string(content) == "+func main() {" {
-							return []byte("+func main() {")
-						}
-						if string(content) == "+  // {{projectName}} implementation" {
-							return []byte("+  // test-project implementation")
-						}
-						if string(content) == "+}" {
-							return []byte("+}")
-						}
-						return content
-					}).Times(5)
+				// Transform the patch with the template mappings
+				transformedPatch := []byte(`diff --git a/src/main.go b/src/main.go
+--- a/src/main.go
++++ b/src/main.go
+@@ -0,0 +1,5 @@
++package main
++
++func main() {
++  // test-project implementation
++}`)
+				mockPatchTransform.EXPECT().
+					Transform(patchContent, tplDef.Patterns).
+					Return(transformedPatch, []*entities.FileChange{{Operation: entities.FileCreate, File: "/src/main.go"}}, nil)
 
 				mockPatchManager.EXPECT().
 					Apply("/path/to/project/src", gomock.Any()).
-					DoAndReturn(func(targetDir string, patchContent []byte) error {
+					DoAndReturn(func(targetDir string, patchContent []byte) (*entities.PatchResult, error) {
 						// We don't do exact string comparison here due to potential whitespace differences
 						if len(patchContent) == 0 {
 							t.Errorf("Expected non-empty transformed patch")
 						}
-						return nil
+						return &entities.PatchResult{}, nil
 					})
 
 				// Check that SombraDef is saved with updated version
@@ -578,7 +623,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 						return nil
 					})
 
-				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
 			},
 			shouldError: false,
 		},
@@ -595,7 +640,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				*MockVersionManagerPort,
 				*MockDirectoryManagerPort,
 				*MockFileManagerPort,
-				*MockSombraEngineCase,
+				*MockPatchTransformPort,
 				*MockRepositoryPort,
 			) {
 				mockRepo := NewMockRepositoryPort(ctrl)
@@ -606,7 +651,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockVersionManager := NewMockVersionManagerPort(ctrl)
 				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
 				mockFileManager := NewMockFileManagerPort(ctrl)
-				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/project/sombra.yaml")
@@ -632,7 +677,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup VersionManager mock - returns 1 (current > target)
@@ -648,7 +693,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 					Save(sombraFile, sombraDef).
 					Return(nil)
 
-				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
 			},
 			shouldError: false,
 		},
@@ -665,7 +710,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				*MockVersionManagerPort,
 				*MockDirectoryManagerPort,
 				*MockFileManagerPort,
-				*MockSombraEngineCase,
+				*MockPatchTransformPort,
 				*MockRepositoryPort,
 			) {
 				mockRepo := NewMockRepositoryPort(ctrl)
@@ -676,7 +721,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockVersionManager := NewMockVersionManagerPort(ctrl)
 				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
 				mockFileManager := NewMockFileManagerPort(ctrl)
-				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
 				// Setup SombraDefManager mock to fail
 				sombraFile := entities.File("/path/to/project/sombra.yaml")
@@ -688,7 +733,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 					Load(sombraFile).
 					Return(nil, errors.New("sombra definition load failed"))
 
-				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
 			},
 			shouldError: true,
 			errorMsg:    "sombra definition load failed",
@@ -706,7 +751,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				*MockVersionManagerPort,
 				*MockDirectoryManagerPort,
 				*MockFileManagerPort,
-				*MockSombraEngineCase,
+				*MockPatchTransformPort,
 				*MockRepositoryPort,
 			) {
 				mockRepo := NewMockRepositoryPort(ctrl)
@@ -717,7 +762,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockVersionManager := NewMockVersionManagerPort(ctrl)
 				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
 				mockFileManager := NewMockFileManagerPort(ctrl)
-				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/project/sombra.yaml")
@@ -743,10 +788,10 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock to fail
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(nil, errors.New("repository preparation failed"))
 
-				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
 			},
 			shouldError: true,
 			errorMsg:    "repository preparation failed",
@@ -764,7 +809,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				*MockVersionManagerPort,
 				*MockDirectoryManagerPort,
 				*MockFileManagerPort,
-				*MockSombraEngineCase,
+				*MockPatchTransformPort,
 				*MockRepositoryPort,
 			) {
 				mockRepo := NewMockRepositoryPort(ctrl)
@@ -775,7 +820,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockVersionManager := NewMockVersionManagerPort(ctrl)
 				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
 				mockFileManager := NewMockFileManagerPort(ctrl)
-				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/project/sombra.yaml")
@@ -801,7 +846,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup RepositoryPort mock for GetTags to fail
@@ -812,7 +857,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				// Cleanup should still happen
 				mockRepo.EXPECT().Clean().Return(nil)
 
-				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
 			},
 			shouldError: true,
 			errorMsg:    "get tags failed",
@@ -830,7 +875,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				*MockVersionManagerPort,
 				*MockDirectoryManagerPort,
 				*MockFileManagerPort,
-				*MockSombraEngineCase,
+				*MockPatchTransformPort,
 				*MockRepositoryPort,
 			) {
 				mockRepo := NewMockRepositoryPort(ctrl)
@@ -841,7 +886,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockVersionManager := NewMockVersionManagerPort(ctrl)
 				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
 				mockFileManager := NewMockFileManagerPort(ctrl)
-				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/project/sombra.yaml")
@@ -867,7 +912,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup VersionManager mock
@@ -879,17 +924,19 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
 				mockRepo.EXPECT().Clean().Return(nil)
 
+				mockRepo.EXPECT().Use("v1.0.0").Return("v1.0.0", nil)
+
 				// Setup TemplateDefManager mock to fail on render
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				mockTemplateDefManager.EXPECT().
 					Render(templateFile, gomock.Any()).
 					Return(nil, errors.New("template render failed"))
 
-				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
 			},
 			shouldError: true,
 			errorMsg:    "template render failed",
@@ -907,7 +954,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				*MockVersionManagerPort,
 				*MockDirectoryManagerPort,
 				*MockFileManagerPort,
-				*MockSombraEngineCase,
+				*MockPatchTransformPort,
 				*MockRepositoryPort,
 			) {
 				mockRepo := NewMockRepositoryPort(ctrl)
@@ -918,7 +965,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockVersionManager := NewMockVersionManagerPort(ctrl)
 				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
 				mockFileManager := NewMockFileManagerPort(ctrl)
-				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/project/sombra.yaml")
@@ -944,7 +991,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup VersionManager mock
@@ -956,33 +1003,12 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
 				mockRepo.EXPECT().Clean().Return(nil)
 
-				// Setup TemplateDefManager mock
-				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
-				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
-					Return(templateFile)
-
-				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
-					Patterns: []*entities.Pattern{
-						{
-							Pattern: "src/**/*",
-							Default: entities.Mappings{
-								"projectName": "{{projectName}}",
-							},
-						},
-					},
-				}
-				mockTemplateDefManager.EXPECT().
-					Render(templateFile, gomock.Any()).
-					Return(tplDef, nil)
-
-				// Setup RepositoryPort mock for use to fail
+				// Setup RepositoryPort mock for use to fail, the definition is read from the version
 				mockRepo.EXPECT().
 					Use("v1.0.0").
 					Return("", errors.New("repository use version failed"))
 
-				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
 			},
 			shouldError: true,
 			errorMsg:    "repository use version failed",
@@ -1000,7 +1026,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				*MockVersionManagerPort,
 				*MockDirectoryManagerPort,
 				*MockFileManagerPort,
-				*MockSombraEngineCase,
+				*MockPatchTransformPort,
 				*MockRepositoryPort,
 			) {
 				mockRepo := NewMockRepositoryPort(ctrl)
@@ -1011,7 +1037,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockVersionManager := NewMockVersionManagerPort(ctrl)
 				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
 				mockFileManager := NewMockFileManagerPort(ctrl)
-				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/project/sombra.yaml")
@@ -1037,7 +1063,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup VersionManager mock
@@ -1052,11 +1078,11 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
+					Vars: []*entities.Var{{Name: "projectName"}},
 					Patterns: []*entities.Pattern{
 						{
 							Pattern: "src/**/*",
@@ -1076,10 +1102,10 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 					Return("some-commit-hash", nil)
 
 				mockRepo.EXPECT().
-					Diff("v0.9.0").
+					Diff("v0.9.0", "").
 					Return(nil, errors.New("repository diff failed"))
 
-				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
 			},
 			shouldError: true,
 			errorMsg:    "repository diff failed",
@@ -1097,7 +1123,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				*MockVersionManagerPort,
 				*MockDirectoryManagerPort,
 				*MockFileManagerPort,
-				*MockSombraEngineCase,
+				*MockPatchTransformPort,
 				*MockRepositoryPort,
 			) {
 				mockRepo := NewMockRepositoryPort(ctrl)
@@ -1108,7 +1134,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				mockVersionManager := NewMockVersionManagerPort(ctrl)
 				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
 				mockFileManager := NewMockFileManagerPort(ctrl)
-				mockSombraEngine := NewMockSombraEngineCase(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
 				// Setup SombraDefManager mock
 				sombraFile := entities.File("/path/to/project/sombra.yaml")
@@ -1134,7 +1160,7 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 
 				// Setup RepositoryPrepareCase mock
 				mockRepoPrepare.EXPECT().
-					Prepare("github.com/user/repo", "").
+					Prepare("github.com/user/repo", "", nil).
 					Return(mockRepo, nil)
 
 				// Setup VersionManager mock
@@ -1149,11 +1175,11 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 				// Setup TemplateDefManager mock
 				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
 				mockTemplateDefManager.EXPECT().
-					GetFile("/tmp/repo").
+					GetFile("/tmp/repo", "").
 					Return(templateFile)
 
 				tplDef := &entities.TemplateDef{
-					Vars: []string{"projectName"},
+					Vars: []*entities.Var{{Name: "projectName"}},
 					Patterns: []*entities.Pattern{
 						{
 							Pattern: "src/**/*",
@@ -1184,86 +1210,509 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
  }`)
 
 				mockRepo.EXPECT().
-					Diff("v0.9.0").
+					Diff("v0.9.0", "").
 					Return(patchContent, nil)
 
-				// Setup SombraEngine mock for patch transformation
-				mockSombraEngine.EXPECT().
-					Match(entities.File("/src/main.go"), tplDef.Patterns).
-					Return(true, tplDef.Patterns, nil)
+				// Transform the patch with the template mappings
+				transformedPatch := []byte(`diff --git a/src/main.go b/src/main.go
+--- a/src/main.go
++++ b/src/main.go
+@@ -1,5 +1,5 @@
+ package main
 
-				mapResult := &entities.MapResult{
-					Path: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-					Name: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-					Content: entities.MapList{
-						{Key: "projectName", Value: "test-project"},
-					},
-				}
-				mockSombraEngine.EXPECT().
-					Combine(tplDef.Patterns).
-					Return(mapResult)
-
-				// Transform file paths in diff
-				mockSombraEngine.EXPECT().
-					NewFile(entities.File("/src/main.go"), mapResult.Path, mapResult.Name).
-					Return(entities.File("/src/main.go")).AnyTimes()
-
-				// Transform content
-				mockSombraEngine.EXPECT().
-					NewContent(gomock.Any(), mapResult.Content).
-					DoAndReturn(func(content []byte, mappings entities.MapList) []byte {
-						if string(content) == " func main() {" {
-							return []byte(" func main() {")
-						}
-						if string(content) == "-  // old code" {
-							return []byte("-  // old code")
-						}
-						if string(content) == "+  // new code for {{projectName}}" {
-							return []byte("+  // new code for test-project")
-						}
-						return content
-					}).Times(3)
+ func main() {
+-  // old code
++  // new code for test-project
+ }`)
+				mockPatchTransform.EXPECT().
+					Transform(patchContent, tplDef.Patterns).
+					Return(transformedPatch, []*entities.FileChange{{Operation: entities.FileModify, File: "/src/main.go"}}, nil)
 
 				// Patch apply fails
 				mockPatchManager.EXPECT().
 					Apply("/path/to/project/src", gomock.Any()).
-					Return(errors.New("patch apply failed"))
+					Return(nil, errors.New("patch apply failed"))
 
-				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, mockRepo
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
 			},
 			shouldError: true,
 			errorMsg:    "patch apply failed",
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+		{
+			name:   "rejected hunks keep the current version",
+			target: "/path/to/project",
+			uri:    "github.com/user/repo",
+			tag:    "v1.0.0",
+			setup: func(ctrl *gomock.Controller) (
+				*MockRepositoryPrepareCase,
+				*MockPatchPort,
+				*MockTemplateDefManagerPort,
+				*MockSombraDefManagerPort,
+				*MockVersionManagerPort,
+				*MockDirectoryManagerPort,
+				*MockFileManagerPort,
+				*MockPatchTransformPort,
+				*MockRepositoryPort,
+			) {
+				mockRepo := NewMockRepositoryPort(ctrl)
+				mockRepoPrepare := NewMockRepositoryPrepareCase(ctrl)
+				mockPatchManager := NewMockPatchPort(ctrl)
+				mockTemplateDefManager := NewMockTemplateDefManagerPort(ctrl)
+				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
+				mockVersionManager := NewMockVersionManagerPort(ctrl)
+				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
+				mockFileManager := NewMockFileManagerPort(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
 
-			// Set up mocks
-			mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager,
-				mockVersionManager, mockDirectoryManager, mockFileManager, mockSombraEngine, _ :=
-				tt.setup(ctrl)
+				// Setup SombraDefManager mock
+				sombraFile := entities.File("/path/to/project/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/project").
+					Return(sombraFile)
 
-			// Create interactor
-			interactor := NewDirectoryLocalDiffInteractor(
-				mockRepoPrepare,
-				mockPatchManager,
-				mockTemplateDefManager,
-				mockSombraDefManager,
-				mockVersionManager,
-				mockDirectoryManager,
-				mockFileManager,
-				mockSombraEngine,
-			)
+				sombraDef := &entities.SombraDef{
+					Templates: []*entities.TemplateConfig{
+						{
+							URI:     "github.com/user/repo",
+							Path:    "src",
+							Current: entities.Version("v0.9.0"),
+							Vars: entities.Mappings{
+								"projectName": "test-project",
+							},
+						},
+					},
+				}
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(sombraDef, nil)
 
-			// Execute
-			err := interactor.LocalUpdate(tt.target, tt.uri, tt.tag)
+				// Setup RepositoryPrepareCase mock
+				mockRepoPrepare.EXPECT().
+					Prepare("github.com/user/repo", "", nil).
+					Return(mockRepo, nil)
+
+				// Setup VersionManager mock
+				mockVersionManager.EXPECT().
+					Compare(entities.Version("v0.9.0"), entities.Version("v1.0.0")).
+					Return(int8(-1), nil)
+
+				// Setup RepositoryPort mock
+				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Clean().Return(nil)
+
+				// Setup TemplateDefManager mock
+				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
+				mockTemplateDefManager.EXPECT().
+					GetFile("/tmp/repo", "").
+					Return(templateFile)
+
+				tplDef := &entities.TemplateDef{
+					Vars: []*entities.Var{{Name: "projectName"}},
+					Patterns: []*entities.Pattern{
+						{
+							Pattern: "src/**/*",
+							Default: entities.Mappings{
+								"projectName": "{{projectName}}",
+							},
+						},
+					},
+				}
+				mockTemplateDefManager.EXPECT().
+					Render(templateFile, gomock.Any()).
+					Return(tplDef, nil)
+
+				// Setup RepositoryPort mock for diff operations
+				mockRepo.EXPECT().
+					Use("v1.0.0").
+					Return("some-commit-hash", nil)
+
+				patchContent := []byte(`diff --git a/src/main.go b/src/main.go
+--- a/src/main.go
++++ b/src/main.go
+@@ -1,5 +1,5 @@
+ package main
+
+ func main() {
+-  // old code
++  // new code for {{projectName}}
+ }`)
+
+				mockRepo.EXPECT().
+					Diff("v0.9.0", "").
+					Return(patchContent, nil)
+
+				// Transform the patch with the template mappings
+				transformedPatch := []byte(`diff --git a/src/main.go b/src/main.go
+--- a/src/main.go
++++ b/src/main.go
+@@ -1,5 +1,5 @@
+ package main
+
+ func main() {
+-  // old code
++  // new code for test-project
+ }`)
+				mockPatchTransform.EXPECT().
+					Transform(patchContent, tplDef.Patterns).
+					Return(transformedPatch, []*entities.FileChange{{Operation: entities.FileModify, File: "/src/main.go"}}, nil)
+
+				// Patch applied with rejected hunks
+				mockPatchManager.EXPECT().
+					Apply("/path/to/project/src", gomock.Any()).
+					Return(&entities.PatchResult{
+						Files: []*entities.PatchFileResult{
+							{
+								File: "/src/main.go",
+								Hunks: []*entities.PatchHunkResult{
+									{Number: 1, Status: entities.HunkRejected, Line: 1},
+								},
+								RejectFile: "/src/main.go.rej",
+							},
+						},
+					}, nil)
+
+				// The sombra file is saved without bumping the version
+				mockSombraDefManager.EXPECT().
+					Save(sombraFile, gomock.Any()).
+					DoAndReturn(func(fn entities.File, def *entities.SombraDef) error {
+						if def.Templates[0].Current != "v0.9.0" {
+							t.Errorf("Expected version to stay v0.9.0, got %s", def.Templates[0].Current)
+						}
+						return nil
+					})
+
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
+			},
+			shouldError: true,
+			errorMsg:    "1 hunks could not be applied, review the .rej files or use --allow-rejects to keep the new version",
+			checkPlans: func(t *testing.T, plans []*entities.UpdatePlan) {
+				if len(plans) != 1 || plans[0].Patch == nil || plans[0].Patch.Rejected() != 1 || !plans[0].Incomplete {
+					t.Fatalf("Expected a plan with 1 rejected hunk, got %+v", plans)
+				}
+			},
+		},
+		{
+			name:         "allow rejects bumps the version",
+			target:       "/path/to/project",
+			uri:          "github.com/user/repo",
+			tag:          "v1.0.0",
+			allowRejects: true,
+			setup: func(ctrl *gomock.Controller) (
+				*MockRepositoryPrepareCase,
+				*MockPatchPort,
+				*MockTemplateDefManagerPort,
+				*MockSombraDefManagerPort,
+				*MockVersionManagerPort,
+				*MockDirectoryManagerPort,
+				*MockFileManagerPort,
+				*MockPatchTransformPort,
+				*MockRepositoryPort,
+			) {
+				mockRepo := NewMockRepositoryPort(ctrl)
+				mockRepoPrepare := NewMockRepositoryPrepareCase(ctrl)
+				mockPatchManager := NewMockPatchPort(ctrl)
+				mockTemplateDefManager := NewMockTemplateDefManagerPort(ctrl)
+				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
+				mockVersionManager := NewMockVersionManagerPort(ctrl)
+				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
+				mockFileManager := NewMockFileManagerPort(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
+
+				// Setup SombraDefManager mock
+				sombraFile := entities.File("/path/to/project/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/project").
+					Return(sombraFile)
+
+				sombraDef := &entities.SombraDef{
+					Templates: []*entities.TemplateConfig{
+						{
+							URI:     "github.com/user/repo",
+							Path:    "src",
+							Current: entities.Version("v0.9.0"),
+							Vars: entities.Mappings{
+								"projectName": "test-project",
+							},
+						},
+					},
+				}
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(sombraDef, nil)
+
+				// Setup RepositoryPrepareCase mock
+				mockRepoPrepare.EXPECT().
+					Prepare("github.com/user/repo", "", nil).
+					Return(mockRepo, nil)
+
+				// Setup VersionManager mock
+				mockVersionManager.EXPECT().
+					Compare(entities.Version("v0.9.0"), entities.Version("v1.0.0")).
+					Return(int8(-1), nil)
+
+				// Setup RepositoryPort mock
+				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Clean().Return(nil)
+
+				// Setup TemplateDefManager mock
+				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
+				mockTemplateDefManager.EXPECT().
+					GetFile("/tmp/repo", "").
+					Return(templateFile)
+
+				tplDef := &entities.TemplateDef{
+					Vars: []*entities.Var{{Name: "projectName"}},
+					Patterns: []*entities.Pattern{
+						{
+							Pattern: "src/**/*",
+							Default: entities.Mappings{
+								"projectName": "{{projectName}}",
+							},
+						},
+					},
+				}
+				mockTemplateDefManager.EXPECT().
+					Render(templateFile, gomock.Any()).
+					Return(tplDef, nil)
+
+				// Setup RepositoryPort mock for diff operations
+				mockRepo.EXPECT().
+					Use("v1.0.0").
+					Return("some-commit-hash", nil)
+
+				patchContent := []byte(`diff --git a/src/main.go b/src/main.go
+--- a/src/main.go
++++ b/src/main.go
+@@ -1,5 +1,5 @@
+ package main
+
+ func main() {
+-  // old code
++  // new code for {{projectName}}
+ }`)
+
+				mockRepo.EXPECT().
+					Diff("v0.9.0", "").
+					Return(patchContent, nil)
+
+				// Transform the patch with the template mappings
+				transformedPatch := []byte(`diff --git a/src/main.go b/src/main.go
+--- a/src/main.go
++++ b/src/main.go
+@@ -1,5 +1,5 @@
+ package main
+
+ func main() {
+-  // old code
++  // new code for test-project
+ }`)
+				mockPatchTransform.EXPECT().
+					Transform(patchContent, tplDef.Patterns).
+					Return(transformedPatch, []*entities.FileChange{{Operation: entities.FileModify, File: "/src/main.go"}}, nil)
+
+				// Patch applied with rejected hunks
+				mockPatchManager.EXPECT().
+					Apply("/path/to/project/src", gomock.Any()).
+					Return(&entities.PatchResult{
+						Files: []*entities.PatchFileResult{
+							{
+								File: "/src/main.go",
+								Hunks: []*entities.PatchHunkResult{
+									{Number: 1, Status: entities.HunkRejected, Line: 1},
+								},
+								RejectFile: "/src/main.go.rej",
+							},
+						},
+					}, nil)
+
+				mockSombraDefManager.EXPECT().
+					Save(sombraFile, gomock.Any()).
+					DoAndReturn(func(fn entities.File, def *entities.SombraDef) error {
+						if def.Templates[0].Current != "v1.0.0" {
+							t.Errorf("Expected version v1.0.0, got %s", def.Templates[0].Current)
+						}
+						return nil
+					})
+
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
+			},
+			checkPlans: func(t *testing.T, plans []*entities.UpdatePlan) {
+				if len(plans) != 1 || plans[0].Patch.Rejected() != 1 || plans[0].Incomplete {
+					t.Fatalf("Expected a plan with 1 rejected hunk, got %+v", plans)
+				}
+			},
+		},
+		{
+			name:   "dry run reports patch changes without applying",
+			target: "/path/to/project",
+			uri:    "github.com/user/repo",
+			tag:    "v1.0.0",
+			dryRun: true,
+			setup: func(ctrl *gomock.Controller) (
+				*MockRepositoryPrepareCase,
+				*MockPatchPort,
+				*MockTemplateDefManagerPort,
+				*MockSombraDefManagerPort,
+				*MockVersionManagerPort,
+				*MockDirectoryManagerPort,
+				*MockFileManagerPort,
+				*MockPatchTransformPort,
+				*MockRepositoryPort,
+			) {
+				mockRepo := NewMockRepositoryPort(ctrl)
+				mockRepoPrepare := NewMockRepositoryPrepareCase(ctrl)
+				mockPatchManager := NewMockPatchPort(ctrl)
+				mockTemplateDefManager := NewMockTemplateDefManagerPort(ctrl)
+				mockSombraDefManager := NewMockSombraDefManagerPort(ctrl)
+				mockVersionManager := NewMockVersionManagerPort(ctrl)
+				mockDirectoryManager := NewMockDirectoryManagerPort(ctrl)
+				mockFileManager := NewMockFileManagerPort(ctrl)
+				mockPatchTransform := NewMockPatchTransformPort(ctrl)
+
+				// Setup SombraDefManager mock, Save must not be called
+				sombraFile := entities.File("/path/to/project/sombra.yaml")
+				mockSombraDefManager.EXPECT().
+					GetFile("/path/to/project").
+					Return(sombraFile)
+
+				sombraDef := &entities.SombraDef{
+					Templates: []*entities.TemplateConfig{
+						{
+							URI:     "github.com/user/repo",
+							Current: entities.Version("v0.9.0"),
+						},
+					},
+				}
+				mockSombraDefManager.EXPECT().
+					Load(sombraFile).
+					Return(sombraDef, nil)
+
+				mockRepoPrepare.EXPECT().
+					Prepare("github.com/user/repo", "", nil).
+					Return(mockRepo, nil)
+
+				mockVersionManager.EXPECT().
+					Compare(entities.Version("v0.9.0"), entities.Version("v1.0.0")).
+					Return(int8(-1), nil)
+
+				mockRepo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+				mockRepo.EXPECT().Clean().Return(nil)
+
+				templateFile := entities.File("/tmp/repo/sombra-template.yaml")
+				mockTemplateDefManager.EXPECT().
+					GetFile("/tmp/repo", "").
+					Return(templateFile)
+
+				tplDef := &entities.TemplateDef{
+					Patterns: []*entities.Pattern{
+						{Pattern: "**/*"},
+					},
+				}
+				mockTemplateDefManager.EXPECT().
+					Render(templateFile, gomock.Any()).
+					Return(tplDef, nil)
+
+				mockRepo.EXPECT().
+					Use("v1.0.0").
+					Return("v1.0.0", nil)
+
+				patchContent := []byte(`diff --git a/src/new.go b/src/new.go
+new file mode 100644
+--- /dev/null
++++ b/src/new.go
+@@ -0,0 +1 @@
++package src
+diff --git a/src/old.go b/src/old.go
+deleted file mode 100644
+--- a/src/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package src
+diff --git a/src/a.go b/src/b.go
+similarity index 100%
+rename from src/a.go
+rename to src/b.go`)
+				mockRepo.EXPECT().
+					Diff("v0.9.0", "").
+					Return(patchContent, nil)
+
+				// Transform the patch with the template mappings
+				transformedPatch := []byte(`diff --git a/src/new.go b/src/new.go
+new file mode 100644
+--- /dev/null
++++ b/src/new.go
+@@ -0,0 +1 @@
++package src
+diff --git a/src/old.go b/src/old.go
+deleted file mode 100644
+--- a/src/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package src
+diff --git a/src/a.go b/src/b.go
+similarity index 100%
+rename from src/a.go
+rename to src/b.go`)
+				mockPatchTransform.EXPECT().
+					Transform(patchContent, tplDef.Patterns).
+					Return(transformedPatch, []*entities.FileChange{
+						{Operation: entities.FileCreate, File: "/src/new.go"},
+						{Operation: entities.FileDelete, File: "/src/old.go"},
+						{Operation: entities.FileRename, File: "/src/b.go", From: "/src/a.go"},
+					}, nil)
+
+				return mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager, mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, mockRepo
+			},
+			shouldError: false,
+			checkPlans: func(t *testing.T, plans []*entities.UpdatePlan) {
+				if len(plans) != 1 {
+					t.Fatalf("Expected 1 plan, got %d", len(plans))
+				}
+				plan := plans[0]
+				if !plan.DryRun || plan.From != "v0.9.0" || plan.To != "v1.0.0" {
+					t.Errorf("Unexpected plan header %+v", plan)
+				}
+				expected := []entities.FileChange{
+					{Operation: entities.FileCreate, File: "/src/new.go"},
+					{Operation: entities.FileDelete, File: "/src/old.go"},
+					{Operation: entities.FileRename, File: "/src/b.go", From: "/src/a.go"},
+				}
+				if len(plan.Changes) != len(expected) {
+					t.Fatalf("Expected %d changes, got %d", len(expected), len(plan.Changes))
+				}
+				for i, change := range plan.Changes {
+					if *change != expected[i] {
+						t.Errorf("Expected change %+v, got %+v", expected[i], *change)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Set up mocks
+			mockRepoPrepare, mockPatchManager, mockTemplateDefManager, mockSombraDefManager,
+				mockVersionManager, mockDirectoryManager, mockFileManager, mockPatchTransform, _ :=
+				tt.setup(ctrl)
+
+			// Create interactor
+			interactor := NewDirectoryLocalDiffInteractor(
+				mockRepoPrepare,
+				mockPatchManager,
+				mockTemplateDefManager,
+				mockSombraDefManager,
+				mockVersionManager,
+				mockDirectoryManager,
+				mockFileManager,
+				mockPatchTransform,
+			)
+
+			// Execute
+			plans, err := interactor.LocalUpdate(tt.target, tt.uri, LocalUpdateOptions{Tag: tt.tag, DryRun: tt.dryRun, AllowRejects: tt.allowRejects})
 
 			// Check error
 			if (err != nil) != tt.shouldError {
@@ -1273,6 +1722,274 @@ func TestDirectoryLocalDiffInteractor_LocalUpdate(t *testing.T) {
 			if tt.shouldError && err != nil && tt.errorMsg != "" && err.Error() != tt.errorMsg {
 				t.Errorf("Expected error message %q, got %q", tt.errorMsg, err.Error())
 			}
+
+			if tt.checkPlans != nil {
+				tt.checkPlans(t, plans)
+			}
+		})
+	}
+}
+
+type localStepwiseMocks struct {
+	repo               *MockRepositoryPort
+	repoPrepare        *MockRepositoryPrepareCase
+	patchManager       *MockPatchPort
+	templateDefManager *MockTemplateDefManagerPort
+	sombraDefManager   *MockSombraDefManagerPort
+	versionManager     *MockVersionManagerPort
+	patchTransform     *MockPatchTransformPort
+}
+
+func TestDirectoryLocalDiffInteractor_LocalUpdateStepwise(t *testing.T) {
+	sombraFile := entities.File("/path/to/project/sombra.yaml")
+	templateFile := entities.File("/tmp/repo/.sombra/default.yaml")
+	tplDef := &entities.TemplateDef{Patterns: []*entities.Pattern{{Pattern: "**/*"}}}
+	tags := []string{"v1.0.0", "v1.1.0", "v1.2.0", "v2.0.0"}
+
+	// versions are compared as strings, which works for the tags above
+	versions := func(m *localStepwiseMocks) {
+		m.versionManager.EXPECT().GetLatest(tags, "*").Return(entities.Version("v2.0.0"), nil).AnyTimes()
+		m.versionManager.EXPECT().Compare(gomock.Any(), gomock.Any()).DoAndReturn(func(v1, v2 entities.Version) (int8, error) {
+			switch {
+			case v1 < v2:
+				return -1, nil
+			case v1 > v2:
+				return 1, nil
+			}
+			return 0, nil
+		}).AnyTimes()
+		m.versionManager.EXPECT().GetNext(tags, "*", gomock.Any()).DoAndReturn(func(tags []string, constraint string, current entities.Version) (entities.Version, error) {
+			for _, tag := range tags {
+				if entities.Version(tag) > current {
+					return entities.Version(tag), nil
+				}
+			}
+			return current, nil
+		}).AnyTimes()
+	}
+	prepare := func(m *localStepwiseMocks, def *entities.SombraDef) {
+		m.sombraDefManager.EXPECT().GetFile("/path/to/project").Return(sombraFile)
+		m.sombraDefManager.EXPECT().Load(sombraFile).Return(def, nil)
+		m.repoPrepare.EXPECT().Prepare("github.com/user/repo", "", nil).Return(m.repo, nil)
+		m.repo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+		m.repo.EXPECT().Clean().Return(nil)
+		m.repo.EXPECT().GetTags().Return(tags, nil)
+		m.templateDefManager.EXPECT().GetFile("/tmp/repo", "").Return(templateFile).AnyTimes()
+		m.templateDefManager.EXPECT().Render(templateFile, gomock.Any()).Return(tplDef, nil).AnyTimes()
+		versions(m)
+	}
+	step := func(m *localStepwiseMocks, from, to string, rejected bool) {
+		patch := []byte("diff " + from + ".." + to)
+		m.repo.EXPECT().Use(to).Return(to, nil)
+		m.repo.EXPECT().Diff(from, "").Return(patch, nil)
+		m.patchTransform.EXPECT().Transform(patch, tplDef.Patterns).
+			Return(patch, []*entities.FileChange{{Operation: entities.FileModify, File: "/main.go"}}, nil)
+
+		res := &entities.PatchResult{Files: []*entities.PatchFileResult{{File: "/main.go"}}}
+		if rejected {
+			res.Files[0].Hunks = []*entities.PatchHunkResult{{Number: 1, Status: entities.HunkRejected}}
+		}
+		m.patchManager.EXPECT().Apply("/path/to/project", patch).Return(res, nil)
+	}
+	sombraDef := func() *entities.SombraDef {
+		return &entities.SombraDef{
+			Templates: []*entities.TemplateConfig{
+				{URI: "github.com/user/repo", Current: "v1.0.0"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		tag         string
+		dryRun      bool
+		setup       func(m *localStepwiseMocks, def *entities.SombraDef)
+		shouldError bool
+		expected    [][2]entities.Version
+		current     entities.Version
+	}{
+		{
+			name: "every release is applied and stored in turn",
+			setup: func(m *localStepwiseMocks, def *entities.SombraDef) {
+				prepare(m, def)
+				step(m, "v1.0.0", "v1.1.0", false)
+				step(m, "v1.1.0", "v1.2.0", false)
+				step(m, "v1.2.0", "v2.0.0", false)
+				// once per step and once at the end
+				m.sombraDefManager.EXPECT().Save(sombraFile, def).Return(nil).Times(4)
+			},
+			expected: [][2]entities.Version{{"v1.0.0", "v1.1.0"}, {"v1.1.0", "v1.2.0"}, {"v1.2.0", "v2.0.0"}},
+			current:  "v2.0.0",
+		},
+		{
+			name: "explicit tag is the last step",
+			tag:  "v1.2.0",
+			setup: func(m *localStepwiseMocks, def *entities.SombraDef) {
+				prepare(m, def)
+				step(m, "v1.0.0", "v1.1.0", false)
+				step(m, "v1.1.0", "v1.2.0", false)
+				m.sombraDefManager.EXPECT().Save(sombraFile, def).Return(nil).Times(3)
+			},
+			expected: [][2]entities.Version{{"v1.0.0", "v1.1.0"}, {"v1.1.0", "v1.2.0"}},
+			current:  "v1.2.0",
+		},
+		{
+			name: "rejected step keeps the last good version",
+			setup: func(m *localStepwiseMocks, def *entities.SombraDef) {
+				prepare(m, def)
+				step(m, "v1.0.0", "v1.1.0", false)
+				step(m, "v1.1.0", "v1.2.0", true)
+				m.sombraDefManager.EXPECT().Save(sombraFile, def).Return(nil).Times(2)
+			},
+			shouldError: true,
+			expected:    [][2]entities.Version{{"v1.0.0", "v1.1.0"}, {"v1.1.0", "v1.2.0"}},
+			current:     "v1.1.0",
+		},
+		{
+			name:   "dry run reports every step without storing",
+			dryRun: true,
+			setup: func(m *localStepwiseMocks, def *entities.SombraDef) {
+				prepare(m, def)
+				for _, s := range [][2]string{{"v1.0.0", "v1.1.0"}, {"v1.1.0", "v1.2.0"}, {"v1.2.0", "v2.0.0"}} {
+					patch := []byte("diff " + s[0] + ".." + s[1])
+					m.repo.EXPECT().Use(s[1]).Return(s[1], nil)
+					m.repo.EXPECT().Diff(s[0], "").Return(patch, nil)
+					m.patchTransform.EXPECT().Transform(patch, tplDef.Patterns).Return(patch, []*entities.FileChange{}, nil)
+				}
+			},
+			expected: [][2]entities.Version{{"v1.0.0", "v1.1.0"}, {"v1.1.0", "v1.2.0"}, {"v1.2.0", "v2.0.0"}},
+			current:  "v2.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := &localStepwiseMocks{
+				repo:               NewMockRepositoryPort(ctrl),
+				repoPrepare:        NewMockRepositoryPrepareCase(ctrl),
+				patchManager:       NewMockPatchPort(ctrl),
+				templateDefManager: NewMockTemplateDefManagerPort(ctrl),
+				sombraDefManager:   NewMockSombraDefManagerPort(ctrl),
+				versionManager:     NewMockVersionManagerPort(ctrl),
+				patchTransform:     NewMockPatchTransformPort(ctrl),
+			}
+			def := sombraDef()
+			tt.setup(m, def)
+
+			interactor := NewDirectoryLocalDiffInteractor(
+				m.repoPrepare,
+				m.patchManager,
+				m.templateDefManager,
+				m.sombraDefManager,
+				m.versionManager,
+				NewMockDirectoryManagerPort(ctrl),
+				NewMockFileManagerPort(ctrl),
+				m.patchTransform,
+			)
+
+			plans, err := interactor.LocalUpdate("/path/to/project", "github.com/user/repo", LocalUpdateOptions{Tag: tt.tag, DryRun: tt.dryRun, Stepwise: true})
+
+			if (err != nil) != tt.shouldError {
+				t.Errorf("LocalUpdate() error = %v, shouldError = %v", err, tt.shouldError)
+			}
+			if len(plans) != len(tt.expected) {
+				t.Fatalf("Expected %d plans, got %d", len(tt.expected), len(plans))
+			}
+			for i, plan := range plans {
+				if plan.From != tt.expected[i][0] || plan.To != tt.expected[i][1] {
+					t.Errorf("Expected step %s -> %s, got %s -> %s", tt.expected[i][0], tt.expected[i][1], plan.From, plan.To)
+				}
+			}
+			if def.Templates[0].Current != tt.current {
+				t.Errorf("Expected current version %s, got %s", tt.current, def.Templates[0].Current)
+			}
 		})
 	}
 }
+
+func TestDirectoryLocalDiffInteractor_LocalUpdateStepPatterns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sombraFile := entities.File("/path/to/project/sombra.yaml")
+	templateFile := entities.File("/tmp/repo/.sombra/default.yaml")
+	tags := []string{"v1.0.0", "v1.1.0", "v2.0.0"}
+	def := &entities.SombraDef{
+		Templates: []*entities.TemplateConfig{{URI: "github.com/user/repo", Current: "v1.0.0"}},
+	}
+
+	// the mappings of the template change between the releases
+	definitions := map[string]*entities.TemplateDef{
+		"v1.1.0": {Patterns: []*entities.Pattern{{Pattern: "**/*", Default: entities.Mappings{"acme": "{{ .name }}"}}}},
+		"v2.0.0": {Patterns: []*entities.Pattern{{Pattern: "**/*", Default: entities.Mappings{"acme-corp": "{{ .name }}"}}}},
+	}
+
+	m := &localStepwiseMocks{
+		repo:               NewMockRepositoryPort(ctrl),
+		repoPrepare:        NewMockRepositoryPrepareCase(ctrl),
+		patchManager:       NewMockPatchPort(ctrl),
+		templateDefManager: NewMockTemplateDefManagerPort(ctrl),
+		sombraDefManager:   NewMockSombraDefManagerPort(ctrl),
+		versionManager:     NewMockVersionManagerPort(ctrl),
+		patchTransform:     NewMockPatchTransformPort(ctrl),
+	}
+	m.sombraDefManager.EXPECT().GetFile("/path/to/project").Return(sombraFile)
+	m.sombraDefManager.EXPECT().Load(sombraFile).Return(def, nil)
+	m.sombraDefManager.EXPECT().Save(sombraFile, def).Return(nil).Times(3)
+	m.repoPrepare.EXPECT().Prepare("github.com/user/repo", "", nil).Return(m.repo, nil)
+	m.repo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+	m.repo.EXPECT().Clean().Return(nil)
+	m.repo.EXPECT().GetTags().Return(tags, nil)
+	m.versionManager.EXPECT().GetLatest(tags, "*").Return(entities.Version("v2.0.0"), nil)
+	m.versionManager.EXPECT().Compare(gomock.Any(), gomock.Any()).DoAndReturn(func(v1, v2 entities.Version) (int8, error) {
+		switch {
+		case v1 < v2:
+			return -1, nil
+		case v1 > v2:
+			return 1, nil
+		}
+		return 0, nil
+	}).AnyTimes()
+	m.versionManager.EXPECT().GetNext(tags, "*", entities.Version("v1.0.0")).Return(entities.Version("v1.1.0"), nil)
+	m.versionManager.EXPECT().GetNext(tags, "*", entities.Version("v1.1.0")).Return(entities.Version("v2.0.0"), nil)
+
+	// the definition is read from the checkout of the step
+	checkout := ""
+	m.repo.EXPECT().Use(gomock.Any()).DoAndReturn(func(version string) (string, error) {
+		checkout = version
+		return version, nil
+	}).Times(2)
+	m.templateDefManager.EXPECT().GetFile("/tmp/repo", "").Return(templateFile).Times(2)
+	m.templateDefManager.EXPECT().Render(templateFile, gomock.Any()).DoAndReturn(func(fn entities.File, vars entities.Mappings) (*entities.TemplateDef, error) {
+		return definitions[checkout], nil
+	}).Times(2)
+
+	for _, s := range [][2]string{{"v1.0.0", "v1.1.0"}, {"v1.1.0", "v2.0.0"}} {
+		patch := []byte("diff " + s[0] + ".." + s[1])
+		m.repo.EXPECT().Diff(s[0], "").Return(patch, nil)
+		m.patchTransform.EXPECT().Transform(patch, definitions[s[1]].Patterns).Return(patch, []*entities.FileChange{}, nil)
+		m.patchManager.EXPECT().Apply("/path/to/project", patch).Return(&entities.PatchResult{}, nil)
+	}
+
+	interactor := NewDirectoryLocalDiffInteractor(
+		m.repoPrepare,
+		m.patchManager,
+		m.templateDefManager,
+		m.sombraDefManager,
+		m.versionManager,
+		NewMockDirectoryManagerPort(ctrl),
+		NewMockFileManagerPort(ctrl),
+		m.patchTransform,
+	)
+
+	plans, err := interactor.LocalUpdate("/path/to/project", "github.com/user/repo", LocalUpdateOptions{Stepwise: true})
+	if err != nil || len(plans) != 2 {
+		t.Fatalf("LocalUpdate() = %v, %v", plans, err)
+	}
+	if def.Templates[0].Current != "v2.0.0" {
+		t.Errorf("Expected current version v2.0.0, got %s", def.Templates[0].Current)
+	}
+}
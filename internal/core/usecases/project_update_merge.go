@@ -0,0 +1,241 @@
+package usecases
+
+import (
+	"bytes"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"path/filepath"
+)
+
+type LocalMergeInteractor struct {
+	repoPrepare        RepositoryPrepareCase
+	templateDefManager TemplateDefManagerPort
+	sombraDefManager   SombraDefManagerPort
+	versionManager     VersionManagerPort
+	localFiles         FileManagerPort
+	render             TemplateRenderCase
+	merger             MergePort
+}
+
+func NewLocalMergeInteractor(
+	repoPrepare RepositoryPrepareCase,
+	templateDefManager TemplateDefManagerPort,
+	sombraDefManager SombraDefManagerPort,
+	versionManager VersionManagerPort,
+	localFiles FileManagerPort,
+	render TemplateRenderCase,
+	merger MergePort,
+) *LocalMergeInteractor {
+	return &LocalMergeInteractor{
+		repoPrepare:        repoPrepare,
+		templateDefManager: templateDefManager,
+		sombraDefManager:   sombraDefManager,
+		versionManager:     versionManager,
+		localFiles:         localFiles,
+		render:             render,
+		merger:             merger,
+	}
+}
+
+// LocalUpdate merges the template changes between the current and the target version into the local files.
+// The template rendered at the current version is the base, the local files are "ours" and the template
+// rendered at the target version is "theirs".
+func (merge *LocalMergeInteractor) LocalUpdate(target, uri string, opts LocalUpdateOptions) ([]*entities.UpdatePlan, error) {
+	// Read sombra file
+	sombraFile := merge.sombraDefManager.GetFile(target)
+	def, err := merge.sombraDefManager.Load(sombraFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Download and prepare the version, a template in a subdirectory clones the whole repository
+	repoURI, _ := entities.SplitSubdir(uri)
+	repo, err := merge.repoPrepare.Prepare(repoURI, "", templateAuth(def, uri))
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Clean()
+
+	// If the tag variable is empty, each template looks for its version in the tags
+	var version entities.Version
+	var tags []string
+	if opts.Tag == "" {
+		tags, err = repo.GetTags()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Iterate over all templates
+	var sig int8
+	var base, theirs []*entities.RenderedFile
+	plans := make([]*entities.UpdatePlan, 0)
+	for _, template := range def.Templates {
+		if template.URI != uri {
+			continue
+		}
+
+		version, err = targetVersion(merge.versionManager, repo, tags, template, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		base = make([]*entities.RenderedFile, 0)
+		if template.Current != "" {
+			err = checkFixedVersion(template)
+			if err != nil {
+				return nil, err
+			}
+			sig, err = compareVersions(merge.versionManager, repo, template, template.Current, version)
+			if err != nil {
+				return nil, err
+			}
+			if sig >= 0 {
+				continue
+			}
+
+			base, err = merge.renderVersion(repo, template, template.Current)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		theirs, err = merge.renderVersion(repo, template, version)
+		if err != nil {
+			return nil, err
+		}
+
+		plan := &entities.UpdatePlan{
+			URI:    uri,
+			Path:   template.Path,
+			From:   template.Current,
+			To:     version,
+			DryRun: opts.DryRun,
+		}
+		plan.Changes, err = merge.mergeFiles(filepath.Join(target, template.Path), base, theirs, version, opts.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+
+		// Update the template configuration
+		template.Current = version
+	}
+
+	// A dry run only reports the plan, nothing is stored
+	if opts.DryRun {
+		return plans, nil
+	}
+
+	// Store sombra file
+	err = merge.sombraDefManager.Save(sombraFile, def)
+	if err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+// renderVersion renders the whole template at the given version in memory
+func (merge *LocalMergeInteractor) renderVersion(repo RepositoryPort, template *entities.TemplateConfig, version entities.Version) ([]*entities.RenderedFile, error) {
+	_, err := repo.Use(string(version))
+	if err != nil {
+		return nil, err
+	}
+
+	dir := templateDir(repo, template)
+	fn := merge.templateDefManager.GetFile(dir, template.Flavor)
+	tpl, err := merge.templateDefManager.Render(fn, template.Vars)
+	if err != nil {
+		return nil, err
+	}
+	err = validateVars(tpl.Vars, template.Vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return merge.render.RenderTree(dir, tpl, template.Vars)
+}
+
+func (merge *LocalMergeInteractor) mergeFiles(targetDir string, base, theirs []*entities.RenderedFile, version entities.Version, dryRun bool) ([]*entities.FileChange, error) {
+	baseFiles := make(map[entities.File][]byte)
+	for _, file := range base {
+		baseFiles[file.File] = file.Content
+	}
+
+	var change *entities.FileChange
+	var err error
+	changes := make([]*entities.FileChange, 0)
+	seen := make(map[entities.File]bool)
+	for _, file := range theirs {
+		seen[file.File] = true
+		baseContent, inBase := baseFiles[file.File]
+		change, err = merge.mergeFile(targetDir, file, baseContent, inBase, version, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			changes = append(changes, change)
+		}
+	}
+
+	// files removed from the template are only removed when they were not changed locally
+	for _, file := range base {
+		if seen[file.File] || !merge.localFiles.Exists(targetDir, file.File) {
+			continue
+		}
+		ours, err := merge.localFiles.Read(targetDir, file.File)
+		if err != nil {
+			return nil, err
+		}
+		change = &entities.FileChange{Operation: entities.FileDelete, File: file.File}
+		if !bytes.Equal(ours, file.Content) {
+			change.Conflict = true
+		} else if !dryRun {
+			err = merge.localFiles.Remove(targetDir, file.File)
+			if err != nil {
+				return nil, err
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+func (merge *LocalMergeInteractor) mergeFile(targetDir string, file *entities.RenderedFile, base []byte, inBase bool, version entities.Version, dryRun bool) (*entities.FileChange, error) {
+	if !merge.localFiles.Exists(targetDir, file.File) {
+		// a file that existed in the previous version was deleted on purpose
+		if inBase {
+			return nil, nil
+		}
+		return merge.write(targetDir, entities.FileCreate, file.File, file.Content, false, dryRun)
+	}
+
+	ours, err := merge.localFiles.Read(targetDir, file.File)
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing to merge when both sides agree or the template did not change the file
+	if bytes.Equal(ours, file.Content) || (inBase && bytes.Equal(base, file.Content)) {
+		return nil, nil
+	}
+
+	// the local file was not changed, theirs can be taken as is
+	if inBase && bytes.Equal(ours, base) {
+		return merge.write(targetDir, entities.FileModify, file.File, file.Content, false, dryRun)
+	}
+
+	res := merge.merger.Merge(base, ours, file.Content, "local", string(version))
+	return merge.write(targetDir, entities.FileModify, file.File, res.Content, res.Conflicts > 0, dryRun)
+}
+
+func (merge *LocalMergeInteractor) write(targetDir string, operation entities.FileOperation, file entities.File, content []byte, conflict, dryRun bool) (*entities.FileChange, error) {
+	change := &entities.FileChange{Operation: operation, File: file, Conflict: conflict}
+	if dryRun {
+		return change, nil
+	}
+	return change, merge.localFiles.Write(targetDir, file, content)
+}
+
+var _ LocalUpdateCase = (*LocalMergeInteractor)(nil)
@@ -0,0 +1,289 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"go.uber.org/mock/gomock"
+)
+
+type localMergeMocks struct {
+	repo               *MockRepositoryPort
+	repoPrepare        *MockRepositoryPrepareCase
+	templateDefManager *MockTemplateDefManagerPort
+	sombraDefManager   *MockSombraDefManagerPort
+	versionManager     *MockVersionManagerPort
+	localFiles         *MockFileManagerPort
+	render             *MockTemplateRenderCase
+	merger             *MockMergePort
+}
+
+func TestLocalMergeInteractor_LocalUpdate(t *testing.T) {
+	target := "/path/to/project/src"
+	sombraFile := entities.File("/path/to/project/sombra.yaml")
+	templateFile := entities.File("/tmp/repo/.sombra/default.yaml")
+	tplDef := &entities.TemplateDef{
+		Patterns: []*entities.Pattern{
+			{Pattern: "**/*"},
+		},
+	}
+	sombraDef := func(current entities.Version) *entities.SombraDef {
+		return &entities.SombraDef{
+			Templates: []*entities.TemplateConfig{
+				{
+					URI:     "github.com/user/repo",
+					Path:    "src",
+					Current: current,
+					Vars: entities.Mappings{
+						"projectName": "test-project",
+					},
+				},
+			},
+		}
+	}
+	renderAt := func(m *localMergeMocks, version string, files []*entities.RenderedFile) {
+		m.repo.EXPECT().Use(version).Return(version, nil)
+		m.templateDefManager.EXPECT().GetFile("/tmp/repo", "").Return(templateFile)
+		m.templateDefManager.EXPECT().Render(templateFile, gomock.Any()).Return(tplDef, nil)
+		m.render.EXPECT().RenderTree("/tmp/repo", tplDef, gomock.Any()).Return(files, nil)
+	}
+	prepare := func(m *localMergeMocks, def *entities.SombraDef) {
+		m.sombraDefManager.EXPECT().GetFile("/path/to/project").Return(sombraFile)
+		m.sombraDefManager.EXPECT().Load(sombraFile).Return(def, nil)
+		m.repoPrepare.EXPECT().Prepare("github.com/user/repo", "", nil).Return(m.repo, nil)
+		m.repo.EXPECT().Dir().Return("/tmp/repo").AnyTimes()
+		m.repo.EXPECT().Clean().Return(nil)
+	}
+
+	tests := []struct {
+		name        string
+		tag         string
+		dryRun      bool
+		setup       func(m *localMergeMocks)
+		shouldError bool
+		errorMsg    string
+		checkPlans  func(t *testing.T, plans []*entities.UpdatePlan)
+	}{
+		{
+			name: "successful merge of every kind of change",
+			tag:  "v1.0.0",
+			setup: func(m *localMergeMocks) {
+				def := sombraDef("v0.9.0")
+				prepare(m, def)
+				m.versionManager.EXPECT().Compare(entities.Version("v0.9.0"), entities.Version("v1.0.0")).Return(int8(-1), nil)
+
+				renderAt(m, "v0.9.0", []*entities.RenderedFile{
+					{File: "/untouched.go", Content: []byte("base untouched")},
+					{File: "/edited.go", Content: []byte("base edited")},
+					{File: "/same.go", Content: []byte("same")},
+					{File: "/removed.go", Content: []byte("removed")},
+					{File: "/deleted.go", Content: []byte("deleted")},
+				})
+				renderAt(m, "v1.0.0", []*entities.RenderedFile{
+					{File: "/untouched.go", Content: []byte("new untouched")},
+					{File: "/edited.go", Content: []byte("new edited")},
+					{File: "/same.go", Content: []byte("same")},
+					{File: "/deleted.go", Content: []byte("new deleted")},
+					{File: "/added.go", Content: []byte("added")},
+				})
+
+				// local file not changed, the new version is taken as is
+				m.localFiles.EXPECT().Exists(target, entities.File("/untouched.go")).Return(true)
+				m.localFiles.EXPECT().Read(target, entities.File("/untouched.go")).Return([]byte("base untouched"), nil)
+				m.localFiles.EXPECT().Write(target, entities.File("/untouched.go"), []byte("new untouched")).Return(nil)
+
+				// both sides changed the file, the merger combines them
+				m.localFiles.EXPECT().Exists(target, entities.File("/edited.go")).Return(true)
+				m.localFiles.EXPECT().Read(target, entities.File("/edited.go")).Return([]byte("local edited"), nil)
+				m.merger.EXPECT().
+					Merge([]byte("base edited"), []byte("local edited"), []byte("new edited"), "local", "v1.0.0").
+					Return(&entities.MergeResult{Content: []byte("conflict"), Conflicts: 1})
+				m.localFiles.EXPECT().Write(target, entities.File("/edited.go"), []byte("conflict")).Return(nil)
+
+				// unchanged in the template
+				m.localFiles.EXPECT().Exists(target, entities.File("/same.go")).Return(true)
+				m.localFiles.EXPECT().Read(target, entities.File("/same.go")).Return([]byte("local same"), nil)
+
+				// deleted locally, it is not restored
+				m.localFiles.EXPECT().Exists(target, entities.File("/deleted.go")).Return(false)
+
+				// new in the template
+				m.localFiles.EXPECT().Exists(target, entities.File("/added.go")).Return(false)
+				m.localFiles.EXPECT().Write(target, entities.File("/added.go"), []byte("added")).Return(nil)
+
+				// removed from the template and not changed locally
+				m.localFiles.EXPECT().Exists(target, entities.File("/removed.go")).Return(true)
+				m.localFiles.EXPECT().Read(target, entities.File("/removed.go")).Return([]byte("removed"), nil)
+				m.localFiles.EXPECT().Remove(target, entities.File("/removed.go")).Return(nil)
+
+				m.sombraDefManager.EXPECT().Save(sombraFile, def).Return(nil)
+			},
+			checkPlans: func(t *testing.T, plans []*entities.UpdatePlan) {
+				if len(plans) != 1 {
+					t.Fatalf("Expected 1 plan, got %d", len(plans))
+				}
+				if plans[0].From != "v0.9.0" || plans[0].To != "v1.0.0" {
+					t.Errorf("Unexpected versions %s -> %s", plans[0].From, plans[0].To)
+				}
+				expected := []entities.FileChange{
+					{Operation: entities.FileModify, File: "/untouched.go"},
+					{Operation: entities.FileModify, File: "/edited.go", Conflict: true},
+					{Operation: entities.FileCreate, File: "/added.go"},
+					{Operation: entities.FileDelete, File: "/removed.go"},
+				}
+				if len(plans[0].Changes) != len(expected) {
+					t.Fatalf("Expected %d changes, got %d", len(expected), len(plans[0].Changes))
+				}
+				for i, change := range plans[0].Changes {
+					if *change != expected[i] {
+						t.Errorf("Expected change %+v, got %+v", expected[i], *change)
+					}
+				}
+			},
+		},
+		{
+			name:   "dry run does not write nor save",
+			tag:    "v1.0.0",
+			dryRun: true,
+			setup: func(m *localMergeMocks) {
+				prepare(m, sombraDef("v0.9.0"))
+				m.versionManager.EXPECT().Compare(entities.Version("v0.9.0"), entities.Version("v1.0.0")).Return(int8(-1), nil)
+				renderAt(m, "v0.9.0", []*entities.RenderedFile{
+					{File: "/removed.go", Content: []byte("removed")},
+				})
+				renderAt(m, "v1.0.0", []*entities.RenderedFile{
+					{File: "/main.go", Content: []byte("main")},
+				})
+				m.localFiles.EXPECT().Exists(target, entities.File("/main.go")).Return(false)
+				m.localFiles.EXPECT().Exists(target, entities.File("/removed.go")).Return(true)
+				m.localFiles.EXPECT().Read(target, entities.File("/removed.go")).Return([]byte("changed locally"), nil)
+			},
+			checkPlans: func(t *testing.T, plans []*entities.UpdatePlan) {
+				if len(plans) != 1 || !plans[0].DryRun || len(plans[0].Changes) != 2 {
+					t.Fatalf("Unexpected plans %+v", plans)
+				}
+				if !plans[0].Changes[1].Conflict {
+					t.Errorf("Expected a conflict for a locally changed file removed from the template")
+				}
+			},
+		},
+		{
+			name: "template without current version uses an empty base",
+			tag:  "v1.0.0",
+			setup: func(m *localMergeMocks) {
+				def := sombraDef("")
+				prepare(m, def)
+				renderAt(m, "v1.0.0", []*entities.RenderedFile{
+					{File: "/main.go", Content: []byte("template main")},
+				})
+				m.localFiles.EXPECT().Exists(target, entities.File("/main.go")).Return(true)
+				m.localFiles.EXPECT().Read(target, entities.File("/main.go")).Return([]byte("local main"), nil)
+				m.merger.EXPECT().
+					Merge(nil, []byte("local main"), []byte("template main"), "local", "v1.0.0").
+					Return(&entities.MergeResult{Content: []byte("merged")})
+				m.localFiles.EXPECT().Write(target, entities.File("/main.go"), []byte("merged")).Return(nil)
+				m.sombraDefManager.EXPECT().Save(sombraFile, def).Return(nil)
+			},
+			checkPlans: func(t *testing.T, plans []*entities.UpdatePlan) {
+				if len(plans) != 1 || len(plans[0].Changes) != 1 || plans[0].Changes[0].Conflict {
+					t.Fatalf("Unexpected plans %+v", plans)
+				}
+			},
+		},
+		{
+			name: "latest tag is used and up to date templates are skipped",
+			tag:  "",
+			setup: func(m *localMergeMocks) {
+				def := sombraDef("v1.0.0")
+				prepare(m, def)
+				m.repo.EXPECT().GetTags().Return([]string{"v0.9.0", "v1.0.0"}, nil)
+				m.versionManager.EXPECT().GetLatest([]string{"v0.9.0", "v1.0.0"}, "*").Return(entities.Version("v1.0.0"), nil)
+				m.versionManager.EXPECT().Compare(entities.Version("v1.0.0"), entities.Version("v1.0.0")).Return(int8(0), nil)
+				m.sombraDefManager.EXPECT().Save(sombraFile, def).Return(nil)
+			},
+			checkPlans: func(t *testing.T, plans []*entities.UpdatePlan) {
+				if len(plans) != 0 {
+					t.Errorf("Expected no plans, got %d", len(plans))
+				}
+			},
+		},
+		{
+			name: "render failure",
+			tag:  "v1.0.0",
+			setup: func(m *localMergeMocks) {
+				prepare(m, sombraDef("v0.9.0"))
+				m.versionManager.EXPECT().Compare(entities.Version("v0.9.0"), entities.Version("v1.0.0")).Return(int8(-1), nil)
+				m.repo.EXPECT().Use("v0.9.0").Return("", errors.New("unknown revision"))
+			},
+			shouldError: true,
+			errorMsg:    "unknown revision",
+		},
+		{
+			name: "working tree has no base to merge from",
+			tag:  "v1.0.0",
+			setup: func(m *localMergeMocks) {
+				prepare(m, sombraDef(entities.WorkingTree))
+			},
+			shouldError: true,
+			errorMsg:    "github.com/user/repo was applied from its working tree, there is no fixed version to update from, use the copy method instead",
+		},
+		{
+			name: "file write error",
+			tag:  "v1.0.0",
+			setup: func(m *localMergeMocks) {
+				prepare(m, sombraDef(""))
+				renderAt(m, "v1.0.0", []*entities.RenderedFile{
+					{File: "/main.go", Content: []byte("main")},
+				})
+				m.localFiles.EXPECT().Exists(target, entities.File("/main.go")).Return(false)
+				m.localFiles.EXPECT().Write(target, entities.File("/main.go"), []byte("main")).Return(errors.New("permission denied"))
+			},
+			shouldError: true,
+			errorMsg:    "permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := &localMergeMocks{
+				repo:               NewMockRepositoryPort(ctrl),
+				repoPrepare:        NewMockRepositoryPrepareCase(ctrl),
+				templateDefManager: NewMockTemplateDefManagerPort(ctrl),
+				sombraDefManager:   NewMockSombraDefManagerPort(ctrl),
+				versionManager:     NewMockVersionManagerPort(ctrl),
+				localFiles:         NewMockFileManagerPort(ctrl),
+				render:             NewMockTemplateRenderCase(ctrl),
+				merger:             NewMockMergePort(ctrl),
+			}
+			tt.setup(m)
+
+			interactor := NewLocalMergeInteractor(
+				m.repoPrepare,
+				m.templateDefManager,
+				m.sombraDefManager,
+				m.versionManager,
+				m.localFiles,
+				m.render,
+				m.merger,
+			)
+
+			plans, err := interactor.LocalUpdate("/path/to/project", "github.com/user/repo", LocalUpdateOptions{Tag: tt.tag, DryRun: tt.dryRun})
+
+			if (err != nil) != tt.shouldError {
+				t.Errorf("LocalUpdate() error = %v, shouldError = %v", err, tt.shouldError)
+			}
+
+			if tt.shouldError && err != nil && tt.errorMsg != "" && err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message %q, got %q", tt.errorMsg, err.Error())
+			}
+
+			if tt.checkPlans != nil {
+				tt.checkPlans(t, plans)
+			}
+		})
+	}
+}
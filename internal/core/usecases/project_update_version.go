@@ -0,0 +1,114 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"strings"
+)
+
+// targetVersion finds the version a template is updated to.
+// An explicit tag always wins, then the head of the branch the template follows,
+// otherwise the constraint of the template limits the candidates.
+func targetVersion(versionManager VersionManagerPort, repo RepositoryPort, tags []string, template *entities.TemplateConfig, opts LocalUpdateOptions) (entities.Version, error) {
+	if opts.Tag != "" {
+		return entities.Version(opts.Tag), nil
+	}
+
+	// the commit is stored instead of the branch, so the next update knows where it starts from
+	if template.Branch != "" {
+		commit, err := repo.Use(template.Branch)
+		if err != nil {
+			return "", err
+		}
+		return entities.Version(commit), nil
+	}
+
+	// a local directory without history only has its working tree
+	if len(tags) == 1 && entities.Version(tags[0]) == entities.WorkingTree {
+		return entities.WorkingTree, nil
+	}
+
+	constraint := templateConstraint(template)
+	tags = templateTags(tags, template)
+
+	// a template that was never applied has no next version, it starts from the latest one
+	var version entities.Version
+	var err error
+	if opts.ToNext && template.Current != "" {
+		version, err = versionManager.GetNext(tags, constraint, trimTagPrefix(template, template.Current))
+	} else {
+		version, err = versionManager.GetLatest(tags, constraint)
+	}
+	if err != nil {
+		return "", err
+	}
+	return entities.Version(template.TagPrefix) + version, nil
+}
+
+// templateTags keeps the tags with the prefix of the template, without it, so they can be read as versions.
+// The tags of the other templates of a repository are left out.
+func templateTags(tags []string, template *entities.TemplateConfig) []string {
+	if template.TagPrefix == "" {
+		return tags
+	}
+	res := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, template.TagPrefix) {
+			res = append(res, strings.TrimPrefix(tag, template.TagPrefix))
+		}
+	}
+	return res
+}
+
+func trimTagPrefix(template *entities.TemplateConfig, version entities.Version) entities.Version {
+	return entities.Version(strings.TrimPrefix(string(version), template.TagPrefix))
+}
+
+func templateConstraint(template *entities.TemplateConfig) string {
+	if template.Constraint == "" {
+		return "*"
+	}
+	return template.Constraint
+}
+
+// compareVersions compares semver versions, other refs like commits are compared by their git history
+func compareVersions(versionManager VersionManagerPort, repo RepositoryPort, template *entities.TemplateConfig, v1, v2 entities.Version) (int8, error) {
+	// the working tree may change between two updates, it is always applied again
+	if v2 == entities.WorkingTree {
+		return -1, nil
+	}
+
+	sig, err := versionManager.Compare(trimTagPrefix(template, v1), trimTagPrefix(template, v2))
+	if err == nil {
+		return sig, nil
+	}
+	if v1 == v2 {
+		return 0, nil
+	}
+
+	isAncestor, err := repo.IsAncestor(string(v1), string(v2))
+	if err != nil {
+		return 0, err
+	}
+	if isAncestor {
+		return -1, nil
+	}
+	isAncestor, err = repo.IsAncestor(string(v2), string(v1))
+	if err != nil {
+		return 0, err
+	}
+	if isAncestor {
+		return 1, nil
+	}
+
+	// a force-push removed one of the versions from the history, there is no base for a diff or a merge
+	return 0, fmt.Errorf("%s and %s do not share their history, it was rewritten in %s, update with --method copy instead", v1, v2, template.URI)
+}
+
+// checkFixedVersion rejects the working tree as the starting point of an update, its content is gone once it changes
+func checkFixedVersion(template *entities.TemplateConfig) error {
+	if template.Current == entities.WorkingTree {
+		return fmt.Errorf("%s was applied from its working tree, there is no fixed version to update from, use the copy method instead", template.URI)
+	}
+	return nil
+}
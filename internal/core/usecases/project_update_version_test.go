@@ -0,0 +1,273 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTargetVersion(t *testing.T) {
+	tags := []string{"v1.0.0", "v1.1.0", "v2.0.0"}
+
+	tests := []struct {
+		name        string
+		template    *entities.TemplateConfig
+		tags        []string
+		opts        LocalUpdateOptions
+		setup       func(m *MockVersionManagerPort, repo *MockRepositoryPort)
+		expected    entities.Version
+		shouldError bool
+	}{
+		{
+			name:     "explicit tag wins over the constraint",
+			template: &entities.TemplateConfig{Current: "v1.0.0", Constraint: "^1"},
+			opts:     LocalUpdateOptions{Tag: "v2.0.0", ToNext: true},
+			setup:    func(m *MockVersionManagerPort, repo *MockRepositoryPort) {},
+			expected: "v2.0.0",
+		},
+		{
+			name:     "latest version without constraint",
+			template: &entities.TemplateConfig{Current: "v1.0.0"},
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().GetLatest(tags, "*").Return(entities.Version("v2.0.0"), nil)
+			},
+			expected: "v2.0.0",
+		},
+		{
+			name:     "latest version within the constraint",
+			template: &entities.TemplateConfig{Current: "v1.0.0", Constraint: "^1"},
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().GetLatest(tags, "^1").Return(entities.Version("v1.1.0"), nil)
+			},
+			expected: "v1.1.0",
+		},
+		{
+			name:     "next version within the constraint",
+			template: &entities.TemplateConfig{Current: "v1.0.0", Constraint: "^1"},
+			opts:     LocalUpdateOptions{ToNext: true},
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().GetNext(tags, "^1", entities.Version("v1.0.0")).Return(entities.Version("v1.1.0"), nil)
+			},
+			expected: "v1.1.0",
+		},
+		{
+			name:     "next version of a template never applied is the latest",
+			template: &entities.TemplateConfig{},
+			opts:     LocalUpdateOptions{ToNext: true},
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().GetLatest(tags, "*").Return(entities.Version("v2.0.0"), nil)
+			},
+			expected: "v2.0.0",
+		},
+		{
+			name:     "branch head is used instead of the tags",
+			template: &entities.TemplateConfig{Current: "1a2b3c", Branch: "main", Constraint: "^1"},
+			opts:     LocalUpdateOptions{ToNext: true},
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				repo.EXPECT().Use("main").Return("4d5e6f", nil)
+			},
+			expected: "4d5e6f",
+		},
+		{
+			name:     "explicit tag wins over the branch",
+			template: &entities.TemplateConfig{Current: "1a2b3c", Branch: "main"},
+			opts:     LocalUpdateOptions{Tag: "v1.0.0"},
+			setup:    func(m *MockVersionManagerPort, repo *MockRepositoryPort) {},
+			expected: "v1.0.0",
+		},
+		{
+			name:     "missing branch",
+			template: &entities.TemplateConfig{Current: "1a2b3c", Branch: "develop"},
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				repo.EXPECT().Use("develop").Return("", errors.New("pathspec 'develop' did not match"))
+			},
+			shouldError: true,
+		},
+		{
+			name:     "no version matches the constraint",
+			template: &entities.TemplateConfig{Current: "v1.0.0", Constraint: "^3"},
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().GetLatest(tags, "^3").Return(entities.Version(""), errors.New("no version matches the constraint ^3"))
+			},
+			shouldError: true,
+		},
+		{
+			name:     "working tree of a local directory without history",
+			template: &entities.TemplateConfig{},
+			tags:     []string{string(entities.WorkingTree)},
+			setup:    func(m *MockVersionManagerPort, repo *MockRepositoryPort) {},
+			expected: entities.WorkingTree,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			versionManager := NewMockVersionManagerPort(ctrl)
+			repo := NewMockRepositoryPort(ctrl)
+			tt.setup(versionManager, repo)
+
+			candidates := tags
+			if tt.tags != nil {
+				candidates = tt.tags
+			}
+			version, err := targetVersion(versionManager, repo, candidates, tt.template, tt.opts)
+			if (err != nil) != tt.shouldError {
+				t.Fatalf("targetVersion() error = %v, shouldError = %v", err, tt.shouldError)
+			}
+			if version != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, version)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	notSemver := errors.New("invalid semantic version")
+
+	tests := []struct {
+		name        string
+		v1          entities.Version
+		v2          entities.Version
+		setup       func(m *MockVersionManagerPort, repo *MockRepositoryPort)
+		expected    int8
+		shouldError bool
+	}{
+		{
+			name: "semver versions",
+			v1:   "v1.0.0",
+			v2:   "v1.1.0",
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().Compare(entities.Version("v1.0.0"), entities.Version("v1.1.0")).Return(int8(-1), nil)
+			},
+			expected: -1,
+		},
+		{
+			name: "same commit",
+			v1:   "1a2b3c",
+			v2:   "1a2b3c",
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().Compare(gomock.Any(), gomock.Any()).Return(int8(0), notSemver)
+			},
+			expected: 0,
+		},
+		{
+			name: "older commit",
+			v1:   "1a2b3c",
+			v2:   "4d5e6f",
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().Compare(gomock.Any(), gomock.Any()).Return(int8(0), notSemver)
+				repo.EXPECT().IsAncestor("1a2b3c", "4d5e6f").Return(true, nil)
+			},
+			expected: -1,
+		},
+		{
+			name: "newer commit",
+			v1:   "4d5e6f",
+			v2:   "1a2b3c",
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().Compare(gomock.Any(), gomock.Any()).Return(int8(0), notSemver)
+				repo.EXPECT().IsAncestor("4d5e6f", "1a2b3c").Return(false, nil)
+				repo.EXPECT().IsAncestor("1a2b3c", "4d5e6f").Return(true, nil)
+			},
+			expected: 1,
+		},
+		{
+			name: "tag and commit",
+			v1:   "v1.0.0",
+			v2:   "4d5e6f",
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().Compare(gomock.Any(), gomock.Any()).Return(int8(0), notSemver)
+				repo.EXPECT().IsAncestor("v1.0.0", "4d5e6f").Return(true, nil)
+			},
+			expected: -1,
+		},
+		{
+			name: "rewritten history",
+			v1:   "1a2b3c",
+			v2:   "4d5e6f",
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().Compare(gomock.Any(), gomock.Any()).Return(int8(0), notSemver)
+				repo.EXPECT().IsAncestor("1a2b3c", "4d5e6f").Return(false, nil)
+				repo.EXPECT().IsAncestor("4d5e6f", "1a2b3c").Return(false, nil)
+			},
+			shouldError: true,
+		},
+		{
+			name:     "working tree is always newer",
+			v1:       entities.WorkingTree,
+			v2:       entities.WorkingTree,
+			setup:    func(m *MockVersionManagerPort, repo *MockRepositoryPort) {},
+			expected: -1,
+		},
+		{
+			name: "unknown commit",
+			v1:   "1a2b3c",
+			v2:   "4d5e6f",
+			setup: func(m *MockVersionManagerPort, repo *MockRepositoryPort) {
+				m.EXPECT().Compare(gomock.Any(), gomock.Any()).Return(int8(0), notSemver)
+				repo.EXPECT().IsAncestor("1a2b3c", "4d5e6f").Return(false, errors.New("not a valid commit name"))
+			},
+			shouldError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			versionManager := NewMockVersionManagerPort(ctrl)
+			repo := NewMockRepositoryPort(ctrl)
+			tt.setup(versionManager, repo)
+
+			sig, err := compareVersions(versionManager, repo, &entities.TemplateConfig{}, tt.v1, tt.v2)
+			if (err != nil) != tt.shouldError {
+				t.Fatalf("compareVersions() error = %v, shouldError = %v", err, tt.shouldError)
+			}
+			if sig != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, sig)
+			}
+		})
+	}
+}
+
+func TestTagPrefix(t *testing.T) {
+	tags := []string{"go-api/v1.0.0", "python-api/v1.0.0", "python-api/v1.1.0", "v3.0.0"}
+	template := &entities.TemplateConfig{URI: "github.com/org/templates//python-api", Current: "python-api/v1.0.0", TagPrefix: "python-api/"}
+
+	if res := templateTags(tags, template); len(res) != 2 || res[0] != "v1.0.0" || res[1] != "v1.1.0" {
+		t.Errorf("Expected the versions of the template only, got %v", res)
+	}
+	if res := templateTags(tags, &entities.TemplateConfig{}); len(res) != len(tags) {
+		t.Errorf("Expected every tag without prefix, got %v", res)
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	versionManager := NewMockVersionManagerPort(ctrl)
+	repo := NewMockRepositoryPort(ctrl)
+
+	// the versions are read without the prefix, the tags keep it
+	versionManager.EXPECT().GetLatest([]string{"v1.0.0", "v1.1.0"}, "*").Return(entities.Version("v1.1.0"), nil)
+	version, err := targetVersion(versionManager, repo, tags, template, LocalUpdateOptions{})
+	if err != nil || version != "python-api/v1.1.0" {
+		t.Errorf("targetVersion() = %s, %v", version, err)
+	}
+
+	versionManager.EXPECT().GetNext([]string{"v1.0.0", "v1.1.0"}, "*", entities.Version("v1.0.0")).Return(entities.Version("v1.1.0"), nil)
+	version, err = targetVersion(versionManager, repo, tags, template, LocalUpdateOptions{ToNext: true})
+	if err != nil || version != "python-api/v1.1.0" {
+		t.Errorf("targetVersion() = %s, %v", version, err)
+	}
+
+	versionManager.EXPECT().Compare(entities.Version("v1.0.0"), entities.Version("v1.1.0")).Return(int8(-1), nil)
+	sig, err := compareVersions(versionManager, repo, template, "python-api/v1.0.0", "python-api/v1.1.0")
+	if err != nil || sig != -1 {
+		t.Errorf("compareVersions() = %d, %v", sig, err)
+	}
+}
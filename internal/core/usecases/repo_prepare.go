@@ -1,15 +1,26 @@
 package usecases
 
+import (
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"path/filepath"
+)
+
 type RepositoryPrepareCase interface {
-	Prepare(uri, tag string) (RepositoryPort, error)
+	Prepare(uri, tag string, auth *entities.TemplateAuth) (RepositoryPort, error)
 }
 
 type RepositoryPrepareInteractor struct {
-	factory RepositoryFactory
+	factory     RepositoryFactory
+	credentials CredentialsPort
 }
 
-func (l *RepositoryPrepareInteractor) Prepare(uri, tag string) (RepositoryPort, error) {
-	repo, err := l.factory(uri)
+func (l *RepositoryPrepareInteractor) Prepare(uri, tag string, auth *entities.TemplateAuth) (RepositoryPort, error) {
+	credentials, err := l.credentials.Resolve(uri, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := l.factory(uri, credentials)
 	if err != nil {
 		return nil, err
 	}
@@ -31,8 +42,24 @@ func (l *RepositoryPrepareInteractor) Prepare(uri, tag string) (RepositoryPort,
 	return repo, nil
 }
 
-func NewRepositoryPrepareInteractor(factory RepositoryFactory) *RepositoryPrepareInteractor {
-	return &RepositoryPrepareInteractor{factory: factory}
+func NewRepositoryPrepareInteractor(factory RepositoryFactory, credentials CredentialsPort) *RepositoryPrepareInteractor {
+	return &RepositoryPrepareInteractor{factory: factory, credentials: credentials}
+}
+
+// templateAuth returns the auth settings of the template, a URI applied several times shares them
+func templateAuth(def *entities.SombraDef, uri string) *entities.TemplateAuth {
+	for _, template := range def.Templates {
+		if template.URI == uri && template.Auth != nil {
+			return template.Auth
+		}
+	}
+	return nil
+}
+
+// templateDir is the directory of the template in the repository, a repository may hold several templates
+func templateDir(repo RepositoryPort, template *entities.TemplateConfig) string {
+	_, subdir := template.Source()
+	return filepath.Join(repo.Dir(), subdir)
 }
 
 var _ CliTemplateInitCase = (*CliTemplateInitInteractor)(nil)
@@ -12,6 +12,7 @@ package usecases
 import (
 	reflect "reflect"
 
+	entities "github.com/sombrahq/sombra-cli/internal/core/entities"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,16 +41,16 @@ func (m *MockRepositoryPrepareCase) EXPECT() *MockRepositoryPrepareCaseMockRecor
 }
 
 // Prepare mocks base method.
-func (m *MockRepositoryPrepareCase) Prepare(uri, tag string) (RepositoryPort, error) {
+func (m *MockRepositoryPrepareCase) Prepare(uri, tag string, auth *entities.TemplateAuth) (RepositoryPort, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Prepare", uri, tag)
+	ret := m.ctrl.Call(m, "Prepare", uri, tag, auth)
 	ret0, _ := ret[0].(RepositoryPort)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Prepare indicates an expected call of Prepare.
-func (mr *MockRepositoryPrepareCaseMockRecorder) Prepare(uri, tag any) *gomock.Call {
+func (mr *MockRepositoryPrepareCaseMockRecorder) Prepare(uri, tag, auth any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prepare", reflect.TypeOf((*MockRepositoryPrepareCase)(nil).Prepare), uri, tag)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prepare", reflect.TypeOf((*MockRepositoryPrepareCase)(nil).Prepare), uri, tag, auth)
 }
@@ -8,14 +8,19 @@ import (
 
 type SombraStringsPort interface {
 	ProcessString(target string, mapping entities.MapList) string
-	ProcessContent(content []byte, mapping entities.MapList) []byte
+	// ProcessContent applies the mappings to the content of the file, its name sets the word boundaries of the word: mappings
+	ProcessContent(file entities.File, content []byte, mapping entities.MapList) []byte
+	// RenderTemplate renders the content as a Go template, name is used in the errors
+	RenderTemplate(name string, content []byte, delims []string, vars entities.Mappings) ([]byte, error)
 }
 
 type SombraEngineCase interface {
 	Match(file entities.File, mappings []*entities.Pattern) (bool, []*entities.Pattern, error)
+	Skipped(file entities.File, mappings []*entities.Pattern) (bool, error)
 	Combine(patterns []*entities.Pattern) *entities.MapResult
 	NewFile(file entities.File, paths entities.MapList, names entities.MapList) entities.File
-	NewContent(content []byte, mappings entities.MapList) []byte
+	NewContent(file entities.File, content []byte, mappings entities.MapList) []byte
+	RenderContent(file entities.File, content []byte, items *entities.MapResult, vars entities.Mappings) ([]byte, error)
 }
 
 type SombraEngineInteractor struct {
@@ -26,36 +31,24 @@ type SombraEngineInteractor struct {
 }
 
 func (l *SombraEngineInteractor) Match(file entities.File, mappings []*entities.Pattern) (bool, []*entities.Pattern, error) {
-	var match bool
 	include := false
-	var err error
-
 	res := make([]*entities.Pattern, 0)
 
 	for _, value := range mappings {
-		match, err = l.dirManager.PathMatch(file, value.Pattern)
+		match, err := l.matchPattern(file, value)
 		if err != nil {
+			return false, nil, err
 		}
-
 		if !match {
 			continue
 		}
 
-		skip := false
-
-		exceptions := append(value.Except, l.alwaysIgnore...)
-		for _, except := range exceptions {
-			match, err = l.dirManager.PathMatch(file, except)
-			if err != nil {
-				return false, nil, err
-			}
-			if match {
-				skip = true
-				break
-			}
+		// the patterns whose condition is false do not match
+		enabled, err := value.Enabled()
+		if err != nil {
+			return false, nil, err
 		}
-
-		if skip {
+		if !enabled {
 			continue
 		}
 
@@ -68,15 +61,68 @@ func (l *SombraEngineInteractor) Match(file entities.File, mappings []*entities.
 	return include, res, nil
 }
 
+// Skipped tells if the file is left out only because the condition of a pattern matching it is false
+func (l *SombraEngineInteractor) Skipped(file entities.File, mappings []*entities.Pattern) (bool, error) {
+	for _, value := range mappings {
+		if value.Abstract || value.When == nil {
+			continue
+		}
+		match, err := l.matchPattern(file, value)
+		if err != nil {
+			return false, err
+		}
+		if !match {
+			continue
+		}
+		enabled, err := value.Enabled()
+		if err != nil {
+			return false, err
+		}
+		if !enabled {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchPattern checks the wildcard of the pattern and its exceptions
+func (l *SombraEngineInteractor) matchPattern(file entities.File, value *entities.Pattern) (bool, error) {
+	// an invalid wildcard does not match
+	match, _ := l.dirManager.PathMatch(file, value.Pattern)
+	if !match {
+		return false, nil
+	}
+
+	exceptions := append(value.Except, l.alwaysIgnore...)
+	for _, except := range exceptions {
+		match, err := l.dirManager.PathMatch(file, except)
+		if err != nil {
+			return false, err
+		}
+		if match {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func (l *SombraEngineInteractor) Combine(patterns []*entities.Pattern) *entities.MapResult {
 	path := entities.Mappings{}
 	name := entities.Mappings{}
 	content := entities.Mappings{}
 	isVerbatim := false
+	render := false
+	var delims []string
 	for _, value := range patterns {
 		if value.Verbatim {
 			isVerbatim = true
 		}
+		if value.Render {
+			render = true
+		}
+		if len(value.Delims) > 0 {
+			delims = value.Delims
+		}
 
 		// copy path
 		l.updateMapping(path, value.Default)
@@ -97,6 +143,8 @@ func (l *SombraEngineInteractor) Combine(patterns []*entities.Pattern) *entities
 		Path:    l.makeOrderedMap(path),
 		Name:    l.makeOrderedMap(name),
 		Content: l.makeOrderedMap(content),
+		Render:  render,
+		Delims:  delims,
 	}
 
 	return &res
@@ -109,8 +157,20 @@ func (l *SombraEngineInteractor) NewFile(path entities.File, paths entities.MapL
 	return entities.File(filepath.Join(newPath, newName))
 }
 
-func (l *SombraEngineInteractor) NewContent(content []byte, mappings entities.MapList) []byte {
-	return l.stringProcessor.ProcessContent(content, mappings)
+func (l *SombraEngineInteractor) NewContent(file entities.File, content []byte, mappings entities.MapList) []byte {
+	return l.stringProcessor.ProcessContent(file, content, mappings)
+}
+
+// RenderContent renders the Go templates of the file first, then applies the content mappings
+func (l *SombraEngineInteractor) RenderContent(file entities.File, content []byte, items *entities.MapResult, vars entities.Mappings) ([]byte, error) {
+	if items.Render {
+		var err error
+		content, err = l.stringProcessor.RenderTemplate(string(file), content, items.Delims, vars)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return l.NewContent(file, content, items.Content), nil
 }
 
 func (l *SombraEngineInteractor) updateMapping(target entities.Mappings, mapping entities.Mappings) {
@@ -126,8 +186,12 @@ func (l *SombraEngineInteractor) makeOrderedMap(mappings entities.Mappings) enti
 		res = append(res, entities.MapItem{Key: key, Value: value})
 	}
 
-	sort.Slice(res, func(item1, item2 int) bool {
-		return len(res[item1].Key) > len(res[item2].Key)
+	// the map has no order, the keys of the same length are sorted so the matches at the same position always resolve the same way
+	sort.SliceStable(res, func(item1, item2 int) bool {
+		if len(res[item1].Key) != len(res[item2].Key) {
+			return len(res[item1].Key) > len(res[item2].Key)
+		}
+		return res[item1].Key < res[item2].Key
 	})
 
 	return res
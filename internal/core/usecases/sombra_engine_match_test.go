@@ -0,0 +1,112 @@
+package usecases
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSombraEngineInteractor_When(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the wildcards are prefixes in this test
+	dirManager := NewMockDirectoryManagerPort(ctrl)
+	dirManager.EXPECT().PathMatch(gomock.Any(), gomock.Any()).DoAndReturn(func(fn entities.File, pattern entities.Wildcard) (bool, error) {
+		return strings.HasPrefix(string(fn), strings.TrimSuffix(string(pattern), "**")), nil
+	}).AnyTimes()
+	engine := NewSombraEngineInteractor(dirManager, nil, nil)
+
+	on, off, invalid := "true", "false", "<no value>"
+	patterns := []*entities.Pattern{
+		{Pattern: "/**", Abstract: true},
+		{Pattern: "/src/**"},
+		{Pattern: "/docker/**", When: &off},
+		{Pattern: "/ci/**", When: &on},
+	}
+
+	tests := []struct {
+		file    entities.File
+		match   bool
+		skipped bool
+	}{
+		{file: "/src/main.go", match: true},
+		{file: "/docker/Dockerfile", match: false, skipped: true},
+		{file: "/ci/build.yaml", match: true},
+		{file: "/README.md", match: false},
+	}
+	for _, tt := range tests {
+		match, res, err := engine.Match(tt.file, patterns)
+		if err != nil || match != tt.match {
+			t.Errorf("Match(%s) = %v, %v, expected %v", tt.file, match, err, tt.match)
+		}
+		for _, pattern := range res {
+			if pattern.When == &off {
+				t.Errorf("Match(%s) returned the disabled pattern", tt.file)
+			}
+		}
+
+		skipped, err := engine.Skipped(tt.file, patterns)
+		if err != nil || skipped != tt.skipped {
+			t.Errorf("Skipped(%s) = %v, %v, expected %v", tt.file, skipped, err, tt.skipped)
+		}
+	}
+
+	_, _, err := engine.Match("/docker/Dockerfile", []*entities.Pattern{{Pattern: "/docker/**", When: &invalid}})
+	if err == nil {
+		t.Errorf("Expected an error for a condition that is not a boolean")
+	}
+}
+
+func TestSombraEngineInteractor_RenderContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stringProcessor := NewMockSombraStringsPort(ctrl)
+	engine := NewSombraEngineInteractor(nil, nil, stringProcessor)
+	vars := entities.Mappings{"name": "api"}
+
+	// the delimiters of the last pattern are used, verbatim keeps the rendering without the mappings
+	items := engine.Combine([]*entities.Pattern{
+		{Pattern: "/**", Abstract: true, Default: entities.Mappings{"acme": "{{ .name }}"}},
+		{Pattern: "/charts/**", Render: true, Delims: []string{"[[", "]]"}, Verbatim: true},
+	})
+	if !items.Render || len(items.Delims) != 2 || len(items.Content) != 0 {
+		t.Fatalf("Combine() = %+v, expected a verbatim rendered file", items)
+	}
+
+	stringProcessor.EXPECT().
+		RenderTemplate("/charts/values.yaml", []byte("name: [[ .name ]]"), []string{"[[", "]]"}, vars).
+		Return([]byte("name: api"), nil)
+	stringProcessor.EXPECT().
+		ProcessContent(entities.File("/charts/values.yaml"), []byte("name: api"), items.Content).
+		Return([]byte("name: api"))
+
+	res, err := engine.RenderContent("/charts/values.yaml", []byte("name: [[ .name ]]"), items, vars)
+	if err != nil || string(res) != "name: api" {
+		t.Errorf("RenderContent() = %s, %v", res, err)
+	}
+}
+
+func TestSombraEngineInteractor_CombineOrder(t *testing.T) {
+	engine := NewSombraEngineInteractor(nil, nil, nil)
+	patterns := []*entities.Pattern{{
+		Pattern: "/**",
+		Default: entities.Mappings{"word:acme": "x", "re:acm[e]": "y", "acme-corp": "z", "beta": "w"},
+	}}
+	expected := []string{"acme-corp", "re:acm[e]", "word:acme", "beta"}
+
+	// the keys of the same length come from a map, they are sorted every time in the same way
+	for run := 0; run < 50; run++ {
+		items := engine.Combine(patterns)
+		keys := make([]string, 0, len(items.Content))
+		for _, item := range items.Content {
+			keys = append(keys, item.Key)
+		}
+		if strings.Join(keys, " ") != strings.Join(expected, " ") {
+			t.Fatalf("Combine() keys = %v, expected %v", keys, expected)
+		}
+	}
+}
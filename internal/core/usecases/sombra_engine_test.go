@@ -41,17 +41,17 @@ func (m *MockSombraStringsPort) EXPECT() *MockSombraStringsPortMockRecorder {
 }
 
 // ProcessContent mocks base method.
-func (m *MockSombraStringsPort) ProcessContent(content []byte, mapping entities.MapList) []byte {
+func (m *MockSombraStringsPort) ProcessContent(file entities.File, content []byte, mapping entities.MapList) []byte {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessContent", content, mapping)
+	ret := m.ctrl.Call(m, "ProcessContent", file, content, mapping)
 	ret0, _ := ret[0].([]byte)
 	return ret0
 }
 
 // ProcessContent indicates an expected call of ProcessContent.
-func (mr *MockSombraStringsPortMockRecorder) ProcessContent(content, mapping any) *gomock.Call {
+func (mr *MockSombraStringsPortMockRecorder) ProcessContent(file, content, mapping any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessContent", reflect.TypeOf((*MockSombraStringsPort)(nil).ProcessContent), content, mapping)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessContent", reflect.TypeOf((*MockSombraStringsPort)(nil).ProcessContent), file, content, mapping)
 }
 
 // ProcessString mocks base method.
@@ -68,6 +68,21 @@ func (mr *MockSombraStringsPortMockRecorder) ProcessString(target, mapping any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessString", reflect.TypeOf((*MockSombraStringsPort)(nil).ProcessString), target, mapping)
 }
 
+// RenderTemplate mocks base method.
+func (m *MockSombraStringsPort) RenderTemplate(name string, content []byte, delims []string, vars entities.Mappings) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderTemplate", name, content, delims, vars)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderTemplate indicates an expected call of RenderTemplate.
+func (mr *MockSombraStringsPortMockRecorder) RenderTemplate(name, content, delims, vars any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderTemplate", reflect.TypeOf((*MockSombraStringsPort)(nil).RenderTemplate), name, content, delims, vars)
+}
+
 // MockSombraEngineCase is a mock of SombraEngineCase interface.
 type MockSombraEngineCase struct {
 	ctrl     *gomock.Controller
@@ -123,17 +138,17 @@ func (mr *MockSombraEngineCaseMockRecorder) Match(file, mappings any) *gomock.Ca
 }
 
 // NewContent mocks base method.
-func (m *MockSombraEngineCase) NewContent(content []byte, mappings entities.MapList) []byte {
+func (m *MockSombraEngineCase) NewContent(file entities.File, content []byte, mappings entities.MapList) []byte {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NewContent", content, mappings)
+	ret := m.ctrl.Call(m, "NewContent", file, content, mappings)
 	ret0, _ := ret[0].([]byte)
 	return ret0
 }
 
 // NewContent indicates an expected call of NewContent.
-func (mr *MockSombraEngineCaseMockRecorder) NewContent(content, mappings any) *gomock.Call {
+func (mr *MockSombraEngineCaseMockRecorder) NewContent(file, content, mappings any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewContent", reflect.TypeOf((*MockSombraEngineCase)(nil).NewContent), content, mappings)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewContent", reflect.TypeOf((*MockSombraEngineCase)(nil).NewContent), file, content, mappings)
 }
 
 // NewFile mocks base method.
@@ -149,3 +164,33 @@ func (mr *MockSombraEngineCaseMockRecorder) NewFile(file, paths, names any) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewFile", reflect.TypeOf((*MockSombraEngineCase)(nil).NewFile), file, paths, names)
 }
+
+// RenderContent mocks base method.
+func (m *MockSombraEngineCase) RenderContent(file entities.File, content []byte, items *entities.MapResult, vars entities.Mappings) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderContent", file, content, items, vars)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderContent indicates an expected call of RenderContent.
+func (mr *MockSombraEngineCaseMockRecorder) RenderContent(file, content, items, vars any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderContent", reflect.TypeOf((*MockSombraEngineCase)(nil).RenderContent), file, content, items, vars)
+}
+
+// Skipped mocks base method.
+func (m *MockSombraEngineCase) Skipped(file entities.File, mappings []*entities.Pattern) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Skipped", file, mappings)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Skipped indicates an expected call of Skipped.
+func (mr *MockSombraEngineCaseMockRecorder) Skipped(file, mappings any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Skipped", reflect.TypeOf((*MockSombraEngineCase)(nil).Skipped), file, mappings)
+}
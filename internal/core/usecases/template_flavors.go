@@ -0,0 +1,33 @@
+package usecases
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"path/filepath"
+)
+
+type TemplateFlavorsCase interface {
+	ListFlavors(uri, tag string) ([]string, error)
+}
+
+type TemplateFlavorsInteractor struct {
+	repoPrepare        RepositoryPrepareCase
+	templateDefManager TemplateDefManagerPort
+}
+
+// ListFlavors reads the definitions of the template at the version, the default branch is used when the tag is empty
+func (l *TemplateFlavorsInteractor) ListFlavors(uri, tag string) ([]string, error) {
+	repoURI, subdir := entities.SplitSubdir(uri)
+	repo, err := l.repoPrepare.Prepare(repoURI, tag, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Clean()
+
+	return l.templateDefManager.ListFlavors(filepath.Join(repo.Dir(), subdir))
+}
+
+func NewTemplateFlavorsInteractor(repoPrepare RepositoryPrepareCase, templateDefManager TemplateDefManagerPort) *TemplateFlavorsInteractor {
+	return &TemplateFlavorsInteractor{repoPrepare: repoPrepare, templateDefManager: templateDefManager}
+}
+
+var _ TemplateFlavorsCase = (*TemplateFlavorsInteractor)(nil)
@@ -0,0 +1,72 @@
+package usecases
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestTemplateFlavorsInteractor_ListFlavors(t *testing.T) {
+	tests := []struct {
+		name     string
+		uri      string
+		tag      string
+		setUp    func(repoPrepare *MockRepositoryPrepareCase, templateDefManager *MockTemplateDefManagerPort, repo *MockRepositoryPort)
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name: "flavors of the repository",
+			uri:  "github.com/user/repo",
+			tag:  "v1.0.0",
+			setUp: func(repoPrepare *MockRepositoryPrepareCase, templateDefManager *MockTemplateDefManagerPort, repo *MockRepositoryPort) {
+				repoPrepare.EXPECT().Prepare("github.com/user/repo", "v1.0.0", nil).Return(repo, nil)
+				repo.EXPECT().Dir().Return("/tmp/repo")
+				repo.EXPECT().Clean().Return(nil)
+				templateDefManager.EXPECT().ListFlavors("/tmp/repo").Return([]string{"default", "full"}, nil)
+			},
+			expected: []string{"default", "full"},
+		},
+		{
+			name: "flavors of a template in a subdirectory",
+			uri:  "github.com/user/mono//api",
+			setUp: func(repoPrepare *MockRepositoryPrepareCase, templateDefManager *MockTemplateDefManagerPort, repo *MockRepositoryPort) {
+				repoPrepare.EXPECT().Prepare("github.com/user/mono", "", nil).Return(repo, nil)
+				repo.EXPECT().Dir().Return("/tmp/repo")
+				repo.EXPECT().Clean().Return(nil)
+				templateDefManager.EXPECT().ListFlavors("/tmp/repo/api").Return([]string{"minimal"}, nil)
+			},
+			expected: []string{"minimal"},
+		},
+		{
+			name: "repository preparation failure",
+			uri:  "github.com/user/repo",
+			setUp: func(repoPrepare *MockRepositoryPrepareCase, templateDefManager *MockTemplateDefManagerPort, repo *MockRepositoryPort) {
+				repoPrepare.EXPECT().Prepare("github.com/user/repo", "", nil).Return(nil, errors.New("clone failed"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repoPrepare := NewMockRepositoryPrepareCase(ctrl)
+			templateDefManager := NewMockTemplateDefManagerPort(ctrl)
+			repo := NewMockRepositoryPort(ctrl)
+			tt.setUp(repoPrepare, templateDefManager, repo)
+
+			res, err := NewTemplateFlavorsInteractor(repoPrepare, templateDefManager).ListFlavors(tt.uri, tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListFlavors() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(res, tt.expected) {
+				t.Errorf("ListFlavors() = %v, expected %v", res, tt.expected)
+			}
+		})
+	}
+}
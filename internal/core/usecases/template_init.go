@@ -58,7 +58,7 @@ func (l *DirectoryTemplateInitInteractor) TemplateInit(templateDir string, only
 		return err
 	}
 
-	templateDefFile := l.templateDef.GetFile(templateDir)
+	templateDefFile := l.templateDef.GetFile(templateDir, "")
 	err = l.templateDef.Save(templateDefFile, template)
 	if err != nil {
 		return err
@@ -74,10 +74,15 @@ func (l *DirectoryTemplateInitInteractor) buildTemplate(strings []string, files
 		combinedPatterns = append(combinedPatterns, filePattern)
 	}
 
+	vars := make([]*entities.Var, 0)
+	for _, name := range l.deduplicateVars(strings) {
+		vars = append(vars, &entities.Var{Name: name})
+	}
+
 	// Initialize the template definition
 	template := &entities.TemplateDef{
-		Vars:     l.deduplicateVars(strings), // The variables derived from the process
-		Patterns: combinedPatterns,           // Consolidate global and file-specific patterns
+		Vars:     vars,             // The variables derived from the process
+		Patterns: combinedPatterns, // Consolidate global and file-specific patterns
 	}
 	return template, nil
 }
@@ -131,7 +136,8 @@ func (l *DirectoryTemplateInitInteractor) unifyAbstractMappings(candidates []*en
 		if _, exists := mappings[candidate.For]; !exists {
 			mappings[candidate.For] = make(entities.Mappings)
 		}
-		mappings[candidate.For][candidate.Key] = candidate.Value
+		// the detected values are whole words, the name api must not rewrite rapid or capital
+		mappings[candidate.For]["word:"+candidate.Key] = candidate.Value
 	}
 
 	return []*entities.Pattern{
@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+type TemplateRenderCase interface {
+	RenderTree(templateDir string, tpl *entities.TemplateDef, vars entities.Mappings) ([]*entities.RenderedFile, error)
+}
+
+type TemplateRenderInteractor struct {
+	scanner    DirectoryManagerPort
+	localFiles FileManagerPort
+	engine     SombraEngineCase
+}
+
+// RenderTree renders every file of the template in memory, following the same rules used by the copy method
+func (l *TemplateRenderInteractor) RenderTree(templateDir string, tpl *entities.TemplateDef, vars entities.Mappings) ([]*entities.RenderedFile, error) {
+	tree := l.scanner.ScanTree(templateDir, []entities.Wildcard{"**/*"}, nil)
+	res := make([]*entities.RenderedFile, 0)
+	for result := range tree {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		if result.IsDir {
+			continue
+		}
+
+		match, patterns, err := l.engine.Match(result.File, tpl.Patterns)
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			continue
+		}
+
+		items := l.engine.Combine(patterns)
+		content, err := l.localFiles.Read(templateDir, result.File)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err = l.engine.RenderContent(result.File, content, items, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, &entities.RenderedFile{
+			Source:  result.File,
+			File:    l.engine.NewFile(result.File, items.Path, items.Name),
+			Content: content,
+		})
+	}
+	return res, nil
+}
+
+func NewTemplateRenderInteractor(scanner DirectoryManagerPort, localFiles FileManagerPort, engine SombraEngineCase) *TemplateRenderInteractor {
+	return &TemplateRenderInteractor{
+		scanner:    scanner,
+		localFiles: localFiles,
+		engine:     engine,
+	}
+}
+
+var _ TemplateRenderCase = (*TemplateRenderInteractor)(nil)
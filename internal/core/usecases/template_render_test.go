@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/usecases/template_render.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/usecases/template_render.go -destination=internal/core/usecases/template_render_test.go -package=usecases
+//
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	reflect "reflect"
+
+	entities "github.com/sombrahq/sombra-cli/internal/core/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTemplateRenderCase is a mock of TemplateRenderCase interface.
+type MockTemplateRenderCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockTemplateRenderCaseMockRecorder
+	isgomock struct{}
+}
+
+// MockTemplateRenderCaseMockRecorder is the mock recorder for MockTemplateRenderCase.
+type MockTemplateRenderCaseMockRecorder struct {
+	mock *MockTemplateRenderCase
+}
+
+// NewMockTemplateRenderCase creates a new mock instance.
+func NewMockTemplateRenderCase(ctrl *gomock.Controller) *MockTemplateRenderCase {
+	mock := &MockTemplateRenderCase{ctrl: ctrl}
+	mock.recorder = &MockTemplateRenderCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTemplateRenderCase) EXPECT() *MockTemplateRenderCaseMockRecorder {
+	return m.recorder
+}
+
+// RenderTree mocks base method.
+func (m *MockTemplateRenderCase) RenderTree(templateDir string, tpl *entities.TemplateDef, vars entities.Mappings) ([]*entities.RenderedFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderTree", templateDir, tpl, vars)
+	ret0, _ := ret[0].([]*entities.RenderedFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderTree indicates an expected call of RenderTree.
+func (mr *MockTemplateRenderCaseMockRecorder) RenderTree(templateDir, tpl, vars any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderTree", reflect.TypeOf((*MockTemplateRenderCase)(nil).RenderTree), templateDir, tpl, vars)
+}
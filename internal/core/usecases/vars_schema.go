@@ -0,0 +1,94 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"regexp"
+	"strings"
+)
+
+var intValue = regexp.MustCompile(`^[-+]?[0-9]+$`)
+
+// validateVar checks the value against the schema of the variable
+func validateVar(v *entities.Var, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%s requires a value", v.Name)
+	}
+
+	items := []string{value}
+	switch v.Type {
+	case "", entities.VarString:
+	case entities.VarBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false, got %q", v.Name, value)
+		}
+	case entities.VarInt:
+		if !intValue.MatchString(value) {
+			return fmt.Errorf("%s must be an integer, got %q", v.Name, value)
+		}
+	case entities.VarChoice:
+		if len(v.Choices) == 0 {
+			return fmt.Errorf("%s is a choice without choices", v.Name)
+		}
+	case entities.VarList:
+		items = strings.Split(value, ",")
+		for i, item := range items {
+			items[i] = strings.TrimSpace(item)
+			if items[i] == "" {
+				return fmt.Errorf("%s has an empty item in %q", v.Name, value)
+			}
+		}
+	default:
+		return fmt.Errorf("%s has an unknown type %s", v.Name, v.Type)
+	}
+
+	for _, item := range items {
+		if len(v.Choices) > 0 && !containsString(v.Choices, item) {
+			return fmt.Errorf("%s must be one of %s, got %q", v.Name, strings.Join(v.Choices, ", "), item)
+		}
+		if v.Regex != "" {
+			match, err := regexp.MatchString(v.Regex, item)
+			if err != nil {
+				return fmt.Errorf("%s has an invalid regex: %w", v.Name, err)
+			}
+			if !match {
+				return fmt.Errorf("%s must match %s, got %q", v.Name, v.Regex, item)
+			}
+		}
+	}
+	return nil
+}
+
+// validateVars checks the variables stored in sombra.yaml, the ones declared by their name alone take any value.
+// Every invalid variable is reported at once.
+func validateVars(vars []*entities.Var, values entities.Mappings) error {
+	var failures []string
+	for _, v := range vars {
+		if !v.HasSchema() {
+			continue
+		}
+		value, ok := values[v.Name]
+		if !ok {
+			if v.Default == "" {
+				failures = append(failures, fmt.Sprintf("%s is not set", v.Name))
+			}
+			continue
+		}
+		if err := validateVar(v, value); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("invalid variables in sombra.yaml: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package usecases
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+func TestValidateVar(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       *entities.Var
+		value   string
+		wantErr string
+	}{
+		{name: "any value", v: &entities.Var{Name: "name"}, value: "api"},
+		{name: "empty value", v: &entities.Var{Name: "name"}, value: " ", wantErr: "name requires a value"},
+		{name: "bool", v: &entities.Var{Name: "docker", Type: entities.VarBool}, value: "true"},
+		{name: "invalid bool", v: &entities.Var{Name: "docker", Type: entities.VarBool}, value: "yes", wantErr: "docker must be true or false"},
+		{name: "int", v: &entities.Var{Name: "port", Type: entities.VarInt}, value: "8080"},
+		{name: "invalid int", v: &entities.Var{Name: "port", Type: entities.VarInt}, value: "80a", wantErr: "port must be an integer"},
+		{name: "choice", v: &entities.Var{Name: "db", Type: entities.VarChoice, Choices: []string{"postgres", "mysql"}}, value: "mysql"},
+		{name: "invalid choice", v: &entities.Var{Name: "db", Type: entities.VarChoice, Choices: []string{"postgres", "mysql"}}, value: "oracle", wantErr: "db must be one of postgres, mysql"},
+		{name: "choice without choices", v: &entities.Var{Name: "db", Type: entities.VarChoice}, value: "mysql", wantErr: "db is a choice without choices"},
+		{name: "list", v: &entities.Var{Name: "apps", Type: entities.VarList, Regex: "^[a-z]+$"}, value: "users, orders"},
+		{name: "list with an invalid item", v: &entities.Var{Name: "apps", Type: entities.VarList, Regex: "^[a-z]+$"}, value: "users,Orders", wantErr: `apps must match ^[a-z]+$, got "Orders"`},
+		{name: "list with an empty item", v: &entities.Var{Name: "apps", Type: entities.VarList}, value: "users,,orders", wantErr: "apps has an empty item"},
+		{name: "regex", v: &entities.Var{Name: "name", Regex: "^[a-z-]+$"}, value: "my-api"},
+		{name: "invalid regex", v: &entities.Var{Name: "name", Regex: "^[a-z"}, value: "api", wantErr: "name has an invalid regex"},
+		{name: "unknown type", v: &entities.Var{Name: "name", Type: "float"}, value: "1.0", wantErr: "name has an unknown type float"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVar(tt.v, tt.value)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validateVar() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateVar() error = %v, expected %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateVars(t *testing.T) {
+	vars := []*entities.Var{
+		{Name: "name"},
+		{Name: "port", Type: entities.VarInt},
+		{Name: "db", Type: entities.VarChoice, Choices: []string{"postgres"}},
+		{Name: "owner", Type: entities.VarString, Default: "acme"},
+	}
+
+	err := validateVars(vars, entities.Mappings{"name": "", "port": "8080", "db": "postgres"})
+	if err != nil {
+		t.Errorf("validateVars() error = %v", err)
+	}
+
+	err = validateVars(vars, entities.Mappings{"port": "http"})
+	expected := `invalid variables in sombra.yaml: port must be an integer, got "http"; db is not set`
+	if err == nil || err.Error() != expected {
+		t.Errorf("validateVars() error = %v, expected %q", err, expected)
+	}
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultUsername goes with the tokens, GitHub and Gitea accept any user name with a token but GitHub apps need this one
+const defaultUsername = "x-access-token"
+
+// Rule sets the credentials of the templates matching a URI prefix, in the global configuration
+type Rule struct {
+	Match                 string `yaml:"match"`
+	entities.TemplateAuth `yaml:",inline"`
+}
+
+// Config is the global configuration of sombra, in $XDG_CONFIG_HOME/sombra/config.yaml
+type Config struct {
+	Auth []*Rule `yaml:"auth"`
+}
+
+// Service reads the credentials set in sombra.yaml or in the global configuration.
+// The secrets themselves are read from the environment, the ssh keys or the netrc files.
+type Service struct {
+	rules []*Rule
+}
+
+// NewService reads the global configuration, a missing file has no rules
+func NewService() (*Service, error) {
+	fn, err := configFile()
+	if err != nil {
+		logger.Error("Failed to find the config directory", err)
+		return nil, err
+	}
+	return Load(fn)
+}
+
+func Load(fn string) (*Service, error) {
+	data, err := os.ReadFile(fn)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Service{}, nil
+	}
+	if err != nil {
+		logger.Error("Failed to read config file "+fn, err)
+		return nil, err
+	}
+
+	var conf Config
+	err = yaml.Unmarshal(data, &conf)
+	if err != nil {
+		logger.Error("Failed to parse config file "+fn, err)
+		return nil, err
+	}
+	for _, rule := range conf.Auth {
+		if rule.Match == "" {
+			err = fmt.Errorf("%s has an auth rule without match", fn)
+			logger.Error("Failed to parse config file "+fn, err)
+			return nil, err
+		}
+	}
+	logger.Info(fmt.Sprintf("Loaded %d auth rules from %s", len(conf.Auth), fn))
+	return &Service{rules: conf.Auth}, nil
+}
+
+func configFile() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		var err error
+		base, err = os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(base, "sombra", "config.yaml"), nil
+}
+
+// Resolve reads the credentials of the template, the auth of sombra.yaml wins over the global configuration
+func (s *Service) Resolve(uri string, auth *entities.TemplateAuth) (*entities.Credentials, error) {
+	if auth == nil {
+		auth = s.match(uri)
+	}
+	if auth == nil {
+		logger.Info("No credentials set for " + uri)
+		return nil, nil
+	}
+
+	credentials := &entities.Credentials{}
+	if auth.SSHKey != "" {
+		key, err := expand(auth.SSHKey)
+		if err == nil {
+			_, err = os.Stat(key)
+		}
+		if err != nil {
+			err = fmt.Errorf("the ssh key of %s cannot be read: %w", uri, err)
+			logger.Error("Failed to read credentials", err)
+			return nil, err
+		}
+		credentials.SSHKey = key
+	}
+
+	switch {
+	case auth.TokenEnv != "":
+		token := os.Getenv(auth.TokenEnv)
+		if token == "" {
+			err := fmt.Errorf("%s is not set, it holds the token of %s", auth.TokenEnv, uri)
+			logger.Error("Failed to read credentials", err)
+			return nil, err
+		}
+		credentials.Username = auth.Username
+		if credentials.Username == "" {
+			credentials.Username = defaultUsername
+		}
+		credentials.Password = token
+	case auth.Netrc != "":
+		fn, err := expand(auth.Netrc)
+		if err != nil {
+			logger.Error("Failed to read credentials", err)
+			return nil, err
+		}
+		login, err := readNetrc(fn, host(uri))
+		if err != nil {
+			err = fmt.Errorf("the netrc file of %s cannot be used: %w", uri, err)
+			logger.Error("Failed to read credentials", err)
+			return nil, err
+		}
+		credentials.Username = login.login
+		credentials.Password = login.password
+		if auth.Username != "" {
+			credentials.Username = auth.Username
+		}
+	}
+	logger.Info("Using credentials for " + uri)
+	return credentials, nil
+}
+
+// match returns the rule with the longest prefix of the URI, both are normalised so the scheme does not matter
+func (s *Service) match(uri string) *entities.TemplateAuth {
+	normalised := entities.NormaliseURI(uri)
+	var best *Rule
+	for _, rule := range s.rules {
+		prefix := entities.NormaliseURI(rule.Match)
+		if normalised != prefix && !strings.HasPrefix(normalised, strings.TrimSuffix(prefix, "/")+"/") {
+			continue
+		}
+		if best == nil || len(prefix) > len(entities.NormaliseURI(best.Match)) {
+			best = rule
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	logger.Info(fmt.Sprintf("Using auth rule %s for %s", best.Match, uri))
+	return &best.TemplateAuth
+}
+
+// host is the machine looked for in the netrc files
+func host(uri string) string {
+	res, _, _ := strings.Cut(entities.NormaliseURI(uri), "/")
+	return res
+}
+
+// expand replaces the ~ of the home directory, the paths of sombra.yaml are shared between users
+func expand(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+var _ usecases.CredentialsPort = (*Service)(nil)
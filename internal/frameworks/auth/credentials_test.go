@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+func TestService_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	key := filepath.Join(dir, "id_ed25519")
+	netrc := filepath.Join(dir, "netrc")
+	config := filepath.Join(dir, "config.yaml")
+	files := map[string]string{
+		key: "key\n",
+		netrc: `machine gitlab.example.com
+  login deploy
+  password secret
+default login anonymous password guest
+`,
+		config: `auth:
+  - match: github.com
+    token_env: SOMBRA_TEST_TOKEN
+  - match: https://github.com/acme/
+    ssh_key: ` + key + `
+  - match: gitlab.example.com
+    netrc: ` + netrc + `
+`,
+	}
+	for fn, content := range files {
+		if err := os.WriteFile(fn, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("SOMBRA_TEST_TOKEN", "token")
+
+	service, err := Load(config)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		uri      string
+		auth     *entities.TemplateAuth
+		expected *entities.Credentials
+		wantErr  bool
+	}{
+		{
+			name:     "no credentials",
+			uri:      "https://bitbucket.org/acme/template",
+			expected: nil,
+		},
+		{
+			name:     "token of the host",
+			uri:      "https://github.com/other/template.git",
+			expected: &entities.Credentials{Username: defaultUsername, Password: "token"},
+		},
+		{
+			name:     "longest prefix wins",
+			uri:      "git@github.com:acme/template.git",
+			expected: &entities.Credentials{SSHKey: key},
+		},
+		{
+			name:     "prefix matches whole path segments",
+			uri:      "https://github.com/acme-corp/template",
+			expected: &entities.Credentials{Username: defaultUsername, Password: "token"},
+		},
+		{
+			name:     "netrc machine",
+			uri:      "https://gitlab.example.com/group/template",
+			expected: &entities.Credentials{Username: "deploy", Password: "secret"},
+		},
+		{
+			name:     "auth of sombra.yaml wins",
+			uri:      "https://github.com/acme/template",
+			auth:     &entities.TemplateAuth{TokenEnv: "SOMBRA_TEST_TOKEN", Username: "oauth2"},
+			expected: &entities.Credentials{Username: "oauth2", Password: "token"},
+		},
+		{
+			name:     "netrc default entry",
+			uri:      "https://git.example.org/template",
+			auth:     &entities.TemplateAuth{Netrc: netrc},
+			expected: &entities.Credentials{Username: "anonymous", Password: "guest"},
+		},
+		{
+			name:    "token not set",
+			uri:     "https://github.com/acme/template",
+			auth:    &entities.TemplateAuth{TokenEnv: "SOMBRA_TEST_MISSING"},
+			wantErr: true,
+		},
+		{
+			name:    "missing ssh key",
+			uri:     "git@github.com:acme/template.git",
+			auth:    &entities.TemplateAuth{SSHKey: filepath.Join(dir, "missing")},
+			wantErr: true,
+		},
+		{
+			name:    "missing netrc",
+			uri:     "https://gitlab.example.com/group/template",
+			auth:    &entities.TemplateAuth{Netrc: filepath.Join(dir, "missing")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := service.Resolve(tt.uri, tt.auth)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(res, tt.expected) {
+				t.Errorf("Resolve() = %v, expected %v", res, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	service, err := Load(filepath.Join(dir, "missing.yaml"))
+	if err != nil || len(service.rules) != 0 {
+		t.Errorf("Expected no rules without a config file, got %v, %v", service, err)
+	}
+
+	fn := filepath.Join(dir, "config.yaml")
+	if err = os.WriteFile(fn, []byte("auth:\n  - token_env: TOKEN\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Load(fn); err == nil {
+		t.Errorf("Expected an error with a rule without match")
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type netrcLogin struct {
+	login    string
+	password string
+}
+
+// readNetrc returns the login of the machine, or the default one when the machine is not listed
+func readNetrc(fn, machine string) (*netrcLogin, error) {
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	var found, fallback *netrcLogin
+	var current *netrcLogin
+	tokens := strings.Fields(string(data))
+	for i := 0; i < len(tokens); i++ {
+		next := func() string {
+			if i+1 < len(tokens) {
+				i++
+				return tokens[i]
+			}
+			return ""
+		}
+		switch tokens[i] {
+		case "machine":
+			current = &netrcLogin{}
+			if next() == machine && found == nil {
+				found = current
+			}
+		case "default":
+			current = &netrcLogin{}
+			if fallback == nil {
+				fallback = current
+			}
+		case "login":
+			value := next()
+			if current != nil {
+				current.login = value
+			}
+		case "password":
+			value := next()
+			if current != nil {
+				current.password = value
+			}
+		case "account":
+			next()
+		case "macdef":
+			// macros are not supported, they end the entries
+			i = len(tokens)
+		}
+	}
+
+	if found == nil {
+		found = fallback
+	}
+	if found == nil || found.password == "" {
+		return nil, fmt.Errorf("%s has no login for %s", fn, machine)
+	}
+	return found, nil
+}
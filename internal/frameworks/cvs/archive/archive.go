@@ -0,0 +1,342 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const scheme = "file://"
+
+// emptyTree is the git empty tree, the diff of the first update starts from it
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+var numbers = regexp.MustCompile(`\d+`)
+
+var extensions = []string{".tar.gz", ".tgz", ".zip"}
+
+// versioned matches archive names like template-v1.2.0.tar.gz
+var versioned = regexp.MustCompile(`^.+?-(v?\d+(?:\.\d+)*(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)(?:\.tar\.gz|\.tgz|\.zip)$`)
+
+// Service reads the versions of a template from archives, a single one or a directory of versioned ones
+type Service struct {
+	source string
+	path   string
+	// archives maps each version to its archive file
+	archives map[string]string
+	versions []string
+	// version is the one in use, it is extracted in its own directory
+	version string
+}
+
+// IsArchive tells if the URI is an archive file, or a directory of versioned archives that is not a template itself
+func IsArchive(uri string) bool {
+	source := strings.TrimPrefix(uri, scheme)
+	if strings.Contains(source, "://") {
+		return false
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return false
+	}
+	if !info.IsDir() {
+		return isArchiveName(info.Name())
+	}
+	if _, err = os.Stat(filepath.Join(source, ".sombra")); err == nil {
+		return false
+	}
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && versioned.MatchString(entry.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Factory reads the archives from the filesystem, they need no credentials
+func Factory(uri string, _ *entities.Credentials) (usecases.RepositoryPort, error) {
+	source, err := filepath.Abs(strings.TrimPrefix(uri, scheme))
+	if err != nil {
+		logger.Error("Failed to resolve archive "+uri, err)
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "sombra-archive-")
+	if err != nil {
+		logger.Error("Failed to create temp directory", err)
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Created archive repository service for: %s", source))
+	return &Service{source: source, path: tmp, archives: make(map[string]string)}, nil
+}
+
+// Clone lists the available versions, each one is only extracted when it is used
+func (s *Service) Clone() error {
+	info, err := os.Stat(s.source)
+	if err != nil {
+		logger.Error("Failed to open template archives", err)
+		return err
+	}
+
+	files := []string{s.source}
+	if info.IsDir() {
+		entries, err := os.ReadDir(s.source)
+		if err != nil {
+			logger.Error("Failed to list template archives", err)
+			return err
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() && isArchiveName(entry.Name()) {
+				files = append(files, filepath.Join(s.source, entry.Name()))
+			}
+		}
+	}
+
+	for _, file := range files {
+		version := versionOf(filepath.Base(file))
+		if version == "" {
+			// a single archive without version in its name still has one version
+			if info.IsDir() {
+				logger.Info("Ignoring archive without version: " + file)
+				continue
+			}
+			version = trimExtension(filepath.Base(file))
+		}
+		if other, ok := s.archives[version]; ok {
+			err = fmt.Errorf("version %s found in %s and %s", version, other, file)
+			logger.Error("Failed to list template archives", err)
+			return err
+		}
+		s.archives[version] = file
+		s.versions = append(s.versions, version)
+	}
+	if len(s.versions) == 0 {
+		err = fmt.Errorf("no template archives found in %s", s.source)
+		logger.Error("Failed to list template archives", err)
+		return err
+	}
+	sort.Slice(s.versions, func(i, j int) bool {
+		return olderThan(s.versions[i], s.versions[j])
+	})
+
+	// the latest archive is used until another version is requested
+	s.version = s.versions[len(s.versions)-1]
+	err = s.extract(s.version)
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("Found template archives %v in %s", s.versions, s.source))
+	return nil
+}
+
+func (s *Service) Clean() error {
+	err := os.RemoveAll(s.path)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to remove directory %s", s.path), err)
+		return err
+	}
+	logger.Info(fmt.Sprintf("Removed temporary directory: %s", s.path))
+	return nil
+}
+
+func (s *Service) Dir() string {
+	return s.versionDir(s.version)
+}
+
+func (s *Service) Use(version string) (string, error) {
+	err := s.extract(version)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to use version %s", version), err)
+		return "", err
+	}
+	s.version = version
+	logger.Info(fmt.Sprintf("Using archived version: %s", version))
+	return version, nil
+}
+
+// Diff compares two extracted versions with git, the archives have no history to read it from
+func (s *Service) Diff(commit, subdir string) ([]byte, error) {
+	current := s.version
+
+	// both versions are extracted as a and b, so the paths of the diff are the usual a/ and b/ ones
+	work := filepath.Join(s.path, "diff")
+	err := os.RemoveAll(work)
+	if err == nil {
+		err = s.extractSubdir(current, subdir, filepath.Join(work, "b"))
+	}
+	if err == nil && commit == emptyTree {
+		err = os.MkdirAll(filepath.Join(work, "a"), 0755)
+	} else if err == nil {
+		err = s.extractSubdir(commit, subdir, filepath.Join(work, "a"))
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to extract versions %s and %s", commit, current), err)
+		return nil, err
+	}
+	defer os.RemoveAll(work)
+
+	cmd := exec.Command("git", "diff", "--no-index", "--no-prefix", "--diff-algorithm=histogram", "--patch", "--unified=10", "a", "b")
+	cmd.Dir = work
+	cmd.Stderr = os.Stderr
+	diff, err := cmd.Output()
+
+	// the command exits with 1 when the versions are different
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		err = nil
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get diff for version %s", commit), err)
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Generated diff between versions %s and %s", commit, current))
+	return diff, nil
+}
+
+func (s *Service) GetTags() ([]string, error) {
+	tags := append([]string{}, s.versions...)
+	logger.Info(fmt.Sprintf("Retrieved tags: %v", tags))
+	return tags, nil
+}
+
+// IsAncestor only knows about equal versions, archived versions are compared by their semantic version
+func (s *Service) IsAncestor(ancestor, commit string) (bool, error) {
+	if ancestor == commit {
+		return true, nil
+	}
+	err := fmt.Errorf("archived versions %s and %s have no history, use semantic versions in the archive names", ancestor, commit)
+	logger.Error("Failed to compare archived versions", err)
+	return false, err
+}
+
+func (s *Service) versionDir(version string) string {
+	return filepath.Join(s.path, "versions", strings.ReplaceAll(version, string(filepath.Separator), "_"))
+}
+
+// extract unpacks the version once
+func (s *Service) extract(version string) error {
+	dir := s.versionDir(version)
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	return s.extractTo(version, dir)
+}
+
+func (s *Service) extractTo(version, dir string) error {
+	file, ok := s.archives[version]
+	if !ok {
+		return fmt.Errorf("version %s not found in %s", version, s.source)
+	}
+	err := unpack(file, dir)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return err
+	}
+
+	// the template must be at the root of the directory
+	root, err := rootOf(dir)
+	if err != nil || root == dir {
+		return err
+	}
+	tmp := dir + ".root"
+	if err = os.Rename(root, tmp); err != nil {
+		return err
+	}
+	if err = os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir)
+}
+
+// extractSubdir extracts the subdirectory of the version only, it is empty when the version does not have it
+func (s *Service) extractSubdir(version, subdir, dir string) error {
+	if subdir == "" {
+		return s.extractTo(version, dir)
+	}
+	full := dir + ".full"
+	err := s.extractTo(version, full)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(full)
+
+	err = os.Rename(filepath.Join(full, subdir), dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.MkdirAll(dir, 0755)
+	}
+	return err
+}
+
+// rootOf skips the top directory archives usually wrap the files in, a template always has its .sombra directory at the root
+func rootOf(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() && entries[0].Name() != ".sombra" {
+		return filepath.Join(dir, entries[0].Name()), nil
+	}
+	return dir, nil
+}
+
+func isArchiveName(name string) bool {
+	for _, ext := range extensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func trimExtension(name string) string {
+	for _, ext := range extensions {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
+
+// olderThan sorts the versions by their numbers, a pre-release is older than its release
+func olderThan(v1, v2 string) bool {
+	core1, pre1, _ := strings.Cut(strings.SplitN(v1, "+", 2)[0], "-")
+	core2, pre2, _ := strings.Cut(strings.SplitN(v2, "+", 2)[0], "-")
+	n1, n2 := numbers.FindAllString(core1, -1), numbers.FindAllString(core2, -1)
+	for i := 0; i < len(n1) && i < len(n2); i++ {
+		a, _ := strconv.Atoi(n1[i])
+		b, _ := strconv.Atoi(n2[i])
+		if a != b {
+			return a < b
+		}
+	}
+	if len(n1) != len(n2) {
+		return len(n1) < len(n2)
+	}
+	if (pre1 == "") != (pre2 == "") {
+		return pre1 != ""
+	}
+	return v1 < v2
+}
+
+func versionOf(name string) string {
+	match := versioned.FindStringSubmatch(name)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+var _ usecases.RepositoryPort = (*Service)(nil)
@@ -0,0 +1,238 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTarGz(t *testing.T, file string, files map[string]string) {
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		err = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err == nil {
+			_, err = tw.Write([]byte(content))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, file string, files map[string]string) {
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err == nil {
+			_, err = w.Write([]byte(content))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsArchive(t *testing.T) {
+	dir := t.TempDir()
+	versions := filepath.Join(dir, "versions")
+	template := filepath.Join(dir, "template")
+	for _, d := range []string{versions, filepath.Join(template, ".sombra")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTarGz(t, filepath.Join(versions, "template-v1.0.0.tar.gz"), map[string]string{"main.go": "package main\n"})
+	writeTarGz(t, filepath.Join(template, "template-v1.0.0.tar.gz"), map[string]string{"main.go": "package main\n"})
+
+	tests := []struct {
+		uri      string
+		expected bool
+	}{
+		{uri: filepath.Join(versions, "template-v1.0.0.tar.gz"), expected: true},
+		{uri: "file://" + versions, expected: true},
+		{uri: template, expected: false},
+		{uri: dir, expected: false},
+		{uri: filepath.Join(dir, "missing.zip"), expected: false},
+		{uri: "https://example.com/template-v1.0.0.zip", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			if res := IsArchive(tt.uri); res != tt.expected {
+				t.Errorf("IsArchive(%s) = %v, expected %v", tt.uri, res, tt.expected)
+			}
+		})
+	}
+}
+
+func TestService_Versions(t *testing.T) {
+	dir := t.TempDir()
+	writeTarGz(t, filepath.Join(dir, "template-v1.10.0.tar.gz"), map[string]string{
+		"template-v1.10.0/.sombra/default.yaml": "patterns: []\n",
+		"template-v1.10.0/main.go":              "package main\n\n// v1.10.0\n",
+	})
+	writeZip(t, filepath.Join(dir, "template-v1.9.0.zip"), map[string]string{
+		".sombra/default.yaml": "patterns: []\n",
+		"main.go":              "package main\n\n// v1.9.0\n",
+		"old.go":               "package main\n",
+	})
+	writeTarGz(t, filepath.Join(dir, "template-v1.10.0-rc.1.tgz"), map[string]string{"main.go": "package main\n"})
+	writeTarGz(t, filepath.Join(dir, "notes.tar.gz"), map[string]string{"notes.txt": "notes\n"})
+
+	repo, err := Factory("file://"+dir, nil)
+	if err != nil {
+		t.Fatalf("Factory() error = %v", err)
+	}
+	if err = repo.Clone(); err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	defer repo.Clean()
+
+	tags, err := repo.GetTags()
+	if err != nil {
+		t.Fatalf("GetTags() error = %v", err)
+	}
+	if strings.Join(tags, " ") != "v1.9.0 v1.10.0-rc.1 v1.10.0" {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+
+	// the latest version is used by default, without its top directory
+	content, err := os.ReadFile(filepath.Join(repo.Dir(), "main.go"))
+	if err != nil || !strings.Contains(string(content), "v1.10.0") {
+		t.Errorf("Expected the latest main.go, got %q, %v", content, err)
+	}
+
+	version, err := repo.Use("v1.9.0")
+	if err != nil || version != "v1.9.0" {
+		t.Fatalf("Use() = %s, %v", version, err)
+	}
+	content, err = os.ReadFile(filepath.Join(repo.Dir(), "main.go"))
+	if err != nil || !strings.Contains(string(content), "v1.9.0") {
+		t.Errorf("Expected the v1.9.0 main.go, got %q, %v", content, err)
+	}
+	if _, err = repo.Use("v2.0.0"); err == nil {
+		t.Errorf("Expected an error using a missing version")
+	}
+
+	if _, err = exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	if _, err = repo.Use("v1.10.0"); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+	diff, err := repo.Diff("v1.9.0", "")
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	for _, expected := range []string{"diff --git a/main.go b/main.go", "-// v1.9.0", "+// v1.10.0", "+++ /dev/null"} {
+		if !strings.Contains(string(diff), expected) {
+			t.Errorf("Expected the diff to contain %q:\n%s", expected, diff)
+		}
+	}
+	diff, err = repo.Diff(emptyTree, "")
+	if err != nil || !strings.Contains(string(diff), "+++ b/main.go") {
+		t.Errorf("Expected the whole template in the first diff, got %v:\n%s", err, diff)
+	}
+}
+
+func TestService_SingleArchive(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "template.zip")
+	writeZip(t, file, map[string]string{"main.go": "package main\n"})
+
+	repo, err := Factory(file, nil)
+	if err != nil {
+		t.Fatalf("Factory() error = %v", err)
+	}
+	if err = repo.Clone(); err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	defer repo.Clean()
+
+	tags, err := repo.GetTags()
+	if err != nil || len(tags) != 1 || tags[0] != "template" {
+		t.Errorf("GetTags() = %v, %v", tags, err)
+	}
+	if _, err = os.Stat(filepath.Join(repo.Dir(), "main.go")); err != nil {
+		t.Errorf("Expected main.go to be extracted: %v", err)
+	}
+}
+
+func TestUnpack_OutsideEntries(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "evil-v1.0.0.tar.gz")
+	writeTarGz(t, file, map[string]string{"../evil.go": "package evil\n"})
+
+	err := unpack(file, filepath.Join(dir, "out"))
+	if err == nil || !strings.Contains(err.Error(), "outside of the template") {
+		t.Errorf("Expected the entry to be rejected, got %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "evil.go")); err == nil {
+		t.Errorf("Expected evil.go not to be written")
+	}
+}
+
+func TestUnpack_LinkChain(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "evil-v1.0.0.tar.gz")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	// every name is inside of the template, but a/.. is the parent of the template once s is followed
+	for _, header := range []*tar.Header{
+		{Name: "s", Linkname: ".", Typeflag: tar.TypeSymlink},
+		{Name: "a", Linkname: "s/..", Typeflag: tar.TypeSymlink},
+		{Name: "a/escaped.txt", Mode: 0644, Size: 5, Typeflag: tar.TypeReg},
+	} {
+		if err = tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = tw.Write([]byte("evil\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err = tw.Close(); err == nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = unpack(file, filepath.Join(dir, "out"))
+	if err == nil || !strings.Contains(err.Error(), "outside of the template") {
+		t.Errorf("Expected the link to be rejected, got %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "escaped.txt")); err == nil {
+		t.Errorf("Expected escaped.txt not to be written")
+	}
+}
@@ -0,0 +1,204 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// unpack extracts the archive into dir, entries outside of it are rejected
+func unpack(file, dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	// the links of the entries are compared with the real path of dir
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(file, ".zip") {
+		return unzip(file, dir)
+	}
+	return untar(file, dir)
+}
+
+func untar(file, dir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		target, err := entryPath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			err = writeEntry(target, tr, header.FileInfo().Mode().Perm())
+		case tar.TypeSymlink:
+			err = writeSymlink(dir, target, header.Linkname)
+		}
+		// other entries like devices are not part of a template
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func unzip(file, dir string) error {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer zr.Close()
+
+	for _, entry := range zr.File {
+		target, err := entryPath(dir, entry.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := entry.Mode()
+		switch {
+		case mode.IsDir():
+			err = os.MkdirAll(target, 0755)
+		case mode&os.ModeSymlink != 0:
+			err = unzipSymlink(dir, target, entry)
+		case mode.IsRegular():
+			err = unzipFile(target, entry)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unzipFile(target string, entry *zip.File) error {
+	r, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return writeEntry(target, r, entry.Mode().Perm())
+}
+
+func unzipSymlink(dir, target string, entry *zip.File) error {
+	r, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	link, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return writeSymlink(dir, target, string(link))
+}
+
+// entryPath resolves the name of an entry inside dir, following the links already extracted so a chain of them
+// cannot lead outside of it
+func entryPath(dir, name string) (string, error) {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if !inside(dir, target) {
+		return "", fmt.Errorf("archive entry %s is outside of the template", name)
+	}
+	if target == dir {
+		return target, nil
+	}
+	parent, err := realPath(filepath.Dir(target))
+	if err != nil {
+		return "", err
+	}
+	if !inside(dir, parent) {
+		return "", fmt.Errorf("archive entry %s is outside of the template", name)
+	}
+	target = filepath.Join(parent, filepath.Base(target))
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return "", fmt.Errorf("archive entry %s is written through a link", name)
+	}
+	return target, nil
+}
+
+// realPath follows the links of path like the system does, the part of the path that does not exist yet is kept as is
+func realPath(path string) (string, error) {
+	res, err := filepath.EvalSymlinks(path)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return res, err
+	}
+	i := strings.LastIndexByte(path, filepath.Separator)
+	if i <= 0 {
+		return path, nil
+	}
+	parent, err := realPath(path[:i])
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, path[i+1:]), nil
+}
+
+func inside(dir, path string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+func writeEntry(target string, r io.Reader, perm os.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm|0200)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func writeSymlink(dir, target, link string) error {
+	if filepath.IsAbs(link) {
+		return fmt.Errorf("archive link %s points outside of the template", link)
+	}
+	// the link is not cleaned, a .. after another link goes up from the target of that link
+	real, err := realPath(filepath.Dir(target) + string(filepath.Separator) + filepath.FromSlash(link))
+	if err != nil {
+		return err
+	}
+	if !inside(dir, real) {
+		return fmt.Errorf("archive link %s points outside of the template", link)
+	}
+	err = os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(link, target)
+}
@@ -0,0 +1,101 @@
+package git
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// authFailures are the messages of git and ssh when the remote refuses the credentials
+var authFailures = []string{
+	"Authentication failed",
+	"Permission denied",
+	"could not read Username",
+	"could not read Password",
+	"terminal prompts disabled",
+	"The requested URL returned error: 401",
+	"The requested URL returned error: 403",
+}
+
+// environ passes the credentials to git, the ssh key with the ssh command and the token with an http header.
+// The header is set in the environment, so the token is never written in the git config of the repository.
+func environ(credentials *entities.Credentials) []string {
+	env := os.Environ()
+	if credentials == nil {
+		return env
+	}
+
+	// the credentials are explicit, git must not ask for others
+	env = append(env, "GIT_TERMINAL_PROMPT=0")
+	if credentials.SSHKey != "" {
+		env = appendSSHKey(env, credentials.SSHKey)
+	}
+	if credentials.Password != "" {
+		basic := base64.StdEncoding.EncodeToString([]byte(credentials.Username + ":" + credentials.Password))
+		env = appendConfig(env, "http.extraHeader", "Authorization: Basic "+basic)
+	}
+	return env
+}
+
+// appendSSHKey adds the key to the ssh command already set by the user, like a jump host or a known_hosts file
+func appendSSHKey(env []string, key string) []string {
+	command := "ssh"
+	res := make([]string, 0, len(env)+1)
+	for _, item := range env {
+		if value, found := strings.CutPrefix(item, "GIT_SSH_COMMAND="); found {
+			if value != "" {
+				command = value
+			}
+			continue
+		}
+		res = append(res, item)
+	}
+	if command == "ssh" {
+		// GIT_SSH_COMMAND replaces core.sshCommand, its value is kept the same way
+		cmd := exec.Command("git", "config", "--get", "core.sshCommand")
+		cmd.Env = res
+		cmd.Dir = os.TempDir()
+		if out, err := cmd.Output(); err == nil && strings.TrimSpace(string(out)) != "" {
+			command = strings.TrimSpace(string(out))
+		}
+	}
+	return append(res, fmt.Sprintf("GIT_SSH_COMMAND=%s -i %s -o IdentitiesOnly=yes", command, shellQuote(key)))
+}
+
+// shellQuote quotes a value for the shell running the ssh command
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// appendConfig adds an entry after the GIT_CONFIG_KEY_n entries already set by the user, like a proxy in the CI
+func appendConfig(env []string, key, value string) []string {
+	count := 0
+	res := make([]string, 0, len(env)+3)
+	for _, item := range env {
+		if n, found := strings.CutPrefix(item, "GIT_CONFIG_COUNT="); found {
+			count, _ = strconv.Atoi(n)
+			continue
+		}
+		res = append(res, item)
+	}
+	count = max(count, 0)
+	return append(res,
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", count+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", count, key),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", count, value),
+	)
+}
+
+// authError explains the failures caused by the credentials, git only prints them
+func authError(uri, stderr string, err error) error {
+	for _, failure := range authFailures {
+		if strings.Contains(stderr, failure) {
+			return fmt.Errorf("authentication to %s failed, check the auth of the template in sombra.yaml or the global config: %w", uri, err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+func TestEnviron(t *testing.T) {
+	t.Setenv("GIT_SSH_COMMAND", "")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	env := strings.Join(environ(&entities.Credentials{SSHKey: "/keys/id", Username: "user", Password: "pass"}), "\n")
+	for _, expected := range []string{
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_SSH_COMMAND=ssh -i '/keys/id' -o IdentitiesOnly=yes",
+		"GIT_CONFIG_KEY_0=http.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic dXNlcjpwYXNz",
+	} {
+		if !strings.Contains(env, expected) {
+			t.Errorf("Expected %s in the environment", expected)
+		}
+	}
+
+	if env = strings.Join(environ(nil), "\n"); strings.Contains(env, "GIT_TERMINAL_PROMPT=0") {
+		t.Errorf("Expected the ambient credentials to be used without credentials")
+	}
+}
+
+func TestEnvironSSHCommand(t *testing.T) {
+	t.Setenv("GIT_SSH_COMMAND", "ssh -J bastion")
+
+	env := environ(&entities.Credentials{SSHKey: "/keys/o'brien/id"})
+	var commands []string
+	for _, item := range env {
+		if strings.HasPrefix(item, "GIT_SSH_COMMAND=") {
+			commands = append(commands, item)
+		}
+	}
+	expected := `GIT_SSH_COMMAND=ssh -J bastion -i '/keys/o'\''brien/id' -o IdentitiesOnly=yes`
+	if len(commands) != 1 || commands[0] != expected {
+		t.Errorf("Expected %s, got %v", expected, commands)
+	}
+}
+
+func TestEnvironConfigCount(t *testing.T) {
+	t.Setenv("GIT_CONFIG_COUNT", "2")
+	t.Setenv("GIT_CONFIG_KEY_0", "http.proxy")
+	t.Setenv("GIT_CONFIG_VALUE_0", "http://proxy:3128")
+	t.Setenv("GIT_CONFIG_KEY_1", "safe.directory")
+	t.Setenv("GIT_CONFIG_VALUE_1", "*")
+
+	env := environ(&entities.Credentials{Username: "user", Password: "pass"})
+	joined := strings.Join(env, "\n")
+	for _, expected := range []string{
+		"GIT_CONFIG_KEY_0=http.proxy",
+		"GIT_CONFIG_KEY_1=safe.directory",
+		"GIT_CONFIG_COUNT=3",
+		"GIT_CONFIG_KEY_2=http.extraHeader",
+		"GIT_CONFIG_VALUE_2=Authorization: Basic dXNlcjpwYXNz",
+	} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("Expected %s in the environment", expected)
+		}
+	}
+	if strings.Count(joined, "GIT_CONFIG_COUNT=") != 1 {
+		t.Errorf("Expected a single GIT_CONFIG_COUNT, got %v", env)
+	}
+}
+
+func TestAuthError(t *testing.T) {
+	err := errors.New("exit status 128")
+	res := authError("https://github.com/acme/template", "remote: Invalid username or password.\nfatal: Authentication failed for 'https://github.com/acme/template/'\n", err)
+	if !errors.Is(res, err) || !strings.Contains(res.Error(), "authentication to https://github.com/acme/template failed") {
+		t.Errorf("Expected an authentication error, got %v", res)
+	}
+	if res = authError("https://github.com/acme/template", "fatal: repository not found\n", err); res != err {
+		t.Errorf("Expected the error to be kept, got %v", res)
+	}
+}
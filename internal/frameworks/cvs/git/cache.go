@@ -0,0 +1,231 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// uriFile stores the URI of the mirror, its modification time is the last time the template was used
+const uriFile = "sombra-uri"
+
+// Cache keeps a mirror of each template repository, keyed by the hash of its normalised URI
+type Cache struct {
+	root string
+}
+
+// NewCache returns the cache stored in $XDG_CACHE_HOME/sombra
+func NewCache() (*Cache, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		var err error
+		base, err = os.UserCacheDir()
+		if err != nil {
+			logger.Error("Failed to find the cache directory", err)
+			return nil, err
+		}
+	}
+	return &Cache{root: filepath.Join(base, "sombra", "repos")}, nil
+}
+
+// Mirror returns the mirror of the URI, fetching the new tags or cloning it the first time
+func (c *Cache) Mirror(uri string, credentials *entities.Credentials, offline bool) (string, error) {
+	dir := filepath.Join(c.root, key(uri))
+	_, err := os.Stat(filepath.Join(dir, uriFile))
+	switch {
+	case err == nil && offline:
+		logger.Info(fmt.Sprintf("Using cached repository for %s without fetching it", uri))
+	case err == nil:
+		err = c.fetch(uri, dir, credentials)
+	case offline:
+		err = fmt.Errorf("%s is not in the cache, run the command once without --offline", uri)
+		logger.Error("Failed to find cached repository", err)
+	default:
+		err = c.clone(uri, dir, credentials)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// the URI file is written again to record the use of the template
+	err = os.WriteFile(filepath.Join(dir, uriFile), []byte(uri+"\n"), 0644)
+	if err != nil {
+		logger.Error("Failed to update cached repository "+dir, err)
+		return "", err
+	}
+	return dir, nil
+}
+
+func (c *Cache) fetch(uri, dir string, credentials *entities.Credentials) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "fetch", "--tags", "--prune", "--quiet", "origin")
+	cmd.Dir = dir
+	cmd.Env = environ(credentials)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	err := cmd.Run()
+	if err != nil {
+		err = authError(uri, stderr.String(), err)
+		logger.Error("Failed to fetch cached repository, use --offline to work with the cached version", err)
+		return err
+	}
+	logger.Info("Fetched cached repository: " + dir)
+	return nil
+}
+
+// clone mirrors the repository next to its final directory, an interrupted clone never looks like a cached one
+func (c *Cache) clone(uri, dir string, credentials *entities.Credentials) error {
+	err := os.MkdirAll(c.root, 0755)
+	if err != nil {
+		logger.Error("Failed to create cache directory", err)
+		return err
+	}
+	tmp := filepath.Join(c.root, "tmp-"+uuid.New().String())
+	defer os.RemoveAll(tmp)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "clone", "--mirror", "--quiet", uri, tmp)
+	cmd.Env = environ(credentials)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	err = cmd.Run()
+	if err != nil {
+		err = authError(uri, stderr.String(), err)
+	}
+	if err == nil {
+		err = os.WriteFile(filepath.Join(tmp, uriFile), []byte(uri+"\n"), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp, dir)
+	}
+	if err != nil {
+		logger.Error("Failed to clone git repo into the cache", err)
+		return err
+	}
+	logger.Info(fmt.Sprintf("Cloned repository from URL: %s to cache: %s", uri, dir))
+	return nil
+}
+
+func (c *Cache) List() ([]*entities.CacheEntry, error) {
+	entries, err := os.ReadDir(c.root)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*entities.CacheEntry{}, nil
+	}
+	if err != nil {
+		logger.Error("Failed to list cache directory", err)
+		return nil, err
+	}
+
+	res := make([]*entities.CacheEntry, 0, len(entries))
+	for _, entry := range entries {
+		dir := filepath.Join(c.root, entry.Name())
+		uri, err := os.ReadFile(filepath.Join(dir, uriFile))
+		if err != nil {
+			// left by an interrupted clone
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, uriFile))
+		if err != nil {
+			return nil, err
+		}
+		size, err := dirSize(dir)
+		if err != nil {
+			logger.Error("Failed to read cached repository "+dir, err)
+			return nil, err
+		}
+		res = append(res, &entities.CacheEntry{
+			URI:      strings.TrimSpace(string(uri)),
+			Dir:      dir,
+			Size:     size,
+			LastUsed: info.ModTime().Format(time.DateTime),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].URI < res[j].URI
+	})
+	logger.Info(fmt.Sprintf("Found %d cached repositories", len(res)))
+	return res, nil
+}
+
+func (c *Cache) Prune(days int) ([]*entities.CacheEntry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -days)
+	removed := make([]*entities.CacheEntry, 0)
+	for _, entry := range entries {
+		info, err := os.Stat(filepath.Join(entry.Dir, uriFile))
+		if err != nil {
+			return removed, err
+		}
+		if info.ModTime().After(cutoff) {
+			// the worktrees of interrupted runs are forgotten
+			cmd := exec.Command("git", "worktree", "prune")
+			cmd.Dir = entry.Dir
+			_ = cmd.Run()
+			continue
+		}
+		err = os.RemoveAll(entry.Dir)
+		if err != nil {
+			logger.Error("Failed to remove cached repository "+entry.Dir, err)
+			return removed, err
+		}
+		removed = append(removed, entry)
+	}
+	logger.Info(fmt.Sprintf("Pruned %d cached repositories", len(removed)))
+	return removed, nil
+}
+
+func (c *Cache) Clear() ([]*entities.CacheEntry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	err = os.RemoveAll(c.root)
+	if err != nil {
+		logger.Error("Failed to remove cache directory", err)
+		return nil, err
+	}
+	logger.Info("Removed cache directory: " + c.root)
+	return entries, nil
+}
+
+// key hashes the normalised URI, so the same repository is cached once whatever the way it is written
+func key(uri string) string {
+	sum := sha256.Sum256([]byte(entities.NormaliseURI(uri)))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+var _ usecases.RepositoryCachePort = (*Cache)(nil)
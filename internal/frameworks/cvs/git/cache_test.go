@@ -0,0 +1,94 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	origin := t.TempDir()
+	git := func(dir string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git(origin, "init", "--quiet")
+	if err := os.WriteFile(filepath.Join(origin, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(origin, "add", "--all")
+	git(origin, "commit", "--quiet", "-m", "initial")
+	git(origin, "tag", "v1.0.0")
+
+	cache := &Cache{root: filepath.Join(t.TempDir(), "repos")}
+
+	// offline, nothing can be read before the first clone
+	repo, err := NewFactory(cache, true)(origin, nil)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	if err = repo.Clone(); err == nil {
+		t.Errorf("Expected an error cloning offline an uncached repository")
+	}
+
+	use := func(offline bool) []string {
+		repo, err := NewFactory(cache, offline)(origin, nil)
+		if err != nil {
+			t.Fatalf("NewFactory() error = %v", err)
+		}
+		if err = repo.Clone(); err != nil {
+			t.Fatalf("Clone() error = %v", err)
+		}
+		defer repo.Clean()
+		if _, err = repo.Use("v1.0.0"); err != nil {
+			t.Fatalf("Use() error = %v", err)
+		}
+		if _, err = os.Stat(filepath.Join(repo.Dir(), "main.go")); err != nil {
+			t.Errorf("Expected main.go in the worktree: %v", err)
+		}
+		tags, err := repo.GetTags()
+		if err != nil {
+			t.Fatalf("GetTags() error = %v", err)
+		}
+		return tags
+	}
+
+	if tags := use(false); len(tags) != 1 {
+		t.Errorf("Expected 1 tag, got %v", tags)
+	}
+
+	// the new tags are fetched by the next run, but not offline
+	git(origin, "tag", "v1.1.0")
+	if tags := use(true); len(tags) != 1 {
+		t.Errorf("Expected the cached tags offline, got %v", tags)
+	}
+	if tags := use(false); len(tags) != 2 {
+		t.Errorf("Expected the new tag to be fetched, got %v", tags)
+	}
+
+	entries, err := cache.List()
+	if err != nil || len(entries) != 1 || entries[0].URI != origin || entries[0].Size == 0 {
+		t.Fatalf("List() = %v, %v", entries, err)
+	}
+
+	removed, err := cache.Prune(30)
+	if err != nil || len(removed) != 0 {
+		t.Errorf("Expected the recent repository to be kept, got %v, %v", removed, err)
+	}
+	removed, err = cache.Clear()
+	if err != nil || len(removed) != 1 {
+		t.Errorf("Clear() = %v, %v", removed, err)
+	}
+	if entries, _ = cache.List(); len(entries) != 0 {
+		t.Errorf("Expected an empty cache, got %v", entries)
+	}
+}
@@ -2,10 +2,13 @@ package git
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
 	"github.com/sombrahq/sombra-cli/internal/core/usecases"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -16,9 +19,15 @@ type Service struct {
 	uri  string
 	path string
 	name string
+	// credentials are passed to the git commands that reach the remote, nil uses the ambient ones
+	credentials *entities.Credentials
+	// cache keeps the repository between runs, the template is checked out in a worktree of its mirror
+	cache   *Cache
+	offline bool
+	mirror  string
 }
 
-func Factory(uri string) (usecases.RepositoryPort, error) {
+func Factory(uri string, credentials *entities.Credentials) (usecases.RepositoryPort, error) {
 	tmp, err := os.MkdirTemp("/tmp", "*")
 	if err != nil {
 		logger.Error("Failed to create temp directory", err)
@@ -27,19 +36,41 @@ func Factory(uri string) (usecases.RepositoryPort, error) {
 	name := uuid.New().String()
 	logger.Info(fmt.Sprintf("Created repository service for URL: %s", uri))
 	return &Service{
-		uri:  uri,
-		path: tmp,
-		name: name,
+		uri:         uri,
+		path:        tmp,
+		name:        name,
+		credentials: credentials,
 	}, nil
 }
 
+// NewFactory returns a factory of repositories read from the cache, an offline one never fetches them
+func NewFactory(cache *Cache, offline bool) usecases.RepositoryFactory {
+	return func(uri string, credentials *entities.Credentials) (usecases.RepositoryPort, error) {
+		repo, err := Factory(uri, credentials)
+		if err != nil {
+			return nil, err
+		}
+		service := repo.(*Service)
+		service.cache = cache
+		service.offline = offline
+		return service, nil
+	}
+}
+
 func (t *Service) Clone() error {
+	if t.cache != nil {
+		return t.checkout()
+	}
+
+	var stderr bytes.Buffer
 	cmd := exec.Command("git", "clone", t.uri, t.name)
 	cmd.Dir = t.path
-	cmd.Stderr = os.Stderr
+	cmd.Env = environ(t.credentials)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
 	cmd.Stdout = os.Stdout
 	err := cmd.Run()
 	if err != nil {
+		err = authError(t.uri, stderr.String(), err)
 		logger.Error("Failed to clone git repo", err)
 		return err
 	}
@@ -47,7 +78,37 @@ func (t *Service) Clone() error {
 	return nil
 }
 
+// checkout adds a worktree of the cached mirror, instead of cloning the repository again
+func (t *Service) checkout() error {
+	mirror, err := t.cache.Mirror(t.uri, t.credentials, t.offline)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "worktree", "add", "--detach", "--quiet", t.Dir())
+	cmd.Dir = mirror
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		logger.Error("Failed to add worktree of cached repository", err)
+		return err
+	}
+	t.mirror = mirror
+	logger.Info(fmt.Sprintf("Checked out cached repository of URL: %s to path: %s", t.uri, t.Dir()))
+	return nil
+}
+
 func (t *Service) Clean() error {
+	if t.mirror != "" {
+		cmd := exec.Command("git", "worktree", "remove", "--force", t.Dir())
+		cmd.Dir = t.mirror
+		err := cmd.Run()
+		if err != nil {
+			// the worktree is forgotten by the next prune of the cache
+			logger.Error("Failed to remove worktree of cached repository", err)
+		}
+	}
+
 	err := os.RemoveAll(t.path) // UseTag RemoveAll to delete the directory and its contents
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to remove directory %s", t.path), err)
@@ -77,8 +138,13 @@ func (t *Service) Use(version string) (string, error) {
 		isTag = true
 	}
 
-	// Use git checkout to switch to the specified version
-	cmd = exec.Command("git", "checkout", version)
+	// Use git checkout to switch to the specified version,
+	// the branches of the mirror are detached so several worktrees can use them at once
+	args := []string{"checkout", version}
+	if t.mirror != "" {
+		args = []string{"checkout", "--detach", version}
+	}
+	cmd = exec.Command("git", args...)
 	cmd.Dir = t.Dir()
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = os.Stdout
@@ -113,8 +179,13 @@ func (t *Service) Use(version string) (string, error) {
 	return commitID, nil
 }
 
-func (t *Service) Diff(commit string) ([]byte, error) {
-	cmd := exec.Command("git", "diff", "--diff-algorithm=histogram", "--patch", "--unified=10", fmt.Sprintf("%s..HEAD", commit))
+func (t *Service) Diff(commit, subdir string) ([]byte, error) {
+	args := []string{"diff", "--diff-algorithm=histogram", "--patch", "--unified=10", fmt.Sprintf("%s..HEAD", commit)}
+	if subdir != "" {
+		// the paths are relative to the template, like the ones of a template at the root
+		args = append(args, "--relative="+subdir, "--", subdir)
+	}
+	cmd := exec.Command("git", args...)
 	cmd.Dir = t.Dir()
 	cmd.Stderr = os.Stderr
 	diff, err := cmd.Output()
@@ -140,4 +211,32 @@ func (t *Service) GetTags() ([]string, error) {
 	return tags, nil
 }
 
+func (t *Service) IsAncestor(ancestor, commit string) (bool, error) {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, commit)
+	cmd.Dir = t.Dir()
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+
+	// the command exits with 1 when the ancestor is not part of the history
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	// a commit removed by a force-push is not part of the history either
+	if err != nil && (!t.hasCommit(ancestor) || !t.hasCommit(commit)) {
+		return false, nil
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to check if %s is an ancestor of %s", ancestor, commit), err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (t *Service) hasCommit(version string) bool {
+	cmd := exec.Command("git", "cat-file", "-e", version+"^{commit}")
+	cmd.Dir = t.Dir()
+	return cmd.Run() == nil
+}
+
 var _ usecases.RepositoryPort = (*Service)(nil)
@@ -0,0 +1,389 @@
+package gogit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// emptyTree is the git empty tree, the diff of the first update starts from it
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// Service reads the template with a pure Go git implementation, without a git binary.
+// The objects are kept in memory and only the versions in use are fetched, with a depth of one commit.
+type Service struct {
+	uri  string
+	path string
+	repo *git.Repository
+	// refs are the branches and tags of the remote, listed once when cloning
+	refs map[plumbing.ReferenceName]plumbing.Hash
+	head plumbing.ReferenceName
+	// auth is nil when the template has no credentials
+	auth transport.AuthMethod
+	// full is set once the whole history is fetched, a shallow commit has no parents to walk
+	full bool
+}
+
+func Factory(uri string, credentials *entities.Credentials) (usecases.RepositoryPort, error) {
+	auth, err := authMethod(uri, credentials)
+	if err != nil {
+		logger.Error("Failed to read credentials of "+uri, err)
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "sombra-")
+	if err != nil {
+		logger.Error("Failed to create temp directory", err)
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Created native repository service for URL: %s", uri))
+	return &Service{
+		uri:  uri,
+		path: tmp,
+		refs: make(map[plumbing.ReferenceName]plumbing.Hash),
+		auth: auth,
+	}, nil
+}
+
+// authMethod picks the credentials matching the protocol of the URI, go-git refuses the others
+func authMethod(uri string, credentials *entities.Credentials) (transport.AuthMethod, error) {
+	if credentials == nil {
+		return nil, nil
+	}
+	endpoint, err := transport.NewEndpoint(uri)
+	if err != nil {
+		return nil, err
+	}
+	switch endpoint.Protocol {
+	case "http", "https":
+		if credentials.Password != "" {
+			return &githttp.BasicAuth{Username: credentials.Username, Password: credentials.Password}, nil
+		}
+	case "ssh":
+		if credentials.SSHKey != "" {
+			user := endpoint.User
+			if user == "" {
+				user = gitssh.DefaultUsername
+			}
+			return gitssh.NewPublicKeysFromFile(user, credentials.SSHKey, "")
+		}
+	}
+	return nil, nil
+}
+
+// authError explains the failures caused by the credentials
+func (s *Service) authError(err error) error {
+	if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) {
+		return fmt.Errorf("authentication to %s failed, check the auth of the template in sombra.yaml or the global config: %w", s.uri, err)
+	}
+	return err
+}
+
+// Clone lists the remote refs and checks out the default branch, the other versions are fetched when they are used
+func (s *Service) Clone() error {
+	repo, err := git.Init(memory.NewStorage(), osfs.New(s.Dir()))
+	if err != nil {
+		logger.Error("Failed to init git repo", err)
+		return err
+	}
+	remote, err := repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{s.uri}})
+	if err != nil {
+		logger.Error("Failed to add git remote", err)
+		return err
+	}
+	s.repo = repo
+
+	refs, err := remote.List(&git.ListOptions{Auth: s.auth})
+	if err != nil {
+		err = s.authError(err)
+		logger.Error("Failed to list git remote refs", err)
+		return err
+	}
+	for _, ref := range refs {
+		switch {
+		case ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference:
+			s.head = ref.Target()
+		case ref.Name().IsBranch() || ref.Name().IsTag():
+			s.refs[ref.Name()] = ref.Hash()
+		}
+	}
+	if s.head == "" {
+		err = fmt.Errorf("%s has no default branch", s.uri)
+		logger.Error("Failed to clone git repo", err)
+		return err
+	}
+
+	_, err = s.Use(s.head.Short())
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("Cloned repository from URL: %s to path: %s", s.uri, s.Dir()))
+	return nil
+}
+
+func (s *Service) Clean() error {
+	err := os.RemoveAll(s.path)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to remove directory %s", s.path), err)
+		return err
+	}
+	logger.Info(fmt.Sprintf("Removed temporary directory: %s", s.path))
+	return nil
+}
+
+func (s *Service) Dir() string {
+	return filepath.Join(s.path, "repo")
+}
+
+// Use checks out a tag, a branch or a commit, returning the tag name or the commit hash like the git CLI
+func (s *Service) Use(version string) (string, error) {
+	ref, commit, err := s.resolve(version)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to checkout version %s", version), err)
+		return "", err
+	}
+
+	wt, err := s.repo.Worktree()
+	if err == nil {
+		err = wt.Checkout(&git.CheckoutOptions{Hash: commit.Hash, Force: true})
+	}
+	if err == nil {
+		// files of the previous version that are not part of this one
+		err = wt.Clean(&git.CleanOptions{Dir: true})
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to checkout version %s", version), err)
+		return "", err
+	}
+
+	if ref.IsTag() {
+		logger.Info(fmt.Sprintf("Checked out tag: %s", version))
+		return version, nil
+	}
+	logger.Info(fmt.Sprintf("Checked out commit: %s", commit.Hash))
+	return commit.Hash.String(), nil
+}
+
+// Diff compares the commit with the version in use, in memory
+func (s *Service) Diff(commit, subdir string) ([]byte, error) {
+	head, err := s.repo.Head()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get diff for commit %s", commit), err)
+		return nil, err
+	}
+	to, err := s.tree(head.Hash(), subdir)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get diff for commit %s", commit), err)
+		return nil, err
+	}
+
+	var from *object.Tree
+	if commit != emptyTree {
+		_, c, err := s.resolve(commit)
+		if err == nil {
+			from, err = s.tree(c.Hash, subdir)
+		}
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to get diff for commit %s", commit), err)
+			return nil, err
+		}
+	}
+
+	changes, err := object.DiffTreeWithOptions(context.Background(), from, to, object.DefaultDiffTreeOptions)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get diff for commit %s", commit), err)
+		return nil, err
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get diff for commit %s", commit), err)
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = diff.NewUnifiedEncoder(&buf, 10).Encode(patch)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get diff for commit %s", commit), err)
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Generated diff for commit: %s", commit))
+	return buf.Bytes(), nil
+}
+
+func (s *Service) GetTags() ([]string, error) {
+	tags := make([]string, 0)
+	for name := range s.refs {
+		if name.IsTag() {
+			tags = append(tags, name.Short())
+		}
+	}
+	sort.Strings(tags)
+	logger.Info(fmt.Sprintf("Retrieved tags: %v", tags))
+	return tags, nil
+}
+
+func (s *Service) IsAncestor(ancestor, commit string) (bool, error) {
+	// the parents of shallow commits are missing
+	err := s.fetchAll()
+	if err != nil {
+		return false, err
+	}
+	_, a, err := s.resolve(ancestor)
+	// a commit removed by a force-push is not part of the history either
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to check if %s is an ancestor of %s", ancestor, commit), err)
+		return false, err
+	}
+	_, c, err := s.resolve(commit)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to check if %s is an ancestor of %s", ancestor, commit), err)
+		return false, err
+	}
+	return a.IsAncestor(c)
+}
+
+// resolve finds the commit of a tag, a branch or a hash, fetching it when it is not in memory yet
+func (s *Service) resolve(version string) (plumbing.ReferenceName, *object.Commit, error) {
+	for _, name := range []plumbing.ReferenceName{plumbing.NewTagReferenceName(version), plumbing.NewBranchReferenceName(version)} {
+		hash, ok := s.refs[name]
+		if !ok {
+			continue
+		}
+		commit, err := s.commit(hash)
+		if err != nil {
+			err = s.fetch(name)
+			if err != nil {
+				return "", nil, err
+			}
+			commit, err = s.commit(hash)
+		}
+		return name, commit, err
+	}
+
+	// a commit of the history, e.g. the head of a branch in a previous update
+	hash := plumbing.NewHash(version)
+	if hash.IsZero() || hash.String() != strings.ToLower(version) {
+		return "", nil, fmt.Errorf("version %s not found in %s", version, s.uri)
+	}
+	commit, err := s.commit(hash)
+	if err != nil {
+		err = s.fetchAll()
+		if err != nil {
+			return "", nil, err
+		}
+		commit, err = s.commit(hash)
+	}
+	return "", commit, err
+}
+
+// commit peels annotated tags
+func (s *Service) commit(hash plumbing.Hash) (*object.Commit, error) {
+	tag, err := s.repo.TagObject(hash)
+	if err == nil {
+		return tag.Commit()
+	}
+	return s.repo.CommitObject(hash)
+}
+
+// tree returns the tree of the subdirectory, the paths of the diff are relative to it.
+// A version without the subdirectory has an empty tree.
+func (s *Service) tree(hash plumbing.Hash, subdir string) (*object.Tree, error) {
+	commit, err := s.commit(hash)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil || subdir == "" {
+		return tree, err
+	}
+	tree, err = tree.Tree(subdir)
+	if errors.Is(err, object.ErrDirectoryNotFound) {
+		return nil, nil
+	}
+	return tree, err
+}
+
+// fetch gets the last commit of the ref only
+func (s *Service) fetch(name plumbing.ReferenceName) error {
+	if s.full {
+		return fmt.Errorf("%s not found in %s", name.Short(), s.uri)
+	}
+	err := s.repo.Fetch(&git.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", name, name))},
+		Depth:    1,
+		Tags:     git.NoTags,
+		Auth:     s.auth,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		err = s.authError(err)
+		logger.Error(fmt.Sprintf("Failed to fetch %s", name.Short()), err)
+		return err
+	}
+	logger.Info(fmt.Sprintf("Fetched %s from %s", name.Short(), s.uri))
+	return nil
+}
+
+// fetchAll gets the whole history, it is only needed to compare commits
+func (s *Service) fetchAll() error {
+	if s.full {
+		return nil
+	}
+
+	// the history is fetched again, the shallow commits in memory would hide their parents
+	head, err := s.repo.Head()
+	if err != nil {
+		return err
+	}
+	storage := memory.NewStorage()
+	repo, err := git.Init(storage, osfs.New(s.Dir()))
+	if err == nil {
+		_, err = repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{s.uri}})
+	}
+	if err == nil {
+		err = repo.Fetch(&git.FetchOptions{
+			RefSpecs: []config.RefSpec{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"},
+			Tags:     git.NoTags,
+			Auth:     s.auth,
+		})
+	}
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		err = s.authError(err)
+		logger.Error("Failed to fetch the history of "+s.uri, err)
+		return err
+	}
+
+	// the checked out version is kept
+	err = storage.SetReference(plumbing.NewHashReference(plumbing.HEAD, head.Hash()))
+	if err != nil {
+		return err
+	}
+	s.repo = repo
+	s.full = true
+	logger.Info("Fetched the history of " + s.uri)
+	return nil
+}
+
+var _ usecases.RepositoryPort = (*Service)(nil)
@@ -0,0 +1,131 @@
+package gogit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestService(t *testing.T) {
+	// the local transport of go-git runs git-upload-pack, remote ones do not need git
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	origin := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = origin
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(files map[string]string, message string) string {
+		for name, content := range files {
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(origin, name)), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(origin, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		git("add", "--all")
+		git("commit", "--quiet", "-m", message)
+		return git("rev-parse", "HEAD")
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	first := commit(map[string]string{"main.go": "package main\n", "old.go": "package old\n"}, "first")
+	git("tag", "v1.0.0")
+	if err := os.Remove(filepath.Join(origin, "old.go")); err != nil {
+		t.Fatal(err)
+	}
+	commit(map[string]string{"main.go": "package main\n\n// v1.1.0\n", "new.go": "package main\n", "api/main.go": "package api\n"}, "second")
+	git("tag", "-a", "v1.1.0", "-m", "annotated")
+	head := commit(map[string]string{"main.go": "package main\n\n// main\n"}, "third")
+
+	repo, err := Factory("file://"+origin, nil)
+	if err != nil {
+		t.Fatalf("Factory() error = %v", err)
+	}
+	if err = repo.Clone(); err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	defer repo.Clean()
+
+	read := func(name string) string {
+		content, _ := os.ReadFile(filepath.Join(repo.Dir(), name))
+		return string(content)
+	}
+	if !strings.Contains(read("main.go"), "// main") {
+		t.Errorf("Expected the default branch to be checked out, got %q", read("main.go"))
+	}
+
+	tags, err := repo.GetTags()
+	if err != nil || strings.Join(tags, " ") != "v1.0.0 v1.1.0" {
+		t.Errorf("GetTags() = %v, %v", tags, err)
+	}
+
+	version, err := repo.Use("v1.0.0")
+	if err != nil || version != "v1.0.0" {
+		t.Fatalf("Use() = %s, %v", version, err)
+	}
+	if read("old.go") == "" || read("new.go") != "" {
+		t.Errorf("Expected the files of v1.0.0 only")
+	}
+
+	if _, err = repo.Use("v1.1.0"); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+	patch, err := repo.Diff("v1.0.0", "")
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	for _, expected := range []string{"diff --git a/main.go b/main.go", "+// v1.1.0", "+++ b/new.go", "deleted file mode"} {
+		if !strings.Contains(string(patch), expected) {
+			t.Errorf("Expected the diff to contain %q:\n%s", expected, patch)
+		}
+	}
+	patch, err = repo.Diff(emptyTree, "")
+	if err != nil || !strings.Contains(string(patch), "--- /dev/null") {
+		t.Errorf("Expected the whole version in the first diff, got %v:\n%s", err, patch)
+	}
+
+	// a template in a subdirectory only sees its files, relative to it
+	patch, err = repo.Diff("v1.0.0", "api")
+	if err != nil || !strings.Contains(string(patch), "+++ b/main.go") || strings.Contains(string(patch), "new.go") {
+		t.Errorf("Expected the diff of the subdirectory only, got %v:\n%s", err, patch)
+	}
+
+	// branches return their commit, which needs the history to be compared
+	version, err = repo.Use("main")
+	if err != nil || version != head {
+		t.Fatalf("Use() = %s, %v, expected %s", version, err, head)
+	}
+	isAncestor, err := repo.IsAncestor(first, head)
+	if err != nil || !isAncestor {
+		t.Errorf("IsAncestor() = %v, %v", isAncestor, err)
+	}
+	isAncestor, err = repo.IsAncestor(head, first)
+	if err != nil || isAncestor {
+		t.Errorf("IsAncestor() = %v, %v", isAncestor, err)
+	}
+	// the commit of a previous update may be gone after a force-push
+	isAncestor, err = repo.IsAncestor("0123456789abcdef0123456789abcdef01234567", head)
+	if err != nil || isAncestor {
+		t.Errorf("IsAncestor() of a missing commit = %v, %v", isAncestor, err)
+	}
+	if _, err = repo.Use(first); err != nil {
+		t.Errorf("Use() error = %v", err)
+	}
+	if read("old.go") == "" {
+		t.Errorf("Expected the files of the first commit")
+	}
+	if _, err = repo.Use("v9.9.9"); err == nil {
+		t.Errorf("Expected an error using a missing version")
+	}
+}
@@ -0,0 +1,181 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const scheme = "file://"
+
+// Service reads the template from a local directory without cloning it.
+// The working tree is used by default, committed versions are read from a clone when the directory is a git repository.
+type Service struct {
+	root string
+	// repo is the clone used for the committed versions, nil when the directory is not a git repository
+	repo usecases.RepositoryPort
+	dir  string
+}
+
+// IsLocal tells if the URI is a file:// URI or the path of a local directory
+func IsLocal(uri string) bool {
+	if strings.HasPrefix(uri, scheme) {
+		return true
+	}
+	if strings.Contains(uri, "://") {
+		return false
+	}
+	info, err := os.Stat(uri)
+	return err == nil && info.IsDir()
+}
+
+// NewFactory returns the factory of local directories, the git factory is used for the committed versions
+func NewFactory(gitFactory usecases.RepositoryFactory) usecases.RepositoryFactory {
+	return func(uri string, credentials *entities.Credentials) (usecases.RepositoryPort, error) {
+		root, err := filepath.Abs(strings.TrimPrefix(uri, scheme))
+		if err != nil {
+			logger.Error("Failed to resolve local template "+uri, err)
+			return nil, err
+		}
+
+		var repo usecases.RepositoryPort
+		if _, err = os.Stat(filepath.Join(root, ".git")); err == nil {
+			repo, err = gitFactory(root, credentials)
+			if err != nil {
+				return nil, err
+			}
+		}
+		logger.Info(fmt.Sprintf("Created local repository service for: %s", root))
+		return &Service{root: root, repo: repo, dir: root}, nil
+	}
+}
+
+func (s *Service) Clone() error {
+	info, err := os.Stat(s.root)
+	if err == nil && !info.IsDir() {
+		err = fmt.Errorf("%s is not a directory", s.root)
+	}
+	if err != nil {
+		logger.Error("Failed to open local template", err)
+		return err
+	}
+
+	// the clone is only needed for the committed versions, but it is cheap for a local repository
+	if s.repo != nil {
+		return s.repo.Clone()
+	}
+	return nil
+}
+
+func (s *Service) Clean() error {
+	if s.repo != nil {
+		return s.repo.Clean()
+	}
+	return nil
+}
+
+func (s *Service) Dir() string {
+	return s.dir
+}
+
+func (s *Service) Use(version string) (string, error) {
+	if entities.Version(version) == entities.WorkingTree {
+		s.dir = s.root
+		logger.Info("Using the working tree of " + s.root)
+		return version, nil
+	}
+
+	if s.repo == nil {
+		err := fmt.Errorf("%s is not a git repository, only the %s version is available", s.root, entities.WorkingTree)
+		logger.Error(fmt.Sprintf("Failed to use version %s", version), err)
+		return "", err
+	}
+	res, err := s.repo.Use(version)
+	if err != nil {
+		return "", err
+	}
+	s.dir = s.repo.Dir()
+	return res, nil
+}
+
+func (s *Service) Diff(commit, subdir string) ([]byte, error) {
+	if s.repo == nil {
+		err := fmt.Errorf("%s is not a git repository, there is no history to diff", s.root)
+		logger.Error(fmt.Sprintf("Failed to get diff for commit %s", commit), err)
+		return nil, err
+	}
+	if s.dir != s.root {
+		return s.repo.Diff(commit, subdir)
+	}
+	return s.worktreeDiff(commit, subdir)
+}
+
+// worktreeDiff compares the commit with the working tree, including the untracked files.
+// A temporary index is used, so the index of the template author is not touched, but git add still writes the
+// blobs of the changed files in the object store of the repository. They are loose objects that no ref points to,
+// git gc removes them like any other unreachable object.
+func (s *Service) worktreeDiff(commit, subdir string) ([]byte, error) {
+	tmp, err := os.MkdirTemp("", "sombra-index-")
+	if err != nil {
+		logger.Error("Failed to create temp directory", err)
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(tmp, "index"))
+
+	cmd := exec.Command("git", "add", "--all")
+	cmd.Dir = s.root
+	cmd.Env = env
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		logger.Error("Failed to read the working tree", err)
+		return nil, err
+	}
+
+	args := []string{"diff", "--cached", "--diff-algorithm=histogram", "--patch", "--unified=10", commit}
+	if subdir != "" {
+		args = append(args, "--relative="+subdir, "--", subdir)
+	}
+	cmd = exec.Command("git", args...)
+	cmd.Dir = s.root
+	cmd.Env = env
+	cmd.Stderr = os.Stderr
+	diff, err := cmd.Output()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get diff for commit %s", commit), err)
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Generated diff for commit %s against the working tree", commit))
+	return diff, nil
+}
+
+func (s *Service) GetTags() ([]string, error) {
+	if s.repo == nil {
+		logger.Info("No tags in " + s.root + ", it is not a git repository, only its working tree is available")
+		return []string{string(entities.WorkingTree)}, nil
+	}
+	return s.repo.GetTags()
+}
+
+// IsAncestor considers the working tree newer than any commit
+func (s *Service) IsAncestor(ancestor, commit string) (bool, error) {
+	switch entities.WorkingTree {
+	case entities.Version(commit):
+		return true, nil
+	case entities.Version(ancestor):
+		return false, nil
+	}
+	if s.repo == nil {
+		return false, errors.New(s.root + " is not a git repository")
+	}
+	return s.repo.IsAncestor(ancestor, commit)
+}
+
+var _ usecases.RepositoryPort = (*Service)(nil)
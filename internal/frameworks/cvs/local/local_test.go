@@ -0,0 +1,153 @@
+package local
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/cvs/git"
+)
+
+func TestIsLocal(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		uri      string
+		expected bool
+	}{
+		{uri: "file://" + dir, expected: true},
+		{uri: "file:///not/there", expected: true},
+		{uri: dir, expected: true},
+		{uri: filepath.Join(dir, "missing"), expected: false},
+		{uri: "https://github.com/user/repo", expected: false},
+		{uri: "git@github.com:user/repo.git", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			if res := IsLocal(tt.uri); res != tt.expected {
+				t.Errorf("IsLocal(%s) = %v, expected %v", tt.uri, res, tt.expected)
+			}
+		})
+	}
+}
+
+func TestService_PlainDirectory(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFactory(git.Factory)("file://"+dir, nil)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	if err = repo.Clone(); err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	defer repo.Clean()
+
+	if repo.Dir() != dir {
+		t.Errorf("Expected the working tree %s, got %s", dir, repo.Dir())
+	}
+	version, err := repo.Use(string(entities.WorkingTree))
+	if err != nil || version != string(entities.WorkingTree) {
+		t.Errorf("Use() = %s, %v", version, err)
+	}
+	if _, err = repo.Use("v1.0.0"); err == nil {
+		t.Errorf("Expected an error using a tag without git")
+	}
+	tags, err := repo.GetTags()
+	if err != nil || len(tags) != 1 || tags[0] != string(entities.WorkingTree) {
+		t.Errorf("GetTags() = %v, %v", tags, err)
+	}
+}
+
+func TestService_WorkingTreeDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "--quiet")
+	if err := os.Mkdir(filepath.Join(dir, "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write("main.go", "package main\n")
+	write("api/main.go", "package api\n")
+	run("add", "--all")
+	run("commit", "--quiet", "-m", "initial")
+	run("tag", "v1.0.0")
+
+	// uncommitted and untracked changes are part of the working tree
+	write("main.go", "package changed\n")
+	write("new.go", "package main\n")
+	write("api/new.go", "package api\n")
+
+	repo, err := NewFactory(git.Factory)(dir, nil)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	if err = repo.Clone(); err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	defer repo.Clean()
+
+	tags, err := repo.GetTags()
+	if err != nil || len(tags) != 1 || tags[0] != "v1.0.0" {
+		t.Errorf("GetTags() = %v, %v", tags, err)
+	}
+
+	if _, err = repo.Use(string(entities.WorkingTree)); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+	diff, err := repo.Diff("v1.0.0", "")
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	for _, expected := range []string{"+package changed", "+++ b/new.go"} {
+		if !strings.Contains(string(diff), expected) {
+			t.Errorf("Expected the diff to contain %q:\n%s", expected, diff)
+		}
+	}
+
+	// a template in a subdirectory only sees its files, relative to it
+	diff, err = repo.Diff("v1.0.0", "api")
+	if err != nil || !strings.Contains(string(diff), "+++ b/new.go") || strings.Contains(string(diff), "package changed") {
+		t.Errorf("Expected the diff of the subdirectory only, got %v:\n%s", err, diff)
+	}
+
+	// the index of the template is not touched
+	cmd := exec.Command("git", "status", "--porcelain")
+	cmd.Dir = dir
+	status, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(status), "?? new.go") {
+		t.Errorf("Expected new.go to stay untracked:\n%s", status)
+	}
+
+	// committed versions are read from the clone
+	if _, err = repo.Use("v1.0.0"); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+	if repo.Dir() == dir {
+		t.Errorf("Expected the clone to be used for a tag")
+	}
+	content, err := os.ReadFile(filepath.Join(repo.Dir(), "main.go"))
+	if err != nil || string(content) != "package main\n" {
+		t.Errorf("Expected the tagged main.go, got %q, %v", content, err)
+	}
+}
@@ -0,0 +1,384 @@
+package patch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type Service struct {
+	// fuzz is the number of context lines that can be ignored at each side of a hunk
+	fuzz int
+}
+
+func NewService(fuzz int) *Service {
+	return &Service{fuzz: fuzz}
+}
+
+// Apply applies the git diff over the directory, the hunks that could not be applied are stored in .rej files
+func (s *Service) Apply(dir string, content []byte) (*entities.PatchResult, error) {
+	files, err := Parse(content)
+	if err != nil {
+		logger.Error("Failed to parse patch", err)
+		return nil, err
+	}
+
+	res := &entities.PatchResult{Files: make([]*entities.PatchFileResult, 0)}
+	for _, file := range files {
+		fileRes, err := s.applyFile(dir, file)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to patch file %s", file.NewName), err)
+			return nil, err
+		}
+		res.Files = append(res.Files, fileRes)
+	}
+
+	logger.Info(fmt.Sprintf("Applied patch with %d rejected hunks", res.Rejected()))
+	return res, nil
+}
+
+func (s *Service) applyFile(dir string, file *FileDiff) (*entities.PatchFileResult, error) {
+	name := file.NewName
+	if file.Deleted {
+		name = file.OldName
+	}
+	res := &entities.PatchFileResult{File: entities.File("/" + name)}
+
+	// the names come from the template, they cannot leave the project
+	for _, fn := range []string{file.OldName, file.NewName} {
+		if !s.safe(fn) {
+			res.Message = "Ignoring potentially dangerous file name " + fn
+			s.reject(res, max(len(file.Hunks), 1))
+			return res, nil
+		}
+	}
+
+	if file.Binary {
+		res.Message = "binary patches are not supported"
+		s.reject(res, max(len(file.Hunks), 1))
+		return res, nil
+	}
+
+	// the source is the new name when the rename was already applied
+	source := file.OldName
+	if file.NewFile || !s.exists(dir, source) {
+		source = file.NewName
+	}
+	exists := s.exists(dir, source)
+
+	switch {
+	case !exists && file.Deleted:
+		return res, nil
+	case !exists && !file.NewFile:
+		res.Message = "No file to patch."
+		s.reject(res, len(file.Hunks))
+		return res, nil
+	case exists && file.NewFile:
+		return res, s.applyExisting(dir, file, res)
+	}
+
+	var old []byte
+	var err error
+	if exists {
+		old, err = os.ReadFile(filepath.Join(dir, source))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lines, rejected := s.applyHunks(splitLines(old), file.Hunks, res)
+	content := []byte(strings.Join(lines, ""))
+
+	// a deleted file with local changes is kept as it is
+	if file.Deleted && (len(rejected) > 0 || len(content) > 0) {
+		res.Hunks = res.Hunks[:0]
+		res.Message = "The file has local changes."
+		s.reject(res, len(file.Hunks))
+		return res, s.writeRejects(dir, file, file.Hunks, res)
+	}
+	if file.Deleted {
+		return res, s.remove(dir, source)
+	}
+
+	if len(rejected) > 0 {
+		err = s.writeRejects(dir, file, rejected, res)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// a change of mode alone does not need to rewrite the file
+	if len(file.Hunks) > 0 || source != file.NewName || !exists {
+		err = s.write(dir, file.NewName, content, s.perm(dir, source, file))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if file.Rename && source != file.NewName {
+		err = s.remove(dir, source)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, s.chmod(dir, file)
+}
+
+// applyExisting handles a new file that already exists, it is only accepted when both contents are the same
+func (s *Service) applyExisting(dir string, file *FileDiff, res *entities.PatchFileResult) error {
+	current, err := os.ReadFile(filepath.Join(dir, file.NewName))
+	if err != nil {
+		return err
+	}
+
+	expected := make([]string, 0)
+	for _, hunk := range file.Hunks {
+		_, new := hunk.sides()
+		expected = append(expected, new...)
+	}
+	if strings.Join(expected, "") == string(current) {
+		for n := range file.Hunks {
+			res.Hunks = append(res.Hunks, &entities.PatchHunkResult{Number: n + 1, Status: entities.HunkApplied, Line: 1})
+		}
+		return s.chmod(dir, file)
+	}
+
+	res.Message = "The file already exists."
+	s.reject(res, len(file.Hunks))
+	return s.writeRejects(dir, file, file.Hunks, res)
+}
+
+// applyHunks applies the hunks in order, the position of each one is moved by the previous ones
+func (s *Service) applyHunks(lines []string, hunks []*Hunk, res *entities.PatchFileResult) ([]string, []*Hunk) {
+	rejected := make([]*Hunk, 0)
+	drift, limit := 0, 0
+	for n, hunk := range hunks {
+		old, new := hunk.sides()
+		expected := hunk.OldStart - 1 + drift
+		if hunk.OldLines == 0 {
+			expected = hunk.OldStart + drift
+		}
+
+		pos, top, bottom, fuzz := s.locate(lines, old, expected, limit, s.leading(hunk), s.trailing(hunk))
+		if pos < 0 {
+			rejected = append(rejected, hunk)
+			res.Hunks = append(res.Hunks, &entities.PatchHunkResult{Number: n + 1, Status: entities.HunkRejected, Line: expected + 1})
+			continue
+		}
+
+		status := entities.HunkApplied
+		if fuzz > 0 || pos-top != expected {
+			status = entities.HunkFuzzed
+		}
+		res.Hunks = append(res.Hunks, &entities.PatchHunkResult{Number: n + 1, Status: status, Line: pos - top + 1})
+
+		replacement := new[top : len(new)-bottom]
+		updated := make([]string, 0, len(lines)+len(replacement))
+		updated = append(updated, lines[:pos]...)
+		updated = append(updated, replacement...)
+		updated = append(updated, lines[pos+len(old)-top-bottom:]...)
+		lines = updated
+
+		drift = pos - top - (hunk.OldStart - 1) + len(new) - len(old)
+		if hunk.OldLines == 0 {
+			drift = pos - hunk.OldStart + len(new) - len(old)
+		}
+		limit = pos + len(replacement)
+	}
+	return lines, rejected
+}
+
+// locate finds the closest position to expected where the hunk matches, ignoring up to fuzz context lines
+func (s *Service) locate(lines, old []string, expected, limit, leading, trailing int) (int, int, int, int) {
+	for fuzz := 0; fuzz <= s.fuzz; fuzz++ {
+		top, bottom := min(fuzz, leading), min(fuzz, trailing)
+		if fuzz > 0 && top < fuzz && bottom < fuzz {
+			break
+		}
+		if top+bottom > len(old) {
+			break
+		}
+		want := old[top : len(old)-bottom]
+		start := expected + top
+		for delta := 0; ; delta++ {
+			after, before := start+delta, start-delta
+			if after+len(want) > len(lines) && before < limit {
+				break
+			}
+			if after >= limit && after+len(want) <= len(lines) && s.matches(lines[after:], want) {
+				return after, top, bottom, fuzz
+			}
+			if delta > 0 && before >= limit && before+len(want) <= len(lines) && s.matches(lines[before:], want) {
+				return before, top, bottom, fuzz
+			}
+		}
+	}
+	return -1, 0, 0, 0
+}
+
+func (s *Service) matches(lines, want []string) bool {
+	for i := range want {
+		if lines[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Service) leading(hunk *Hunk) int {
+	count := 0
+	for _, line := range hunk.Lines {
+		if line.Kind != LineContext {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+func (s *Service) trailing(hunk *Hunk) int {
+	count := 0
+	for i := len(hunk.Lines) - 1; i >= 0 && hunk.Lines[i].Kind == LineContext; i-- {
+		count++
+	}
+	return count
+}
+
+func (s *Service) reject(res *entities.PatchFileResult, count int) {
+	for n := 1; n <= count; n++ {
+		res.Hunks = append(res.Hunks, &entities.PatchHunkResult{Number: n, Status: entities.HunkRejected})
+	}
+}
+
+// writeRejects stores the rejected hunks next to the file, as GNU patch does
+func (s *Service) writeRejects(dir string, file *FileDiff, hunks []*Hunk, res *entities.PatchFileResult) error {
+	name := file.NewName
+	if file.Deleted {
+		name = file.OldName
+	}
+	rejects := &FileDiff{OldName: file.OldName, NewName: file.NewName, NewFile: file.NewFile, Deleted: file.Deleted, Hunks: hunks}
+	var buf bytes.Buffer
+	_, _ = fmt.Fprintf(&buf, "--- %s\n", rejects.headerName("a/", rejects.OldName, rejects.NewFile))
+	_, _ = fmt.Fprintf(&buf, "+++ %s\n", rejects.headerName("b/", rejects.NewName, rejects.Deleted))
+	for _, hunk := range hunks {
+		hunk.write(&buf)
+	}
+
+	err := s.write(dir, name+".rej", buf.Bytes(), 0644)
+	if err != nil {
+		return err
+	}
+	res.RejectFile = entities.File("/" + name + ".rej")
+	return nil
+}
+
+func (s *Service) write(dir, name string, content []byte, perm os.FileMode) error {
+	fn := filepath.Join(dir, name)
+	err := os.MkdirAll(filepath.Dir(fn), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fn, content, perm)
+}
+
+// remove deletes the file and the parent directories left empty
+func (s *Service) remove(dir, name string) error {
+	err := os.Remove(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	for parent := filepath.Dir(name); parent != "." && parent != "/"; parent = filepath.Dir(parent) {
+		if os.Remove(filepath.Join(dir, parent)) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// perm keeps the permissions of the patched file unless the patch sets a new mode
+func (s *Service) perm(dir, source string, file *FileDiff) os.FileMode {
+	if mode, ok := s.mode(file.NewMode); ok {
+		return mode
+	}
+	info, err := os.Stat(filepath.Join(dir, source))
+	if err != nil {
+		return 0644
+	}
+	return info.Mode().Perm()
+}
+
+func (s *Service) chmod(dir string, file *FileDiff) error {
+	mode, ok := s.mode(file.NewMode)
+	if !ok || file.Deleted {
+		return nil
+	}
+	return os.Chmod(filepath.Join(dir, file.NewName), mode)
+}
+
+// mode converts git modes, only the permissions of regular files are used
+func (s *Service) mode(value string) (os.FileMode, bool) {
+	if !strings.HasPrefix(value, "100") {
+		return 0, false
+	}
+	mode, err := strconv.ParseUint(value[3:], 8, 32)
+	if err != nil {
+		return 0, false
+	}
+	return os.FileMode(mode), true
+}
+
+// safe refuses the absolute names and the names going up from the directory, as GNU patch does
+func (s *Service) safe(name string) bool {
+	if name == "" {
+		return true
+	}
+	if strings.HasPrefix(name, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return false
+	}
+	clean := filepath.Clean(filepath.FromSlash(name))
+	return clean != "." && clean != ".." && !strings.HasPrefix(clean, ".."+string(filepath.Separator))
+}
+
+func (s *Service) exists(dir, name string) bool {
+	if name == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(dir, name))
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// sides returns the lines the hunk expects and the lines it writes, with their terminators
+func (hunk *Hunk) sides() ([]string, []string) {
+	old := make([]string, 0, hunk.OldLines)
+	new := make([]string, 0, hunk.NewLines)
+	for _, line := range hunk.Lines {
+		text := line.Text + "\n"
+		if line.NoNewline {
+			text = line.Text
+		}
+		if line.Kind != LineInsert {
+			old = append(old, text)
+		}
+		if line.Kind != LineDelete {
+			new = append(new, text)
+		}
+	}
+	return old, new
+}
+
+// splitLines splits the content keeping the line terminators
+func splitLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+var _ usecases.PatchPort = (*Service)(nil)
@@ -0,0 +1,249 @@
+package patch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+func TestService_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		patch    string
+		expected map[string]string
+		missing  []string
+		hunks    []entities.HunkStatus
+		message  string
+	}{
+		{
+			name:  "modify file",
+			files: map[string]string{"a.txt": "1\n2\n3\n"},
+			patch: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n",
+			expected: map[string]string{
+				"a.txt": "1\ntwo\n3\n",
+			},
+			hunks: []entities.HunkStatus{entities.HunkApplied},
+		},
+		{
+			name:  "hunk moved by local lines",
+			files: map[string]string{"a.txt": "local\n1\n2\n3\n"},
+			patch: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n",
+			expected: map[string]string{
+				"a.txt": "local\n1\ntwo\n3\n",
+			},
+			hunks: []entities.HunkStatus{entities.HunkFuzzed},
+		},
+		{
+			name:  "context changed locally needs fuzz",
+			files: map[string]string{"a.txt": "one\n2\n3\n"},
+			patch: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n",
+			expected: map[string]string{
+				"a.txt": "one\ntwo\n3\n",
+			},
+			hunks: []entities.HunkStatus{entities.HunkFuzzed},
+		},
+		{
+			name:  "second hunk uses the offset of the first one",
+			files: map[string]string{"a.txt": "1\n2\n3\n4\n5\n6\n7\n8\n"},
+			patch: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n" +
+				"@@ -1,2 +1,3 @@\n 1\n+1.5\n 2\n" +
+				"@@ -7,2 +8,2 @@\n 7\n-8\n+eight\n",
+			expected: map[string]string{
+				"a.txt": "1\n1.5\n2\n3\n4\n5\n6\n7\neight\n",
+			},
+			hunks: []entities.HunkStatus{entities.HunkApplied, entities.HunkApplied},
+		},
+		{
+			name:  "rejected hunk is saved",
+			files: map[string]string{"a.txt": "1\nlocal\n3\n"},
+			patch: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n",
+			expected: map[string]string{
+				"a.txt":     "1\nlocal\n3\n",
+				"a.txt.rej": "--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n",
+			},
+			hunks: []entities.HunkStatus{entities.HunkRejected},
+		},
+		{
+			name:  "new file without newline",
+			files: map[string]string{},
+			patch: "diff --git a/dir/new.txt b/dir/new.txt\nnew file mode 100644\n--- /dev/null\n+++ b/dir/new.txt\n@@ -0,0 +1,2 @@\n+a\n+b\n\\ No newline at end of file\n",
+			expected: map[string]string{
+				"dir/new.txt": "a\nb",
+			},
+			hunks: []entities.HunkStatus{entities.HunkApplied},
+		},
+		{
+			name:    "new file over a different existing one",
+			files:   map[string]string{"new.txt": "mine\n"},
+			patch:   "diff --git a/new.txt b/new.txt\nnew file mode 100644\n--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1 @@\n+theirs\n",
+			message: "The file already exists.",
+			expected: map[string]string{
+				"new.txt":     "mine\n",
+				"new.txt.rej": "--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1 @@\n+theirs\n",
+			},
+			hunks: []entities.HunkStatus{entities.HunkRejected},
+		},
+		{
+			name:    "deleted file",
+			files:   map[string]string{"dir/old.txt": "a\n", "keep.txt": "k\n"},
+			patch:   "diff --git a/dir/old.txt b/dir/old.txt\ndeleted file mode 100644\n--- a/dir/old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-a\n",
+			missing: []string{"dir/old.txt", "dir"},
+			hunks:   []entities.HunkStatus{entities.HunkApplied},
+		},
+		{
+			name:    "deleted file with local changes is kept",
+			files:   map[string]string{"old.txt": "a\nlocal\n"},
+			patch:   "diff --git a/old.txt b/old.txt\ndeleted file mode 100644\n--- a/old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-a\n",
+			message: "The file has local changes.",
+			expected: map[string]string{
+				"old.txt": "a\nlocal\n",
+			},
+			hunks: []entities.HunkStatus{entities.HunkRejected},
+		},
+		{
+			name:  "renamed file with changes",
+			files: map[string]string{"old name.txt": "1\n2\n"},
+			patch: "diff --git a/old name.txt b/new name.txt\nsimilarity index 50%\nrename from old name.txt\nrename to new name.txt\n" +
+				"--- a/old name.txt\t\n+++ b/new name.txt\t\n@@ -1,2 +1,2 @@\n 1\n-2\n+two\n",
+			expected: map[string]string{
+				"new name.txt": "1\ntwo\n",
+			},
+			missing: []string{"old name.txt"},
+			hunks:   []entities.HunkStatus{entities.HunkApplied},
+		},
+		{
+			name:    "missing file",
+			files:   map[string]string{},
+			patch:   "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-1\n+one\n",
+			message: "No file to patch.",
+			missing: []string{"a.txt"},
+			hunks:   []entities.HunkStatus{entities.HunkRejected},
+		},
+		{
+			name:    "binary patch",
+			files:   map[string]string{},
+			patch:   "diff --git a/a.bin b/a.bin\nindex 1234567..89abcde 100644\nBinary files a/a.bin and b/a.bin differ\n",
+			message: "binary patches are not supported",
+			hunks:   []entities.HunkStatus{entities.HunkRejected},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				fn := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			res, err := NewService(3).Apply(dir, []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+
+			if len(res.Files) != 1 {
+				t.Fatalf("Expected 1 file result, got %d", len(res.Files))
+			}
+			if res.Files[0].Message != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, res.Files[0].Message)
+			}
+			if len(res.Files[0].Hunks) != len(tt.hunks) {
+				t.Fatalf("Expected %d hunks, got %d", len(tt.hunks), len(res.Files[0].Hunks))
+			}
+			for i, hunk := range res.Files[0].Hunks {
+				if hunk.Status != tt.hunks[i] {
+					t.Errorf("Expected hunk #%d to be %s, got %s", i+1, tt.hunks[i], hunk.Status)
+				}
+			}
+
+			for name, content := range tt.expected {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Errorf("Expected file %s: %v", name, err)
+					continue
+				}
+				if string(data) != content {
+					t.Errorf("Expected %s to be %q, got %q", name, content, data)
+				}
+			}
+			for _, name := range tt.missing {
+				if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+					t.Errorf("Expected %s to be removed", name)
+				}
+			}
+		})
+	}
+}
+
+func TestService_ApplyMode(t *testing.T) {
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "run.sh")
+	if err := os.WriteFile(fn, []byte("echo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewService(3).Apply(dir, []byte("diff --git a/run.sh b/run.sh\nold mode 100644\nnew mode 100755\n"))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	info, err := os.Stat(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("Expected mode 0755, got %o", info.Mode().Perm())
+	}
+}
+
+func TestService_ApplyUnsafeNames(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "project")
+	victim := filepath.Join(parent, "victim.txt")
+	for fn, content := range map[string]string{victim: "1\n", filepath.Join(dir, "a.txt"): "1\n"} {
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	absolute := filepath.ToSlash(filepath.Join(parent, "absolute.txt"))
+
+	patch := "diff --git a/../escape.txt b/../escape.txt\nnew file mode 100644\n--- /dev/null\n+++ b/../escape.txt\n@@ -0,0 +1 @@\n+x\n" +
+		"diff --git a/../victim.txt b/../victim.txt\ndeleted file mode 100644\n--- a/../victim.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-1\n" +
+		"diff --git a/" + absolute + " b/" + absolute + "\nnew file mode 100644\n--- /dev/null\n+++ b/" + absolute + "\n@@ -0,0 +1 @@\n+x\n" +
+		"diff --git a/a.txt b/sub/../../renamed.txt\nsimilarity index 100%\nrename from a.txt\nrename to sub/../../renamed.txt\n"
+
+	res, err := NewService(3).Apply(dir, []byte(patch))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(res.Files) != 4 || res.Rejected() != 4 {
+		t.Fatalf("Expected 4 rejected files, got %d files and %d rejected hunks", len(res.Files), res.Rejected())
+	}
+	for _, file := range res.Files {
+		if file.Message == "" || file.RejectFile != "" {
+			t.Errorf("Expected %s to be refused without a .rej file, got %+v", file.File, file)
+		}
+	}
+
+	for _, fn := range []string{filepath.Join(parent, "escape.txt"), filepath.Join(parent, "absolute.txt"), filepath.Join(parent, "renamed.txt")} {
+		if _, err := os.Stat(fn); err == nil {
+			t.Errorf("Expected %s not to be written", fn)
+		}
+	}
+	for _, fn := range []string{victim, filepath.Join(dir, "a.txt")} {
+		if _, err := os.Stat(fn); err != nil {
+			t.Errorf("Expected %s to be kept: %v", fn, err)
+		}
+	}
+}
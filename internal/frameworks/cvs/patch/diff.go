@@ -0,0 +1,442 @@
+package patch
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// https://git-scm.com/docs/diff-format
+const devNull = "/dev/null"
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
+
+type LineKind byte
+
+const (
+	LineContext LineKind = ' '
+	LineDelete  LineKind = '-'
+	LineInsert  LineKind = '+'
+)
+
+type Line struct {
+	Kind LineKind
+	// Text is the line content without the line terminator
+	Text string
+	// NoNewline is set for the last line of a file without a line terminator
+	NoNewline bool
+}
+
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Section is the text after the hunk range, usually the enclosing function
+	Section string
+	Lines   []*Line
+}
+
+// FileDiff is a single file of a git diff, names do not include the a/ and b/ prefixes
+type FileDiff struct {
+	OldName string
+	NewName string
+	OldMode string
+	NewMode string
+	NewFile bool
+	Deleted bool
+	Rename  bool
+	Copy    bool
+	// Similarity is the raw value of the similarity or dissimilarity index
+	Similarity   string
+	Dissimilar   bool
+	Index        string
+	Binary       bool
+	BinaryHeader string
+	Hunks        []*Hunk
+}
+
+// Parse reads a patch in the git diff format
+func Parse(content []byte) ([]*FileDiff, error) {
+	files := make([]*FileDiff, 0)
+	lines := strings.Split(string(content), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var file *FileDiff
+	var err error
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.HasPrefix(line, "diff --git ") {
+			file = &FileDiff{}
+			file.OldName, file.NewName, err = parseGitNames(line[len("diff --git "):])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			files = append(files, file)
+			continue
+		}
+
+		if file == nil {
+			// anything before the first file is ignored, as git does with commit messages
+			continue
+		}
+
+		if strings.HasPrefix(line, "@@ ") {
+			var hunk *Hunk
+			hunk, i, err = parseHunk(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			file.Hunks = append(file.Hunks, hunk)
+			continue
+		}
+
+		// extended headers always come before the hunks
+		if len(file.Hunks) > 0 {
+			if line == "" {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: unexpected content after hunk: %q", i+1, line)
+		}
+
+		err = parseExtendedHeader(file, line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+
+	return files, nil
+}
+
+func parseExtendedHeader(file *FileDiff, line string) error {
+	var err error
+	switch {
+	case strings.HasPrefix(line, "old mode "):
+		file.OldMode = line[len("old mode "):]
+	case strings.HasPrefix(line, "new mode "):
+		file.NewMode = line[len("new mode "):]
+	case strings.HasPrefix(line, "deleted file mode "):
+		file.Deleted = true
+		file.OldMode = line[len("deleted file mode "):]
+	case strings.HasPrefix(line, "new file mode "):
+		file.NewFile = true
+		file.NewMode = line[len("new file mode "):]
+	case strings.HasPrefix(line, "similarity index "):
+		file.Similarity = line[len("similarity index "):]
+	case strings.HasPrefix(line, "dissimilarity index "):
+		file.Similarity = line[len("dissimilarity index "):]
+		file.Dissimilar = true
+	case strings.HasPrefix(line, "rename from "):
+		file.Rename = true
+		file.OldName, err = parseName(line[len("rename from "):])
+	case strings.HasPrefix(line, "rename to "):
+		file.Rename = true
+		file.NewName, err = parseName(line[len("rename to "):])
+	case strings.HasPrefix(line, "copy from "):
+		file.Copy = true
+		file.OldName, err = parseName(line[len("copy from "):])
+	case strings.HasPrefix(line, "copy to "):
+		file.Copy = true
+		file.NewName, err = parseName(line[len("copy to "):])
+	case strings.HasPrefix(line, "index "):
+		file.Index = line[len("index "):]
+	case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+		file.Binary = true
+		file.BinaryHeader = line
+	case strings.HasPrefix(line, "--- "):
+		file.OldName, err = parsePatchName(line[len("--- "):], file.OldName)
+	case strings.HasPrefix(line, "+++ "):
+		file.NewName, err = parsePatchName(line[len("+++ "):], file.NewName)
+	case file.Binary:
+		// the content of binary patches is kept out of the model
+	default:
+		err = fmt.Errorf("unknown extended header: %q", line)
+	}
+	return err
+}
+
+// parseHunk reads the hunk starting at lines[start] and returns the index of its last line
+func parseHunk(lines []string, start int) (*Hunk, int, error) {
+	groups := hunkHeader.FindStringSubmatch(lines[start])
+	if groups == nil {
+		return nil, start, fmt.Errorf("line %d: invalid hunk header: %q", start+1, lines[start])
+	}
+
+	hunk := &Hunk{
+		OldStart: atoi(groups[1], 0),
+		OldLines: atoi(groups[2], 1),
+		NewStart: atoi(groups[3], 0),
+		NewLines: atoi(groups[4], 1),
+		Section:  groups[5],
+	}
+
+	// the counters tell where the hunk ends, so lines looking like headers are still content
+	old, new := hunk.OldLines, hunk.NewLines
+	i := start + 1
+	for ; i < len(lines) && (old > 0 || new > 0 || strings.HasPrefix(lines[i], "\\")); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "\\") {
+			if len(hunk.Lines) == 0 {
+				return nil, i, fmt.Errorf("line %d: missing line before %q", i+1, line)
+			}
+			hunk.Lines[len(hunk.Lines)-1].NoNewline = true
+			continue
+		}
+
+		// some editors remove the space of empty context lines
+		kind := LineContext
+		text := ""
+		if line != "" {
+			kind = LineKind(line[0])
+			text = line[1:]
+		}
+
+		switch kind {
+		case LineContext:
+			old--
+			new--
+		case LineDelete:
+			old--
+		case LineInsert:
+			new--
+		default:
+			return nil, i, fmt.Errorf("line %d: invalid hunk line: %q", i+1, line)
+		}
+		if old < 0 || new < 0 {
+			return nil, i, fmt.Errorf("line %d: hunk longer than its header", i+1)
+		}
+		hunk.Lines = append(hunk.Lines, &Line{Kind: kind, Text: text})
+	}
+
+	if old > 0 || new > 0 {
+		return nil, i, fmt.Errorf("line %d: truncated hunk", i+1)
+	}
+	return hunk, i - 1, nil
+}
+
+// parseGitNames splits the names in the `diff --git` line, unquoted names may contain spaces
+func parseGitNames(value string) (string, string, error) {
+	if strings.HasPrefix(value, "\"") {
+		old, rest, err := unquotePrefix(value)
+		if err != nil {
+			return "", "", err
+		}
+		rest = strings.TrimPrefix(rest, " ")
+		new, err := parseName(rest)
+		if err != nil {
+			return "", "", err
+		}
+		return stripPrefix(old), stripPrefix(new), nil
+	}
+
+	if i := strings.Index(value, " \""); i >= 0 && strings.HasSuffix(value, "\"") {
+		new, err := parseName(value[i+1:])
+		if err != nil {
+			return "", "", err
+		}
+		return stripPrefix(value[:i]), stripPrefix(new), nil
+	}
+
+	// both names are the same when the file is not renamed
+	if len(value)%2 == 1 {
+		half := len(value) / 2
+		if value[half] == ' ' && stripPrefix(value[:half]) == stripPrefix(value[half+1:]) {
+			return stripPrefix(value[:half]), stripPrefix(value[half+1:]), nil
+		}
+	}
+
+	if i := strings.Index(value, " b/"); i >= 0 {
+		return stripPrefix(value[:i]), stripPrefix(value[i+1:]), nil
+	}
+	return "", "", fmt.Errorf("invalid diff header: %q", value)
+}
+
+// parsePatchName reads the name of the ---/+++ lines, /dev/null keeps the name from the git header
+func parsePatchName(value, current string) (string, error) {
+	if strings.HasPrefix(value, "\"") {
+		name, _, err := unquotePrefix(value)
+		if err != nil {
+			return "", err
+		}
+		return stripPrefix(name), nil
+	}
+
+	// git adds a tab after names with spaces, other tools add a timestamp
+	if i := strings.Index(value, "\t"); i >= 0 {
+		value = value[:i]
+	}
+	if value == devNull {
+		return current, nil
+	}
+	return stripPrefix(value), nil
+}
+
+func parseName(value string) (string, error) {
+	if strings.HasPrefix(value, "\"") {
+		name, _, err := unquotePrefix(value)
+		return name, err
+	}
+	return value, nil
+}
+
+// unquotePrefix unquotes the C-style quoted name at the start of the value
+func unquotePrefix(value string) (string, string, error) {
+	for i := 1; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == '"' {
+			name, err := strconv.Unquote(value[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid quoted name %s: %w", value[:i+1], err)
+			}
+			return name, value[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quoted name: %s", value)
+}
+
+// stripPrefix removes the a/ or b/ prefix, as `patch -p1` does
+func stripPrefix(name string) string {
+	if i := strings.Index(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func atoi(value string, def int) int {
+	if value == "" {
+		return def
+	}
+	n, _ := strconv.Atoi(value)
+	return n
+}
+
+// Format writes the files back in the git diff format
+func Format(files []*FileDiff) []byte {
+	var buf bytes.Buffer
+	for _, file := range files {
+		file.write(&buf)
+	}
+	return buf.Bytes()
+}
+
+func (file *FileDiff) write(buf *bytes.Buffer) {
+	_, _ = fmt.Fprintf(buf, "diff --git %s %s\n", quoteName("a/"+file.OldName), quoteName("b/"+file.NewName))
+
+	switch {
+	case file.Deleted:
+		_, _ = fmt.Fprintf(buf, "deleted file mode %s\n", file.OldMode)
+	case file.NewFile:
+		_, _ = fmt.Fprintf(buf, "new file mode %s\n", file.NewMode)
+	case file.OldMode != "" || file.NewMode != "":
+		_, _ = fmt.Fprintf(buf, "old mode %s\nnew mode %s\n", file.OldMode, file.NewMode)
+	}
+
+	if file.Similarity != "" {
+		if file.Dissimilar {
+			_, _ = fmt.Fprintf(buf, "dissimilarity index %s\n", file.Similarity)
+		} else {
+			_, _ = fmt.Fprintf(buf, "similarity index %s\n", file.Similarity)
+		}
+	}
+	if file.Rename {
+		_, _ = fmt.Fprintf(buf, "rename from %s\nrename to %s\n", quoteName(file.OldName), quoteName(file.NewName))
+	}
+	if file.Copy {
+		_, _ = fmt.Fprintf(buf, "copy from %s\ncopy to %s\n", quoteName(file.OldName), quoteName(file.NewName))
+	}
+	if file.Index != "" {
+		_, _ = fmt.Fprintf(buf, "index %s\n", file.Index)
+	}
+
+	if file.Binary {
+		_, _ = fmt.Fprintf(buf, "Binary files %s and %s differ\n", file.patchName("a/", file.OldName, file.NewFile), file.patchName("b/", file.NewName, file.Deleted))
+		return
+	}
+
+	if len(file.Hunks) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(buf, "--- %s\n", file.headerName("a/", file.OldName, file.NewFile))
+	_, _ = fmt.Fprintf(buf, "+++ %s\n", file.headerName("b/", file.NewName, file.Deleted))
+	for _, hunk := range file.Hunks {
+		hunk.write(buf)
+	}
+}
+
+func (file *FileDiff) patchName(prefix, name string, missing bool) string {
+	if missing {
+		return devNull
+	}
+	return quoteName(prefix + name)
+}
+
+// headerName adds the tab git writes after names with spaces
+func (file *FileDiff) headerName(prefix, name string, missing bool) string {
+	res := file.patchName(prefix, name, missing)
+	if strings.Contains(res, " ") && !strings.HasPrefix(res, "\"") {
+		res += "\t"
+	}
+	return res
+}
+
+func (hunk *Hunk) write(buf *bytes.Buffer) {
+	_, _ = fmt.Fprintf(buf, "@@ -%s +%s @@%s\n", hunkRange(hunk.OldStart, hunk.OldLines), hunkRange(hunk.NewStart, hunk.NewLines), hunk.Section)
+	for _, line := range hunk.Lines {
+		buf.WriteByte(byte(line.Kind))
+		buf.WriteString(line.Text)
+		buf.WriteByte('\n')
+		if line.NoNewline {
+			buf.WriteString("\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// quoteName quotes the names with special characters the same way git does
+func quoteName(name string) string {
+	quote := false
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c < 0x20 || c >= 0x7f || c == '"' || c == '\\' {
+			quote = true
+			break
+		}
+	}
+	if !quote {
+		return name
+	}
+
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '"' || c == '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c == '\t':
+			buf.WriteString("\\t")
+		case c == '\n':
+			buf.WriteString("\\n")
+		case c < 0x20 || c >= 0x7f:
+			_, _ = fmt.Fprintf(&buf, "\\%03o", c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
@@ -0,0 +1,146 @@
+package patch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	hunk := &Hunk{OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 2, Section: " func main() {", Lines: []*Line{
+		{Kind: LineContext, Text: "a"},
+		{Kind: LineDelete, Text: "b"},
+		{Kind: LineInsert, Text: "c"},
+	}}
+
+	tests := []struct {
+		name     string
+		patch    string
+		expected *FileDiff
+	}{
+		{
+			name:     "modified file",
+			patch:    "diff --git a/main.go b/main.go\nindex 1234567..89abcde 100644\n--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@ func main() {\n a\n-b\n+c\n",
+			expected: &FileDiff{OldName: "main.go", NewName: "main.go", Index: "1234567..89abcde 100644", Hunks: []*Hunk{hunk}},
+		},
+		{
+			name:  "new file mode",
+			patch: "diff --git a/dir/new.go b/dir/new.go\nnew file mode 100644\nindex 0000000..89abcde\n--- /dev/null\n+++ b/dir/new.go\n@@ -0,0 +1 @@\n+a\n",
+			expected: &FileDiff{OldName: "dir/new.go", NewName: "dir/new.go", NewMode: "100644", NewFile: true, Index: "0000000..89abcde", Hunks: []*Hunk{
+				{OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 1, Lines: []*Line{{Kind: LineInsert, Text: "a"}}},
+			}},
+		},
+		{
+			name:  "deleted file mode",
+			patch: "diff --git a/old.go b/old.go\ndeleted file mode 100755\nindex 1234567..0000000\n--- a/old.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-a\n",
+			expected: &FileDiff{OldName: "old.go", NewName: "old.go", OldMode: "100755", Deleted: true, Index: "1234567..0000000", Hunks: []*Hunk{
+				{OldStart: 1, OldLines: 1, NewStart: 0, NewLines: 0, Lines: []*Line{{Kind: LineDelete, Text: "a"}}},
+			}},
+		},
+		{
+			name:     "old and new mode",
+			patch:    "diff --git a/run.sh b/run.sh\nold mode 100644\nnew mode 100755\n",
+			expected: &FileDiff{OldName: "run.sh", NewName: "run.sh", OldMode: "100644", NewMode: "100755"},
+		},
+		{
+			name:     "rename without changes",
+			patch:    "diff --git a/a.go b/b.go\nsimilarity index 100%\nrename from a.go\nrename to b.go\n",
+			expected: &FileDiff{OldName: "a.go", NewName: "b.go", Rename: true, Similarity: "100%"},
+		},
+		{
+			name:     "rename with changes",
+			patch:    "diff --git a/a.go b/src/b.go\nsimilarity index 80%\nrename from a.go\nrename to src/b.go\nindex 1234567..89abcde 100644\n--- a/a.go\n+++ b/src/b.go\n@@ -1,2 +1,2 @@ func main() {\n a\n-b\n+c\n",
+			expected: &FileDiff{OldName: "a.go", NewName: "src/b.go", Rename: true, Similarity: "80%", Index: "1234567..89abcde 100644", Hunks: []*Hunk{hunk}},
+		},
+		{
+			name:     "copied file",
+			patch:    "diff --git a/a.go b/c.go\nsimilarity index 100%\ncopy from a.go\ncopy to c.go\n",
+			expected: &FileDiff{OldName: "a.go", NewName: "c.go", Copy: true, Similarity: "100%"},
+		},
+		{
+			name:     "dissimilarity index",
+			patch:    "diff --git a/main.go b/main.go\ndissimilarity index 90%\nindex 1234567..89abcde 100644\n--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@ func main() {\n a\n-b\n+c\n",
+			expected: &FileDiff{OldName: "main.go", NewName: "main.go", Similarity: "90%", Dissimilar: true, Index: "1234567..89abcde 100644", Hunks: []*Hunk{hunk}},
+		},
+		{
+			name:     "binary file",
+			patch:    "diff --git a/logo.png b/logo.png\nindex 1234567..89abcde 100644\nBinary files a/logo.png and b/logo.png differ\n",
+			expected: &FileDiff{OldName: "logo.png", NewName: "logo.png", Index: "1234567..89abcde 100644", Binary: true, BinaryHeader: "Binary files a/logo.png and b/logo.png differ"},
+		},
+		{
+			name:     "new binary file",
+			patch:    "diff --git a/logo.png b/logo.png\nnew file mode 100644\nindex 0000000..89abcde\nBinary files /dev/null and b/logo.png differ\n",
+			expected: &FileDiff{OldName: "logo.png", NewName: "logo.png", NewMode: "100644", NewFile: true, Index: "0000000..89abcde", Binary: true, BinaryHeader: "Binary files /dev/null and b/logo.png differ"},
+		},
+		{
+			name:  "names with spaces",
+			patch: "diff --git a/my file.txt b/my file.txt\n--- a/my file.txt\t\n+++ b/my file.txt\t\n@@ -1 +1 @@\n-a\n+b\n",
+			expected: &FileDiff{OldName: "my file.txt", NewName: "my file.txt", Hunks: []*Hunk{
+				{OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1, Lines: []*Line{{Kind: LineDelete, Text: "a"}, {Kind: LineInsert, Text: "b"}}},
+			}},
+		},
+		{
+			name:     "quoted names",
+			patch:    "diff --git \"a/caf\\303\\251.txt\" \"b/tab\\there.txt\"\nsimilarity index 100%\nrename from \"caf\\303\\251.txt\"\nrename to \"tab\\there.txt\"\n",
+			expected: &FileDiff{OldName: "café.txt", NewName: "tab\there.txt", Rename: true, Similarity: "100%"},
+		},
+		{
+			name:  "no newline at end of file",
+			patch: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n",
+			expected: &FileDiff{OldName: "a.txt", NewName: "a.txt", Hunks: []*Hunk{
+				{OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1, Lines: []*Line{{Kind: LineDelete, Text: "a", NoNewline: true}, {Kind: LineInsert, Text: "a"}}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := Parse([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(files) != 1 {
+				t.Fatalf("Expected 1 file, got %d", len(files))
+			}
+			if !reflect.DeepEqual(files[0], tt.expected) {
+				t.Errorf("Expected %+v, got %+v", *tt.expected, *files[0])
+			}
+
+			// the parsed model writes the same patch back
+			if formatted := string(Format(files)); formatted != tt.patch {
+				t.Errorf("Expected formatted patch %q, got %q", tt.patch, formatted)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{
+			name:  "unknown header",
+			patch: "diff --git a/a.txt b/a.txt\nsomething else\n",
+		},
+		{
+			name:  "truncated hunk",
+			patch: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n a\n",
+		},
+		{
+			name:  "invalid hunk line",
+			patch: "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n*a\n",
+		},
+		{
+			name:  "unterminated quoted name",
+			patch: "diff --git \"a/a.txt b/a.txt\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.patch)); err == nil {
+				t.Errorf("Expected an error parsing %q", tt.patch)
+			}
+		})
+	}
+}
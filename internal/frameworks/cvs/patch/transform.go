@@ -0,0 +1,101 @@
+package patch
+
+import (
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"strings"
+)
+
+type TransformService struct {
+	engine usecases.SombraEngineCase
+}
+
+func NewTransformService(engine usecases.SombraEngineCase) *TransformService {
+	return &TransformService{engine: engine}
+}
+
+// Transform keeps the files matched by the patterns and rewrites their names and lines with the mappings
+func (s *TransformService) Transform(content []byte, patterns []*entities.Pattern) ([]byte, []*entities.FileChange, error) {
+	files, err := Parse(content)
+	if err != nil {
+		logger.Error("Failed to parse template diff", err)
+		return nil, nil, err
+	}
+
+	res := make([]*FileDiff, 0, len(files))
+	changes := make([]*entities.FileChange, 0, len(files))
+	for _, file := range files {
+		// NOTE: sombra names start with '/' because they work in the same way that gitignore works
+		isMatch, all, err := s.engine.Match(entities.File("/"+file.OldName), patterns)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to match file %s", file.OldName), err)
+			return nil, nil, err
+		}
+		if !isMatch {
+			continue
+		}
+
+		// the Go templates cannot be rendered hunk by hunk, the plan tells which files are left behind
+		mappings := s.engine.Combine(all)
+		if mappings.Render {
+			logger.Info(fmt.Sprintf("Skipping %s, the rendered files are not updated by the diff method", file.OldName))
+			changes = append(changes, &entities.FileChange{
+				Operation: entities.FileSkip,
+				File:      entities.File("/" + s.name(file.NewName, mappings)),
+				Reason:    "rendered file, the diff method cannot update it, use the merge method",
+			})
+			continue
+		}
+
+		s.transformFile(file, mappings)
+		res = append(res, file)
+		changes = append(changes, s.change(file))
+	}
+
+	return Format(res), changes, nil
+}
+
+func (s *TransformService) transformFile(file *FileDiff, mappings *entities.MapResult) {
+	file.OldName = s.name(file.OldName, mappings)
+	file.NewName = s.name(file.NewName, mappings)
+
+	// binary files only carry their names
+	for _, hunk := range file.Hunks {
+		hunk.Section = s.content(file.OldName, hunk.Section, mappings)
+		for _, line := range hunk.Lines {
+			line.Text = s.content(file.OldName, line.Text, mappings)
+		}
+	}
+}
+
+func (s *TransformService) name(name string, mappings *entities.MapResult) string {
+	newFile := s.engine.NewFile(entities.File("/"+name), mappings.Path, mappings.Name)
+	return strings.TrimPrefix(string(newFile), "/")
+}
+
+func (s *TransformService) content(name, text string, mappings *entities.MapResult) string {
+	if text == "" {
+		return text
+	}
+	return string(s.engine.NewContent(entities.File("/"+name), []byte(text), mappings.Content))
+}
+
+// change describes the file operation using the extended headers
+func (s *TransformService) change(file *FileDiff) *entities.FileChange {
+	change := &entities.FileChange{Operation: entities.FileModify, File: entities.File("/" + file.NewName)}
+	switch {
+	case file.NewFile || file.Copy:
+		change.Operation = entities.FileCreate
+	case file.Deleted:
+		change.Operation = entities.FileDelete
+		change.File = entities.File("/" + file.OldName)
+	case file.Rename:
+		change.Operation = entities.FileRename
+		change.From = entities.File("/" + file.OldName)
+	}
+	return change
+}
+
+var _ usecases.PatchTransformPort = (*TransformService)(nil)
@@ -0,0 +1,132 @@
+package patch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+// fakeEngine replaces acme with foo in the names and the content, files under /ignored do not match and
+// files under /rendered are Go templates
+type fakeEngine struct{}
+
+func (e *fakeEngine) Match(file entities.File, mappings []*entities.Pattern) (bool, []*entities.Pattern, error) {
+	if strings.HasPrefix(string(file), "/ignored/") {
+		return false, nil, nil
+	}
+	if strings.HasPrefix(string(file), "/rendered/") {
+		return true, []*entities.Pattern{{Pattern: "/rendered/**", Render: true}}, nil
+	}
+	return true, mappings, nil
+}
+
+func (e *fakeEngine) Skipped(file entities.File, mappings []*entities.Pattern) (bool, error) {
+	return false, nil
+}
+
+func (e *fakeEngine) RenderContent(file entities.File, content []byte, items *entities.MapResult, vars entities.Mappings) ([]byte, error) {
+	return e.NewContent(file, content, items.Content), nil
+}
+
+func (e *fakeEngine) Combine(patterns []*entities.Pattern) *entities.MapResult {
+	for _, pattern := range patterns {
+		if pattern.Render {
+			return &entities.MapResult{Render: true}
+		}
+	}
+	return &entities.MapResult{}
+}
+
+func (e *fakeEngine) NewFile(file entities.File, paths entities.MapList, names entities.MapList) entities.File {
+	return entities.File(strings.ReplaceAll(string(file), "acme", "foo"))
+}
+
+func (e *fakeEngine) NewContent(file entities.File, content []byte, mappings entities.MapList) []byte {
+	return []byte(strings.ReplaceAll(string(content), "acme", "foo"))
+}
+
+func TestTransformService_Transform(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    string
+		expected string
+		changes  []*entities.FileChange
+	}{
+		{
+			name:     "context, section and changed lines are mapped",
+			patch:    "diff --git a/acme/main.go b/acme/main.go\n--- a/acme/main.go\n+++ b/acme/main.go\n@@ -1,2 +1,2 @@ package acme\n package acme\n-var a = \"acme\"\n+var b = \"acme\"\n",
+			expected: "diff --git a/foo/main.go b/foo/main.go\n--- a/foo/main.go\n+++ b/foo/main.go\n@@ -1,2 +1,2 @@ package foo\n package foo\n-var a = \"foo\"\n+var b = \"foo\"\n",
+			changes:  []*entities.FileChange{{Operation: entities.FileModify, File: "/foo/main.go"}},
+		},
+		{
+			name:     "new file",
+			patch:    "diff --git a/acme.go b/acme.go\nnew file mode 100644\n--- /dev/null\n+++ b/acme.go\n@@ -0,0 +1 @@\n+acme\n\\ No newline at end of file\n",
+			expected: "diff --git a/foo.go b/foo.go\nnew file mode 100644\n--- /dev/null\n+++ b/foo.go\n@@ -0,0 +1 @@\n+foo\n\\ No newline at end of file\n",
+			changes:  []*entities.FileChange{{Operation: entities.FileCreate, File: "/foo.go"}},
+		},
+		{
+			name:     "deleted file",
+			patch:    "diff --git a/acme.go b/acme.go\ndeleted file mode 100644\n--- a/acme.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-acme\n",
+			expected: "diff --git a/foo.go b/foo.go\ndeleted file mode 100644\n--- a/foo.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-foo\n",
+			changes:  []*entities.FileChange{{Operation: entities.FileDelete, File: "/foo.go"}},
+		},
+		{
+			name:     "renamed file",
+			patch:    "diff --git a/old.go b/acme new.go\nsimilarity index 100%\nrename from old.go\nrename to acme new.go\n",
+			expected: "diff --git a/old.go b/foo new.go\nsimilarity index 100%\nrename from old.go\nrename to foo new.go\n",
+			changes:  []*entities.FileChange{{Operation: entities.FileRename, File: "/foo new.go", From: "/old.go"}},
+		},
+		{
+			name:     "copied file",
+			patch:    "diff --git a/a.go b/acme.go\nsimilarity index 100%\ncopy from a.go\ncopy to acme.go\n",
+			expected: "diff --git a/a.go b/foo.go\nsimilarity index 100%\ncopy from a.go\ncopy to foo.go\n",
+			changes:  []*entities.FileChange{{Operation: entities.FileCreate, File: "/foo.go"}},
+		},
+		{
+			name:     "binary file keeps its content",
+			patch:    "diff --git a/acme.png b/acme.png\nindex 1234567..89abcde 100644\nBinary files a/acme.png and b/acme.png differ\n",
+			expected: "diff --git a/foo.png b/foo.png\nindex 1234567..89abcde 100644\nBinary files a/foo.png and b/foo.png differ\n",
+			changes:  []*entities.FileChange{{Operation: entities.FileModify, File: "/foo.png"}},
+		},
+		{
+			name:     "quoted names",
+			patch:    "diff --git \"a/acme \\\"q\\\".txt\" \"b/acme \\\"q\\\".txt\"\nold mode 100644\nnew mode 100755\n",
+			expected: "diff --git \"a/foo \\\"q\\\".txt\" \"b/foo \\\"q\\\".txt\"\nold mode 100644\nnew mode 100755\n",
+			changes:  []*entities.FileChange{{Operation: entities.FileModify, File: "/foo \"q\".txt"}},
+		},
+		{
+			name:     "files not matched are removed",
+			patch:    "diff --git a/ignored/a.go b/ignored/a.go\n--- a/ignored/a.go\n+++ b/ignored/a.go\n@@ -1 +1 @@\n-a\n+b\n",
+			expected: "",
+			changes:  []*entities.FileChange{},
+		},
+		{
+			name:     "rendered files are skipped with a reason",
+			patch:    "diff --git a/rendered/acme.yaml b/rendered/acme.yaml\n--- a/rendered/acme.yaml\n+++ b/rendered/acme.yaml\n@@ -1 +1 @@\n-a\n+b\n",
+			expected: "",
+			changes: []*entities.FileChange{{
+				Operation: entities.FileSkip,
+				File:      "/rendered/foo.yaml",
+				Reason:    "rendered file, the diff method cannot update it, use the merge method",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, changes, err := NewTransformService(&fakeEngine{}).Transform([]byte(tt.patch), nil)
+			if err != nil {
+				t.Fatalf("Transform() error = %v", err)
+			}
+
+			if string(patch) != tt.expected {
+				t.Errorf("Expected patch %q, got %q", tt.expected, patch)
+			}
+			if !reflect.DeepEqual(changes, tt.changes) {
+				t.Errorf("Expected changes %v, got %v", tt.changes, changes)
+			}
+		})
+	}
+}
@@ -1,11 +1,25 @@
 package cvs
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
 	"github.com/sombrahq/sombra-cli/internal/core/usecases"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
-	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	patchingFile = regexp.MustCompile(`^patching file (.+)$`)
+	hunkApplied  = regexp.MustCompile(`^Hunk #(\d+) succeeded at (\d+)`)
+	hunkFailed   = regexp.MustCompile(`^Hunk #(\d+) (?:FAILED|ignored) at (\d+)`)
+	hunkSummary  = regexp.MustCompile(`^(\d+) out of (\d+) hunks? (?:FAILED|ignored)(?: -- saving rejects to file (.+))?$`)
+	skippedPatch = regexp.MustCompile(`^(.*?)\s+Skipping patch\.$`)
 )
 
 type PatchService struct {
@@ -15,7 +29,9 @@ func NewPatchService() *PatchService {
 	return &PatchService{}
 }
 
-func (t *PatchService) Apply(dir string, patch []byte) error {
+// Apply runs GNU patch over the directory, the hunks that could not be applied are stored in .rej files
+func (t *PatchService) Apply(dir string, patch []byte) (*entities.PatchResult, error) {
+	var out bytes.Buffer
 	cmd := exec.Command(
 		"patch",
 		"-p1", "--force", "--fuzz=5",
@@ -23,15 +39,120 @@ func (t *PatchService) Apply(dir string, patch []byte) error {
 	)
 	cmd.Stdin = bytes.NewReader(patch)
 	cmd.Dir = dir
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
+	cmd.Stderr = &out
+	cmd.Stdout = &out
 	err := cmd.Run()
-	if err != nil {
+
+	// patch exits with 1 when some hunks failed, anything else is a real trouble
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		logger.Error("Failed to apply patch", err)
+		return nil, fmt.Errorf("failed to apply patch: %w\n%s", err, out.String())
+	}
+
+	res := t.parseOutput(out.Bytes())
+	if err != nil && res.Rejected() == 0 {
 		logger.Error("Failed to apply patch", err)
-		return err
+		return nil, fmt.Errorf("failed to apply patch: %w\n%s", err, out.String())
 	}
-	logger.Info("Applied patch successfully")
-	return nil
+
+	logger.Info(fmt.Sprintf("Applied patch with %d rejected hunks", res.Rejected()))
+	return res, nil
+}
+
+// parseOutput reads the result of every file and hunk from the GNU patch messages
+func (t *PatchService) parseOutput(output []byte) *entities.PatchResult {
+	res := &entities.PatchResult{Files: make([]*entities.PatchFileResult, 0)}
+	var current *entities.PatchFileResult
+	var candidate string
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// the name of a missing file only appears in the text leading up to the patch
+		if strings.HasPrefix(line, "|+++ ") || strings.HasPrefix(line, "|--- ") {
+			name := strings.Fields(line[5:])[0]
+			if name != "/dev/null" {
+				candidate = t.stripPrefix(t.unquote(name))
+			}
+			continue
+		}
+
+		if groups := patchingFile.FindStringSubmatch(line); groups != nil {
+			current = &entities.PatchFileResult{File: t.file(t.unquote(groups[1]))}
+			res.Files = append(res.Files, current)
+			continue
+		}
+
+		if groups := skippedPatch.FindStringSubmatch(line); groups != nil {
+			if strings.HasPrefix(line, "No file to patch") {
+				current = &entities.PatchFileResult{File: t.file(candidate)}
+				res.Files = append(res.Files, current)
+			}
+			if current != nil {
+				current.Message = strings.TrimSpace(groups[1])
+			}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		if groups := hunkApplied.FindStringSubmatch(line); groups != nil {
+			status := entities.HunkApplied
+			if strings.Contains(line, "fuzz") || strings.Contains(line, "offset") {
+				status = entities.HunkFuzzed
+			}
+			current.Hunks = append(current.Hunks, t.hunk(groups[1], groups[2], status))
+			continue
+		}
+
+		if groups := hunkFailed.FindStringSubmatch(line); groups != nil {
+			current.Hunks = append(current.Hunks, t.hunk(groups[1], groups[2], entities.HunkRejected))
+			continue
+		}
+
+		if groups := hunkSummary.FindStringSubmatch(line); groups != nil {
+			// skipped files do not list their hunks
+			failed, _ := strconv.Atoi(groups[1])
+			for n := current.Count(entities.HunkRejected) + 1; n <= failed; n++ {
+				current.Hunks = append(current.Hunks, &entities.PatchHunkResult{Number: n, Status: entities.HunkRejected})
+			}
+			if groups[3] != "" {
+				current.RejectFile = t.file(t.unquote(groups[3]))
+			}
+		}
+	}
+
+	return res
+}
+
+func (t *PatchService) hunk(number, line string, status entities.HunkStatus) *entities.PatchHunkResult {
+	n, _ := strconv.Atoi(number)
+	l, _ := strconv.Atoi(line)
+	return &entities.PatchHunkResult{Number: n, Status: status, Line: l}
+}
+
+// unquote removes the quotes added by newer versions of GNU patch to file names
+func (t *PatchService) unquote(name string) string {
+	if len(name) > 1 && (name[0] == '\'' || name[0] == '"') && name[len(name)-1] == name[0] {
+		return name[1 : len(name)-1]
+	}
+	return name
+}
+
+// stripPrefix removes the first path component, as -p1 does
+func (t *PatchService) stripPrefix(name string) string {
+	if i := strings.Index(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func (t *PatchService) file(name string) entities.File {
+	return entities.File("/" + strings.TrimPrefix(name, "/"))
 }
 
 var _ usecases.PatchPort = (*PatchService)(nil)
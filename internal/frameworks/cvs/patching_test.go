@@ -0,0 +1,92 @@
+package cvs
+
+import (
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+func TestPatchService_parseOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []entities.PatchFileResult
+		hunks    [][]entities.PatchHunkResult
+	}{
+		{
+			name:     "clean patch",
+			output:   "patching file main.go\npatching file 'with space.go'\n",
+			expected: []entities.PatchFileResult{{File: "/main.go"}, {File: "/with space.go"}},
+			hunks:    [][]entities.PatchHunkResult{nil, nil},
+		},
+		{
+			name: "fuzz, offset and failed hunks",
+			output: "patching file a.txt\n" +
+				"Hunk #1 succeeded at 3 (offset 1 line).\n" +
+				"Hunk #2 succeeded at 10 with fuzz 1.\n" +
+				"Hunk #3 FAILED at 20.\n" +
+				"1 out of 3 hunks FAILED -- saving rejects to file a.txt.rej\n",
+			expected: []entities.PatchFileResult{{File: "/a.txt", RejectFile: "/a.txt.rej"}},
+			hunks: [][]entities.PatchHunkResult{{
+				{Number: 1, Status: entities.HunkFuzzed, Line: 3},
+				{Number: 2, Status: entities.HunkFuzzed, Line: 10},
+				{Number: 3, Status: entities.HunkRejected, Line: 20},
+			}},
+		},
+		{
+			name: "missing file",
+			output: "can't find file to patch at input line 17\n" +
+				"Perhaps you used the wrong -p or --strip option?\n" +
+				"The text leading up to this was:\n" +
+				"--------------------------\n" +
+				"|diff --git a/src/missing.txt b/src/missing.txt\n" +
+				"|--- a/src/missing.txt\n" +
+				"|+++ b/src/missing.txt\n" +
+				"--------------------------\n" +
+				"No file to patch.  Skipping patch.\n" +
+				"2 out of 2 hunks ignored\n",
+			expected: []entities.PatchFileResult{{File: "/src/missing.txt", Message: "No file to patch."}},
+			hunks: [][]entities.PatchHunkResult{{
+				{Number: 1, Status: entities.HunkRejected},
+				{Number: 2, Status: entities.HunkRejected},
+			}},
+		},
+		{
+			name: "reversed patch",
+			output: "patching file b.txt\n" +
+				"Reversed (or previously applied) patch detected!  Skipping patch.\n" +
+				"1 out of 1 hunk ignored -- saving rejects to file b.txt.rej\n",
+			expected: []entities.PatchFileResult{{
+				File:       "/b.txt",
+				RejectFile: "/b.txt.rej",
+				Message:    "Reversed (or previously applied) patch detected!",
+			}},
+			hunks: [][]entities.PatchHunkResult{{
+				{Number: 1, Status: entities.HunkRejected},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := NewPatchService().parseOutput([]byte(tt.output))
+
+			if len(res.Files) != len(tt.expected) {
+				t.Fatalf("Expected %d files, got %d", len(tt.expected), len(res.Files))
+			}
+			for i, file := range res.Files {
+				if file.File != tt.expected[i].File || file.RejectFile != tt.expected[i].RejectFile || file.Message != tt.expected[i].Message {
+					t.Errorf("Expected file %+v, got %+v", tt.expected[i], *file)
+				}
+				if len(file.Hunks) != len(tt.hunks[i]) {
+					t.Fatalf("Expected %d hunks, got %d", len(tt.hunks[i]), len(file.Hunks))
+				}
+				for j, hunk := range file.Hunks {
+					if *hunk != tt.hunks[i][j] {
+						t.Errorf("Expected hunk %+v, got %+v", tt.hunks[i][j], *hunk)
+					}
+				}
+			}
+		})
+	}
+}
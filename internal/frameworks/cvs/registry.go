@@ -1,25 +1,148 @@
 package cvs
 
 import (
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
 	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/cvs/archive"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/cvs/git"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/cvs/gogit"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/cvs/local"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/cvs/patch"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"os"
+	"strconv"
 )
 
 type RegistryType map[string]usecases.RepositoryFactory
 
 var registry = RegistryType{
-	"git": git.Factory,
+	"git":     git.Factory,
+	"local":   local.NewFactory(git.Factory),
+	"archive": archive.Factory,
 }
 
+// Options tunes how the template repositories are read
+type Options struct {
+	// Offline only uses the repositories already in the cache
+	Offline bool
+}
+
+// For returns the repository of the URI, file:// URIs and local directories are read in place
 func For(url string) (usecases.RepositoryPort, error) {
-	logger.Info("Calling LocalRepoFactory for URL: " + url)
-	factory := registry["git"]
-	repoPort, err := factory(url)
+	return FactoryFor(Options{})(url, nil)
+}
+
+// FactoryFor returns the repository factory, remote git repositories are kept in the cache
+func FactoryFor(opts Options) usecases.RepositoryFactory {
+	return func(url string, credentials *entities.Credentials) (usecases.RepositoryPort, error) {
+		logger.Info("Calling LocalRepoFactory for URL: " + url)
+		kind := "git"
+		switch {
+		case archive.IsArchive(url):
+			kind = "archive"
+		case local.IsLocal(url):
+			kind = "local"
+		}
+		factory := registry[kind]
+		if kind == "git" {
+			factory = gitFactory(opts)
+		}
+		repoPort, err := factory(url, credentials)
+		if err != nil {
+			logger.Error("Failed to get LocalRepoPort", err)
+			return nil, err
+		}
+		logger.Info("Successfully obtained LocalRepoPort for URL: " + url)
+		return repoPort, nil
+	}
+}
+
+// gitFactory returns the git engine set in SOMBRA_GIT_ENGINE, the git CLI is used by default
+func gitFactory(opts Options) usecases.RepositoryFactory {
+	engine := os.Getenv("SOMBRA_GIT_ENGINE")
+	switch engine {
+	case "", "cli":
+		return cachedFactory(opts)
+	case "native":
+		if opts.Offline {
+			return func(string, *entities.Credentials) (usecases.RepositoryPort, error) {
+				return nil, fmt.Errorf("the native git engine does not use the cache, it cannot work offline")
+			}
+		}
+		logger.Info("Using git engine: native")
+		return gogit.Factory
+	}
+	logger.Error("Unknown git engine, using the git CLI", fmt.Errorf("git engine %s not supported", engine))
+	return cachedFactory(opts)
+}
+
+// cachedFactory falls back to a plain clone when there is no cache directory
+func cachedFactory(opts Options) usecases.RepositoryFactory {
+	cache, err := git.NewCache()
+	if err != nil && opts.Offline {
+		return func(string, *entities.Credentials) (usecases.RepositoryPort, error) {
+			return nil, fmt.Errorf("the cache is not available offline: %w", err)
+		}
+	}
+	if err != nil {
+		logger.Error("The cache is not available, the template is cloned", err)
+		return registry["git"]
+	}
+	return git.NewFactory(cache, opts.Offline)
+}
+
+// CacheFor returns the cache of the git repositories
+func CacheFor() (usecases.RepositoryCachePort, error) {
+	cache, err := git.NewCache()
 	if err != nil {
-		logger.Error("Failed to get LocalRepoPort", err)
 		return nil, err
 	}
-	logger.Info("Successfully obtained LocalRepoPort for URL: " + url)
-	return repoPort, nil
+	return cache, nil
+}
+
+// defaultFuzz matches the fuzz used with GNU patch, the diffs include 10 context lines
+const defaultFuzz = 5
+
+var patchRegistry = map[string]func() usecases.PatchPort{
+	"native": func() usecases.PatchPort {
+		return patch.NewService(patchFuzz())
+	},
+	"gnu": func() usecases.PatchPort {
+		return NewPatchService()
+	},
+}
+
+// PatchFor returns the patch engine set in SOMBRA_PATCH_ENGINE, the native one is used by default
+func PatchFor() usecases.PatchPort {
+	engine := os.Getenv("SOMBRA_PATCH_ENGINE")
+	if engine == "" {
+		engine = "native"
+	}
+	factory, ok := patchRegistry[engine]
+	if !ok {
+		logger.Error("Unknown patch engine, using the native one", fmt.Errorf("patch engine %s not supported", engine))
+		factory = patchRegistry["native"]
+	}
+	logger.Info("Using patch engine: " + engine)
+	return factory()
+}
+
+// patchFuzz reads the fuzz of the native engine from SOMBRA_PATCH_FUZZ
+func patchFuzz() int {
+	value := os.Getenv("SOMBRA_PATCH_FUZZ")
+	if value == "" {
+		return defaultFuzz
+	}
+	fuzz, err := strconv.Atoi(value)
+	if err != nil || fuzz < 0 {
+		logger.Error("Invalid patch fuzz, using the default one", fmt.Errorf("fuzz %s is not a positive number", value))
+		return defaultFuzz
+	}
+	return fuzz
+}
+
+// TransformFor returns the service that rewrites the template diffs with the sombra mappings
+func TransformFor(engine usecases.SombraEngineCase) usecases.PatchTransformPort {
+	return patch.NewTransformService(engine)
 }
@@ -29,6 +29,11 @@ func (f *FileManagerService) EnsureDir(dir string, fn entities.File) error {
 	return nil
 }
 
+func (f *FileManagerService) Exists(dir string, fn entities.File) bool {
+	_, err := os.Stat(filepath.Join(dir, string(fn)))
+	return err == nil
+}
+
 func (f *FileManagerService) Read(dir string, fn entities.File) ([]byte, error) {
 	file := filepath.Join(dir, string(fn))
 	data, err := os.ReadFile(file)
@@ -58,6 +63,17 @@ func (f *FileManagerService) Write(dir string, fn entities.File, content []byte)
 	return nil
 }
 
+func (f *FileManagerService) Remove(dir string, fn entities.File) error {
+	file := filepath.Join(dir, string(fn))
+	err := os.Remove(file)
+	if err != nil {
+		logger.Error("error while removing file", err)
+		return err
+	}
+	logger.Info("File removed successfully: " + file)
+	return nil
+}
+
 func NewFileManagerService() *FileManagerService {
 	return &FileManagerService{}
 }
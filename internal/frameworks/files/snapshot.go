@@ -0,0 +1,212 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// SnapshotService copies the directory to a temporary one, the .git directory is never touched by an update.
+// The snapshot is taken once for a run, then only the files written by the updates are copied again.
+type SnapshotService struct {
+	ignore []string
+}
+
+func (s *SnapshotService) Snapshot(dir string) (string, error) {
+	snapshot, err := os.MkdirTemp("", "sombra-snapshot-")
+	if err != nil {
+		logger.Error("failed to create snapshot directory", err)
+		return "", err
+	}
+
+	err = s.copyTree(dir, snapshot)
+	if err != nil {
+		logger.Error("failed to snapshot directory "+dir, err)
+		_ = os.RemoveAll(snapshot)
+		return "", err
+	}
+	logger.Info("Snapshot of " + dir + " stored in " + snapshot)
+	return snapshot, nil
+}
+
+// Restore removes the entries created after the snapshot and writes back the ones that changed
+func (s *SnapshotService) Restore(dir, snapshot string) error {
+	removed := make([]string, 0)
+	err := s.walk(dir, func(rel string, entry fs.DirEntry) error {
+		if _, err := os.Lstat(filepath.Join(snapshot, rel)); errors.Is(err, os.ErrNotExist) {
+			removed = append(removed, rel)
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("failed to scan directory "+dir, err)
+		return err
+	}
+
+	for _, rel := range removed {
+		err = os.RemoveAll(filepath.Join(dir, rel))
+		if err != nil {
+			logger.Error("failed to remove "+rel, err)
+			return err
+		}
+	}
+
+	err = s.copyTree(snapshot, dir)
+	if err != nil {
+		logger.Error("failed to restore directory "+dir, err)
+		return err
+	}
+	logger.Info("Restored " + dir + " from " + snapshot)
+	return nil
+}
+
+func (s *SnapshotService) Update(dir, snapshot string, paths []string) error {
+	for _, rel := range paths {
+		err := s.updatePath(dir, snapshot, filepath.FromSlash(rel))
+		if err != nil {
+			logger.Error("failed to update snapshot with "+rel, err)
+			return err
+		}
+	}
+	logger.Info(fmt.Sprintf("Updated %d paths in snapshot %s", len(paths), snapshot))
+	return nil
+}
+
+func (s *SnapshotService) updatePath(dir, snapshot, rel string) error {
+	from, to := filepath.Join(dir, rel), filepath.Join(snapshot, rel)
+	info, err := os.Lstat(from)
+	if errors.Is(err, os.ErrNotExist) {
+		err = os.RemoveAll(to)
+		// the parents removed with the file are not restored
+		for parent := filepath.Dir(rel); err == nil && parent != "." && parent != string(filepath.Separator); parent = filepath.Dir(parent) {
+			if _, statErr := os.Lstat(filepath.Join(dir, parent)); !errors.Is(statErr, os.ErrNotExist) {
+				break
+			}
+			err = os.RemoveAll(filepath.Join(snapshot, parent))
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(to), 0755)
+	if err != nil {
+		return err
+	}
+	err = s.copyEntry(from, to, info)
+	if err != nil || !info.IsDir() {
+		return err
+	}
+	return s.copyTree(from, to)
+}
+
+func (s *SnapshotService) Discard(snapshot string) error {
+	err := os.RemoveAll(snapshot)
+	if err != nil {
+		logger.Error("failed to remove snapshot "+snapshot, err)
+		return err
+	}
+	logger.Info("Removed snapshot: " + snapshot)
+	return nil
+}
+
+// copyTree copies the entries of src into dst, files with the same content are left untouched
+func (s *SnapshotService) copyTree(src, dst string) error {
+	return s.walk(src, func(rel string, entry fs.DirEntry) error {
+		from, to := filepath.Join(src, rel), filepath.Join(dst, rel)
+		info, err := os.Lstat(from)
+		if err != nil {
+			return err
+		}
+		return s.copyEntry(from, to, info)
+	})
+}
+
+// copyEntry copies a directory, a link or a regular file, replacing the entry of another type
+func (s *SnapshotService) copyEntry(from, to string, info os.FileInfo) error {
+	switch {
+	case info.IsDir():
+		if current, err := os.Lstat(to); err == nil && !current.IsDir() {
+			_ = os.Remove(to)
+		}
+		return os.MkdirAll(to, info.Mode().Perm())
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(from)
+		if err != nil {
+			return err
+		}
+		if current, err := os.Readlink(to); err == nil && current == link {
+			return nil
+		}
+		_ = os.RemoveAll(to)
+		return os.Symlink(link, to)
+	case info.Mode().IsRegular():
+		return s.copyFile(from, to, info.Mode().Perm())
+	}
+	return nil
+}
+
+func (s *SnapshotService) copyFile(from, to string, perm os.FileMode) error {
+	content, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+
+	if info, err := os.Lstat(to); err == nil {
+		if info.Mode().IsRegular() {
+			current, err := os.ReadFile(to)
+			if err == nil && bytes.Equal(current, content) {
+				return os.Chmod(to, perm)
+			}
+		} else {
+			// the entry changed its type, e.g. a file replaced by a directory
+			err = os.RemoveAll(to)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = os.WriteFile(to, content, perm)
+	if err != nil {
+		return err
+	}
+	return os.Chmod(to, perm)
+}
+
+// walk visits the entries of the directory with their relative path, skipping the ignored ones
+func (s *SnapshotService) walk(dir string, visit func(rel string, entry fs.DirEntry) error) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		for _, name := range s.ignore {
+			if rel == name && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			if rel == name {
+				return nil
+			}
+		}
+		return visit(rel, entry)
+	})
+}
+
+func NewSnapshotService() *SnapshotService {
+	return &SnapshotService{ignore: []string{".git"}}
+}
+
+var _ usecases.SnapshotPort = (*SnapshotService)(nil)
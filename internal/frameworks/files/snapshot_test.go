@@ -0,0 +1,124 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotService_Restore(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		fn := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n")
+	write("src/old.go", "package src\n")
+	write(".git/HEAD", "ref: refs/heads/main\n")
+
+	service := NewSnapshotService()
+	snapshot, err := service.Snapshot(dir)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	defer service.Discard(snapshot)
+
+	// the update changes, removes and creates files
+	write("main.go", "package changed\n")
+	if err = os.Remove(filepath.Join(dir, "src/old.go")); err != nil {
+		t.Fatal(err)
+	}
+	write("pkg/new.go", "package pkg\n")
+	write("new.txt", "new\n")
+	write(".git/ORIG_HEAD", "abc\n")
+
+	if err = service.Restore(dir, snapshot); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"main.go":        "package main\n",
+		"src/old.go":     "package src\n",
+		".git/HEAD":      "ref: refs/heads/main\n",
+		".git/ORIG_HEAD": "abc\n",
+	}
+	for name, content := range expected {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("Expected file %s: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("Expected %s to be %q, got %q", name, content, data)
+		}
+	}
+	for _, name := range []string{"pkg", "new.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("Expected %s to be removed", name)
+		}
+	}
+}
+
+func TestSnapshotService_Update(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		fn := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("sombra.yaml", "templates: []\n")
+	write("main.go", "package main\n")
+	write("old/old.go", "package old\n")
+
+	service := NewSnapshotService()
+	snapshot, err := service.Snapshot(dir)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	defer service.Discard(snapshot)
+
+	// a first template updates some files, they are copied to the snapshot
+	write("sombra.yaml", "templates: [first]\n")
+	write("pkg/new.go", "package pkg\n")
+	if err = os.RemoveAll(filepath.Join(dir, "old")); err != nil {
+		t.Fatal(err)
+	}
+	if err = service.Update(dir, snapshot, []string{"sombra.yaml", "pkg/new.go", "old/old.go"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	// a second template fails, only its changes are rolled back
+	write("main.go", "package second\n")
+	write("sombra.yaml", "templates: [first, second]\n")
+	if err = service.Restore(dir, snapshot); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"sombra.yaml": "templates: [first]\n",
+		"main.go":     "package main\n",
+		"pkg/new.go":  "package pkg\n",
+	}
+	for name, content := range expected {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("Expected file %s: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("Expected %s to be %q, got %q", name, content, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old")); err == nil {
+		t.Errorf("Expected old to stay removed")
+	}
+}
@@ -0,0 +1,215 @@
+package report
+
+import (
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+type ConsoleReporter struct {
+	out io.Writer
+}
+
+func (r *ConsoleReporter) ReportPlan(plan *entities.UpdatePlan) {
+	from := string(plan.From)
+	if from == "" {
+		from = "(none)"
+	}
+
+	title := plan.URI
+	if plan.Path != "" {
+		title = fmt.Sprintf("%s (%s)", plan.URI, plan.Path)
+	}
+	if plan.DryRun {
+		_, _ = fmt.Fprintf(r.out, "Dry run for %s: %s -> %s\n", title, from, plan.To)
+	} else if plan.Incomplete {
+		_, _ = fmt.Fprintf(r.out, "Incomplete update of %s: %s -> %s, the version was not stored\n", title, from, plan.To)
+	} else {
+		_, _ = fmt.Fprintf(r.out, "Updated %s: %s -> %s\n", title, from, plan.To)
+	}
+
+	if len(plan.Changes) == 0 {
+		_, _ = fmt.Fprintln(r.out, "  no file changes")
+		return
+	}
+
+	conflicts := 0
+	for _, change := range plan.Changes {
+		if change.Conflict {
+			conflicts++
+			_, _ = fmt.Fprintf(r.out, "  %-7s %s (conflict)\n", change.Operation, r.name(change.File))
+			continue
+		}
+		if change.Operation == entities.FileSkip {
+			_, _ = fmt.Fprintf(r.out, "  %-7s %s (%s)\n", change.Operation, r.name(change.File), change.Reason)
+			continue
+		}
+		if change.Operation == entities.FileRename {
+			_, _ = fmt.Fprintf(r.out, "  %-7s %s -> %s\n", change.Operation, r.name(change.From), r.name(change.File))
+			continue
+		}
+		_, _ = fmt.Fprintf(r.out, "  %-7s %s\n", change.Operation, r.name(change.File))
+	}
+	if conflicts > 0 {
+		_, _ = fmt.Fprintf(r.out, "  %d file(s) with conflicts, resolve the conflict markers before committing\n", conflicts)
+	}
+
+	if plan.Patch != nil {
+		r.reportPatch(plan.Patch)
+	}
+}
+
+// reportPatch prints a table with the files that were not cleanly applied
+func (r *ConsoleReporter) reportPatch(res *entities.PatchResult) {
+	files := make([]*entities.PatchFileResult, 0)
+	for _, file := range res.Files {
+		if file.Message != "" || file.Count(entities.HunkRejected)+file.Count(entities.HunkFuzzed) > 0 {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "  FILE\tFUZZED\tREJECTED\tDETAILS")
+	for _, file := range files {
+		details := make([]string, 0)
+		if file.Message != "" {
+			details = append(details, file.Message)
+		}
+		if file.RejectFile != "" {
+			details = append(details, "rejects saved to "+r.name(file.RejectFile))
+		}
+		_, _ = fmt.Fprintf(w, "  %s\t%d\t%d\t%s\n",
+			r.name(file.File), file.Count(entities.HunkFuzzed), file.Count(entities.HunkRejected), strings.Join(details, "; "))
+	}
+	_ = w.Flush()
+
+	if rejected := res.Rejected(); rejected > 0 {
+		_, _ = fmt.Fprintf(r.out, "  %d hunk(s) rejected\n", rejected)
+	}
+}
+
+// ReportSummary prints one line per template after updating all of them
+func (r *ConsoleReporter) ReportSummary(updates []*entities.TemplateUpdate) {
+	_, _ = fmt.Fprintln(r.out, "Summary:")
+	w := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "  TEMPLATE\tSTATUS\tVERSION\tDETAILS")
+	for _, update := range updates {
+		version := ""
+		if len(update.Plans) > 0 {
+			plan := update.Plans[0]
+			from := string(plan.From)
+			if from == "" {
+				from = "(none)"
+			}
+			version = fmt.Sprintf("%s -> %s", from, plan.To)
+		}
+
+		details := update.Error
+		if update.Status == entities.UpdateSkipped {
+			details = "not updated after a previous failure"
+		}
+		_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", update.URI, update.Status, version, details)
+	}
+	_ = w.Flush()
+}
+
+func (r *ConsoleReporter) ReportDiff(diff *entities.TemplateDiff, stat bool) {
+	if stat {
+		r.reportStat(diff)
+		return
+	}
+	for _, file := range diff.Files {
+		_, _ = r.out.Write(file.Diff.Patch)
+	}
+}
+
+func (r *ConsoleReporter) reportStat(diff *entities.TemplateDiff) {
+	added, removed := 0, 0
+	width := 0
+	for _, file := range diff.Files {
+		width = max(width, len(r.name(file.File)))
+	}
+	for _, file := range diff.Files {
+		added += file.Diff.Added
+		removed += file.Diff.Removed
+		_, _ = fmt.Fprintf(r.out, " %-*s | %4d %s%s\n",
+			width, r.name(file.File),
+			file.Diff.Added+file.Diff.Removed,
+			strings.Repeat("+", min(file.Diff.Added, 40)),
+			strings.Repeat("-", min(file.Diff.Removed, 40)),
+		)
+	}
+	_, _ = fmt.Fprintf(r.out, " %d files changed, %d insertions(+), %d deletions(-) against %s %s\n",
+		len(diff.Files), added, removed, diff.URI, diff.Version)
+}
+
+// ReportCache prints one line per cached repository
+func (r *ConsoleReporter) ReportCache(entries []*entities.CacheEntry) {
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(r.out, "The cache is empty")
+		return
+	}
+	w := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TEMPLATE\tSIZE\tLAST USED")
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", entry.URI, r.size(entry.Size), entry.LastUsed)
+	}
+	_ = w.Flush()
+	_, _ = fmt.Fprintf(r.out, "%d repositories, %s\n", len(entries), r.size(total))
+}
+
+func (r *ConsoleReporter) ReportCacheRemoved(entries []*entities.CacheEntry) {
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+		_, _ = fmt.Fprintf(r.out, "Removed %s\n", entry.URI)
+	}
+	_, _ = fmt.Fprintf(r.out, "%d repositories removed, %s freed\n", len(entries), r.size(total))
+}
+
+// ReportFlavors prints one definition per line
+func (r *ConsoleReporter) ReportFlavors(uri string, flavors []string) {
+	if len(flavors) == 0 {
+		_, _ = fmt.Fprintf(r.out, "%s has no template definition\n", uri)
+		return
+	}
+	for _, flavor := range flavors {
+		_, _ = fmt.Fprintln(r.out, flavor)
+	}
+}
+
+// size formats a number of bytes with a binary unit
+func (r *ConsoleReporter) size(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value, exp := float64(bytes)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGT"[exp])
+}
+
+func (r *ConsoleReporter) name(fn entities.File) string {
+	return strings.TrimPrefix(string(fn), "/")
+}
+
+func NewConsoleReporter() *ConsoleReporter {
+	return &ConsoleReporter{out: os.Stdout}
+}
+
+var _ usecases.UpdateReporterPort = (*ConsoleReporter)(nil)
+var _ usecases.DiffReporterPort = (*ConsoleReporter)(nil)
+var _ usecases.CacheReporterPort = (*ConsoleReporter)(nil)
+var _ usecases.TemplateReporterPort = (*ConsoleReporter)(nil)
@@ -0,0 +1,64 @@
+package sombra
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wordRune tells if a rune is part of a word, so a word: mapping cannot start or end next to it
+type wordRune func(r rune) bool
+
+// identRune is a letter, a digit or an underscore, the identifiers of Go and Python and the default of the other files
+func identRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// jsIdentRune adds the dollar sign of the JavaScript and TypeScript identifiers
+func jsIdentRune(r rune) bool {
+	return identRune(r) || r == '$'
+}
+
+// segmentRune splits the paths on their separators and on the punctuation of the names, api matches api.go and my-api
+func segmentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+var languageRunes = map[string]wordRune{
+	".go":  identRune,
+	".py":  identRune,
+	".pyi": identRune,
+	".js":  jsIdentRune,
+	".jsx": jsIdentRune,
+	".mjs": jsIdentRune,
+	".cjs": jsIdentRune,
+	".ts":  jsIdentRune,
+	".tsx": jsIdentRune,
+}
+
+// contentRunes returns the word runes of the language of the file
+func contentRunes(file string) wordRune {
+	if res, ok := languageRunes[strings.ToLower(filepath.Ext(file))]; ok {
+		return res
+	}
+	return identRune
+}
+
+// isWordMatch tells if the match is not glued to other word runes.
+// Like \b, the boundary is only checked on the sides of the match that end with a word rune, so .acme.com is still a word.
+func isWordMatch(content []byte, start, end int, isWord wordRune) bool {
+	first, _ := utf8.DecodeRune(content[start:end])
+	last, _ := utf8.DecodeLastRune(content[start:end])
+	if start > 0 && isWord(first) {
+		if before, _ := utf8.DecodeLastRune(content[:start]); isWord(before) {
+			return false
+		}
+	}
+	if end < len(content) && isWord(last) {
+		if after, _ := utf8.DecodeRune(content[end:]); isWord(after) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package sombra
+
+import (
+	"strings"
+	"unicode"
+)
+
+// words splits an identifier on its separators and on the case changes, HTTPServer gives HTTP and Server
+func words(s string) []string {
+	var res []string
+	runes := []rune(s)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				res = append(res, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		lowerToUpper := unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev))
+		acronymEnd := unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			res = append(res, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		res = append(res, string(runes[start:]))
+	}
+	return res
+}
+
+func title(word string) string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	return string(runes)
+}
+
+// caseForms are the forms of the case: mappings, in the order they are listed
+var caseForms = []func([]string) string{
+	// pascal, MyService
+	func(w []string) string {
+		res := ""
+		for _, word := range w {
+			res += title(word)
+		}
+		return res
+	},
+	// camel, myService
+	func(w []string) string {
+		res := ""
+		for i, word := range w {
+			if i == 0 {
+				res += strings.ToLower(word)
+				continue
+			}
+			res += title(word)
+		}
+		return res
+	},
+	// snake, my_service
+	func(w []string) string { return strings.ToLower(strings.Join(w, "_")) },
+	// kebab, my-service
+	func(w []string) string { return strings.ToLower(strings.Join(w, "-")) },
+	// upper snake, MY_SERVICE
+	func(w []string) string { return strings.ToUpper(strings.Join(w, "_")) },
+	// upper, MYSERVICE
+	func(w []string) string { return strings.ToUpper(strings.Join(w, "")) },
+	// lower, myservice
+	func(w []string) string { return strings.ToLower(strings.Join(w, "")) },
+}
+
+// expandCase returns the pairs of every form of the text and the value, a form shared by several ones is kept once
+func expandCase(text, value string) [][2]string {
+	textWords, valueWords := words(text), words(value)
+	seen := map[string]bool{}
+	var res [][2]string
+	for _, form := range caseForms {
+		key := form(textWords)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		res = append(res, [2]string{key, form(valueWords)})
+	}
+	return res
+}
@@ -0,0 +1,189 @@
+package sombra
+
+import (
+	"bytes"
+	"sort"
+)
+
+// acNode is a state of the Aho-Corasick automaton, the literal keys are the paths from the root
+type acNode struct {
+	edges []acEdge
+	// fail is the state of the longest proper suffix of this state that is also a prefix of a key
+	fail int32
+	// dict is the next state on the fail chain ending a key, -1 when there is none
+	dict int32
+	// out is the replacement of the key ending at this state, -1 when no key ends here
+	out int32
+}
+
+type acEdge struct {
+	b  byte
+	to int32
+}
+
+// match is a replacement found in the original content, groups are the submatches of the regular expressions
+type match struct {
+	start, end int
+	item       int
+	groups     []int
+}
+
+// matcher applies all the mappings in a single pass, the literals with an Aho-Corasick automaton and the regular
+// expressions with their own scan of the content. Of the overlapping matches, the leftmost wins, then the longest,
+// then the first mapping. The replaced text is never scanned again, so a value cannot be rewritten by another key.
+type matcher struct {
+	items   []*replacement
+	regexes []int
+	nodes   []acNode
+	// root is the dense transition table of the root, most of the bytes of the content are read there
+	root [256]int32
+}
+
+func newMatcher(items []*replacement) *matcher {
+	m := &matcher{items: items, nodes: []acNode{{dict: -1, out: -1}}}
+	for i, item := range items {
+		switch {
+		case item.re != nil:
+			m.regexes = append(m.regexes, i)
+		case item.old != "":
+			m.insert(item.old, i)
+		}
+	}
+	m.link()
+	return m
+}
+
+func (m *matcher) child(state int32, b byte) int32 {
+	for _, edge := range m.nodes[state].edges {
+		if edge.b == b {
+			return edge.to
+		}
+	}
+	return -1
+}
+
+// insert adds a literal key to the trie, the first mapping of a duplicated key wins
+func (m *matcher) insert(key string, item int) {
+	state := int32(0)
+	for i := 0; i < len(key); i++ {
+		next := m.child(state, key[i])
+		if next < 0 {
+			next = int32(len(m.nodes))
+			m.nodes = append(m.nodes, acNode{dict: -1, out: -1})
+			m.nodes[state].edges = append(m.nodes[state].edges, acEdge{b: key[i], to: next})
+		}
+		state = next
+	}
+	if m.nodes[state].out < 0 {
+		m.nodes[state].out = int32(item)
+	}
+}
+
+// link sets the fail and dict links of the states, breadth first so the links of the shorter states are ready
+func (m *matcher) link() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, edge := range m.nodes[0].edges {
+		m.root[edge.b] = edge.to
+		queue = append(queue, edge.to)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, edge := range m.nodes[state].edges {
+			fail := m.nodes[state].fail
+			for fail != 0 && m.child(fail, edge.b) < 0 {
+				fail = m.nodes[fail].fail
+			}
+			if next := m.child(fail, edge.b); next >= 0 {
+				fail = next
+			}
+
+			node := &m.nodes[edge.to]
+			node.fail = fail
+			node.dict = m.nodes[fail].dict
+			if m.nodes[fail].out >= 0 {
+				node.dict = fail
+			}
+			queue = append(queue, edge.to)
+		}
+	}
+}
+
+// replace returns the content with the mappings applied, isWord sets the boundaries of the word: mappings
+func (m *matcher) replace(content []byte, isWord wordRune) []byte {
+	matches := m.find(content, isWord)
+	if len(matches) == 0 {
+		return content
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.start != b.start {
+			return a.start < b.start
+		}
+		if a.end != b.end {
+			return a.end > b.end
+		}
+		return a.item < b.item
+	})
+
+	res := bytes.NewBuffer(make([]byte, 0, len(content)))
+	last, lastEmpty := 0, -1
+	for _, found := range matches {
+		// the overlapped matches are dropped, an empty match is only used once at each position
+		if found.start < last || (found.start == found.end && found.start == lastEmpty) {
+			continue
+		}
+		res.Write(content[last:found.start])
+		item := m.items[found.item]
+		if item.re != nil {
+			res.Write(item.re.Expand(nil, []byte(item.value), content, found.groups))
+		} else {
+			res.WriteString(item.value)
+		}
+		last = found.end
+		if found.start == found.end {
+			lastEmpty = found.start
+		}
+	}
+	res.Write(content[last:])
+	return res.Bytes()
+}
+
+// find returns every match of the mappings in the original content, overlapping or not
+func (m *matcher) find(content []byte, isWord wordRune) []match {
+	var res []match
+	if len(m.nodes) > 1 {
+		state := int32(0)
+		for i := 0; i < len(content); i++ {
+			b := content[i]
+			for state != 0 && m.child(state, b) < 0 {
+				state = m.nodes[state].fail
+			}
+			if state == 0 {
+				state = m.root[b]
+			} else {
+				state = m.child(state, b)
+			}
+
+			out := state
+			if m.nodes[out].out < 0 {
+				out = m.nodes[out].dict
+			}
+			for ; out > 0; out = m.nodes[out].dict {
+				item := int(m.nodes[out].out)
+				start, end := i+1-len(m.items[item].old), i+1
+				if m.items[item].word && !isWordMatch(content, start, end, isWord) {
+					continue
+				}
+				res = append(res, match{start: start, end: end, item: item})
+			}
+		}
+	}
+
+	for _, item := range m.regexes {
+		for _, groups := range m.items[item].re.FindAllSubmatchIndex(content, -1) {
+			res = append(res, match{start: groups[0], end: groups[1], item: item, groups: groups})
+		}
+	}
+	return res
+}
@@ -0,0 +1,208 @@
+package sombra
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+)
+
+func TestMatcher_Replace(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		mapping  entities.MapList
+		expected string
+	}{
+		{
+			name:     "no chained replacements",
+			content:  "acme beta",
+			mapping:  entities.MapList{{Key: "acme", Value: "beta"}, {Key: "beta", Value: "gamma"}},
+			expected: "beta gamma",
+		},
+		{
+			name:     "longest match wins",
+			content:  "acme-api acme",
+			mapping:  entities.MapList{{Key: "acme", Value: "x"}, {Key: "acme-api", Value: "y"}},
+			expected: "y x",
+		},
+		{
+			name:     "leftmost match wins",
+			content:  "ushers",
+			mapping:  entities.MapList{{Key: "he", Value: "1"}, {Key: "she", Value: "2"}, {Key: "hers", Value: "3"}},
+			expected: "u2rs",
+		},
+		{
+			name:     "regex and literals",
+			content:  "version = 1.2.3 for acme",
+			mapping:  entities.MapList{{Key: `re:version = (\d+)\.\d+\.\d+`, Value: "v${1}"}, {Key: "1.2", Value: "x"}, {Key: "acme", Value: "beta"}},
+			expected: "v1 for beta",
+		},
+		{
+			name:     "empty regex match",
+			content:  "a\nb",
+			mapping:  entities.MapList{{Key: "re:(?m)^", Value: "# "}},
+			expected: "# a\n# b",
+		},
+		{
+			name:     "no match",
+			content:  "nothing here",
+			mapping:  entities.MapList{{Key: "acme", Value: "beta"}},
+			expected: "nothing here",
+		},
+	}
+
+	processor := NewProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := newMatcher(processor.compile(tt.mapping)).replace([]byte(tt.content), identRune)
+			if string(res) != tt.expected {
+				t.Errorf("replace() = %q, expected %q", res, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatcher_Ties(t *testing.T) {
+	engine := usecases.NewSombraEngineInteractor(nil, nil, NewProcessor())
+	patterns := []*entities.Pattern{{
+		Pattern: "/**",
+		Default: entities.Mappings{"word:acme": "word", "re:acm[e]": "regex"},
+	}}
+
+	// both keys have the same length and match the same text, the first one in the order of the keys wins
+	for run := 0; run < 50; run++ {
+		items := engine.Combine(patterns)
+		if res := engine.NewContent("/main.go", []byte("acme"), items.Content); string(res) != "regex" {
+			t.Fatalf("NewContent() = %q on run %d, expected %q", res, run, "regex")
+		}
+	}
+}
+
+// naiveReplace is the leftmost-longest replacement checking every key at every position
+func naiveReplace(content string, keys []string) string {
+	var b strings.Builder
+	for i := 0; i < len(content); {
+		best := -1
+		for k, key := range keys {
+			if strings.HasPrefix(content[i:], key) && (best < 0 || len(key) > len(keys[best])) {
+				best = k
+			}
+		}
+		if best < 0 {
+			b.WriteByte(content[i])
+			i++
+			continue
+		}
+		fmt.Fprintf(&b, "<%d>", best)
+		i += len(keys[best])
+	}
+	return b.String()
+}
+
+func TestMatcher_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	word := func(n int) string {
+		res := make([]byte, 1+rnd.Intn(n))
+		for i := range res {
+			res[i] = "abc"[rnd.Intn(3)]
+		}
+		return string(res)
+	}
+
+	for run := 0; run < 500; run++ {
+		seen := map[string]bool{}
+		var keys []string
+		var items []*replacement
+		for len(keys) < 1+rnd.Intn(8) {
+			key := word(4)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			items = append(items, &replacement{old: key, value: fmt.Sprintf("<%d>", len(keys))})
+			keys = append(keys, key)
+		}
+		content := word(40)
+
+		res := string(newMatcher(items).replace([]byte(content), identRune))
+		if expected := naiveReplace(content, keys); res != expected {
+			t.Fatalf("replace(%q, %v) = %q, expected %q", content, keys, res, expected)
+		}
+	}
+}
+
+// sequentialReplace is the former processor, one scan of the whole content for each mapping
+func sequentialReplace(content []byte, mapping entities.MapList) []byte {
+	for _, item := range mapping {
+		content = bytes.ReplaceAll(content, []byte(item.Key), []byte(item.Value))
+	}
+	return content
+}
+
+// benchmarkRepository builds the files of a Go repository with identifiers of the mappings spread in the code
+func benchmarkRepository(files, size, mappings int) ([][]byte, entities.MapList) {
+	rnd := rand.New(rand.NewSource(1))
+	mapping := make(entities.MapList, 0, mappings)
+	for i := 0; i < mappings; i++ {
+		mapping = append(mapping, entities.MapItem{Key: fmt.Sprintf("acme%dService", i), Value: fmt.Sprintf("{{ .name%d }}", i)})
+	}
+
+	lines := []string{
+		"func (s *server) handle(ctx context.Context, req *Request) (*Response, error) {\n",
+		"\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"failed to read the request: %w\", err)\n\t}\n",
+		"\tres := make([]string, 0, len(req.Items))\n",
+		"}\n\n",
+	}
+	repo := make([][]byte, files)
+	for f := range repo {
+		buf := bytes.NewBufferString("package acme\n\nimport \"github.com/acme/api/internal\"\n\n")
+		for buf.Len() < size {
+			buf.WriteString(lines[rnd.Intn(len(lines))])
+			if rnd.Intn(4) == 0 {
+				fmt.Fprintf(buf, "\tsvc := %s.New()\n", mapping[rnd.Intn(len(mapping))].Key)
+			}
+		}
+		repo[f] = buf.Bytes()
+	}
+	return repo, mapping
+}
+
+func BenchmarkProcessor_ProcessContent(b *testing.B) {
+	cases := []struct {
+		files, size, mappings int
+	}{
+		{files: 1000, size: 16 << 10, mappings: 10},
+		{files: 1000, size: 16 << 10, mappings: 100},
+		{files: 4, size: 4 << 20, mappings: 10},
+		{files: 4, size: 4 << 20, mappings: 100},
+	}
+
+	for _, c := range cases {
+		repo, mapping := benchmarkRepository(c.files, c.size, c.mappings)
+		name := fmt.Sprintf("files=%d/size=%dKB/mappings=%d", c.files, c.size>>10, c.mappings)
+		total := int64(c.files * c.size)
+
+		b.Run("single-pass/"+name, func(b *testing.B) {
+			processor := NewProcessor()
+			b.SetBytes(total)
+			for i := 0; i < b.N; i++ {
+				for _, content := range repo {
+					processor.ProcessContent("/main.go", content, mapping)
+				}
+			}
+		})
+		b.Run("sequential/"+name, func(b *testing.B) {
+			b.SetBytes(total)
+			for i := 0; i < b.N; i++ {
+				for _, content := range repo {
+					sequentialReplace(content, mapping)
+				}
+			}
+		})
+	}
+}
@@ -2,57 +2,103 @@ package sombra
 
 import (
 	"bytes"
+	"fmt"
+	"github.com/Masterminds/sprig/v3"
 	"github.com/sombrahq/sombra-cli/internal/core/entities"
 	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
 	"regexp"
-	"strings"
+	"sync"
+	"text/template"
 )
 
+// replacement is a mapping ready to be applied, the regular expressions are compiled once
+type replacement struct {
+	old   string
+	re    *regexp.Regexp
+	value string
+	word  bool
+}
+
 type Processor struct {
+	mu      sync.Mutex
+	regexes map[string]*regexp.Regexp
 }
 
+// ProcessString applies the mappings to a path or a name, the word: mappings match whole path segments
 func (l *Processor) ProcessString(target string, mapping entities.MapList) string {
-	var old, replacement string
-	for _, item := range mapping {
-		old = item.Key
-		replacement = item.Value
-		target = l.stringReplace(target, old, replacement)
-	}
-	return target
+	return string(newMatcher(l.compile(mapping)).replace([]byte(target), segmentRune))
 }
 
-func (l *Processor) ProcessContent(content []byte, mapping entities.MapList) []byte {
-	var old, replacement string
-	for _, item := range mapping {
-		old = item.Key
-		replacement = item.Value
-		content = l.bytesReplace(content, old, replacement)
+// ProcessContent applies the mappings to the content in a single pass, the word: mappings match whole identifiers of the language of the file
+func (l *Processor) ProcessContent(file entities.File, content []byte, mapping entities.MapList) []byte {
+	return newMatcher(l.compile(mapping)).replace(content, contentRunes(string(file)))
+}
+
+func (l *Processor) RenderTemplate(name string, content []byte, delims []string, vars entities.Mappings) ([]byte, error) {
+	tmp := template.New(name).Funcs(sprig.TxtFuncMap()).Option("missingkey=error")
+	switch len(delims) {
+	case 0:
+	case 2:
+		tmp = tmp.Delims(delims[0], delims[1])
+	default:
+		return nil, fmt.Errorf("the delimiters of %s must be a left and a right one, got %v", name, delims)
 	}
-	return content
+
+	tmp, err := tmp.Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err = tmp.Execute(buf, vars)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
-func (l *Processor) bytesReplace(content []byte, old string, replacement string) []byte {
-	parts := strings.SplitN(old, ":", 2)
-	if len(parts) > 0 && parts[0] == "re" {
-		prev := regexp.MustCompile(parts[1])
-		return prev.ReplaceAll(content, ([]byte)(replacement))
+// compile parses the options of the mapping keys, the case: mappings are expanded in place.
+// The definitions are checked when they are rendered, an invalid regex left here is skipped.
+func (l *Processor) compile(mapping entities.MapList) []*replacement {
+	res := make([]*replacement, 0, len(mapping))
+	for _, item := range mapping {
+		key := entities.ParseMappingKey(item.Key)
+		switch {
+		case key.Regex:
+			re, err := l.regex(key.Text)
+			if err != nil {
+				logger.Error("Skipping invalid regex "+key.Text, err)
+				continue
+			}
+			res = append(res, &replacement{re: re, value: item.Value})
+		case key.Case:
+			for _, form := range expandCase(key.Text, item.Value) {
+				res = append(res, &replacement{old: form[0], value: form[1], word: key.Word})
+			}
+		default:
+			res = append(res, &replacement{old: key.Text, value: item.Value, word: key.Word})
+		}
 	}
-	// looks like `old` is not a regex, let's do a string replace then
-	return bytes.ReplaceAll(content, ([]byte)(old), ([]byte)(replacement))
+	return res
 }
 
-func (l *Processor) stringReplace(content string, old string, replacement string) string {
-	parts := strings.SplitN(old, ":", 2)
-	if len(parts) > 0 && parts[0] == "re" {
-		prev := regexp.MustCompile(parts[1])
-		return prev.ReplaceAllString(content, replacement)
+func (l *Processor) regex(expr string) (*regexp.Regexp, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if re, ok := l.regexes[expr]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
 	}
-	// looks like `old` is not a regex, let's do a string replace then
-	return strings.ReplaceAll(content, old, replacement)
+	l.regexes[expr] = re
+	return re, nil
 }
 
 func NewProcessor() *Processor {
-	return &Processor{}
+	return &Processor{regexes: map[string]*regexp.Regexp{}}
 }
 
 var _ usecases.SombraStringsPort = (*Processor)(nil)
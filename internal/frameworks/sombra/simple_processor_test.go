@@ -0,0 +1,141 @@
+package sombra
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+func TestProcessor_RenderTemplate(t *testing.T) {
+	vars := entities.Mappings{"name": "my-api"}
+	tests := []struct {
+		name     string
+		content  string
+		delims   []string
+		expected string
+		wantErr  bool
+	}{
+		{name: "sprig functions", content: "# {{ .name | title }}", expected: "# My-Api"},
+		{name: "custom delimiters keep the other templates", content: "[[ .name ]]: {{ .Values.name }}", delims: []string{"[[", "]]"}, expected: "my-api: {{ .Values.name }}"},
+		{name: "missing variable", content: "{{ .owner }}", wantErr: true},
+		{name: "invalid delimiters", content: "{{ .name }}", delims: []string{"[["}, wantErr: true},
+		{name: "invalid template", content: "{{ .name", wantErr: true},
+	}
+
+	processor := NewProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := processor.RenderTemplate("README.md", []byte(tt.content), tt.delims, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(res) != tt.expected {
+				t.Errorf("RenderTemplate() = %q, expected %q", res, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProcessor_ProcessContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		mapping  entities.MapList
+		expected string
+	}{
+		{
+			name:     "literal",
+			content:  "package acme",
+			mapping:  entities.MapList{{Key: "acme", Value: "billing"}},
+			expected: "package billing",
+		},
+		{
+			name:     "regex with groups",
+			content:  "version = 1.2.3\nname = acme\n",
+			mapping:  entities.MapList{{Key: `re:version = (\d+)\.\d+\.\d+`, Value: "version = ${1}.0.0"}},
+			expected: "version = 1.0.0\nname = acme\n",
+		},
+		{
+			name:     "invalid regex is skipped",
+			content:  "name = acme",
+			mapping:  entities.MapList{{Key: "re:(acme", Value: "x"}, {Key: "acme", Value: "billing"}},
+			expected: "name = billing",
+		},
+		{
+			name:    "case forms",
+			content: "type MyService struct{}\nmyService := NewMyService()\nmy_service my-service MY_SERVICE MYSERVICE myservice",
+			mapping: entities.MapList{{Key: "case:MyService", Value: "billing-api"}},
+			expected: "type BillingApi struct{}\nbillingApi := NewBillingApi()\n" +
+				"billing_api billing-api BILLING_API BILLINGAPI billingapi",
+		},
+	}
+
+	processor := NewProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := processor.ProcessContent("/main.go", []byte(tt.content), tt.mapping)
+			if string(res) != tt.expected {
+				t.Errorf("ProcessContent() = %q, expected %q", res, tt.expected)
+			}
+			if res := processor.ProcessString(tt.content, tt.mapping); res != tt.expected {
+				t.Errorf("ProcessString() = %q, expected %q", res, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProcessor_ProcessWords(t *testing.T) {
+	mapping := entities.MapList{{Key: "word:api", Value: "billing"}}
+	processor := NewProcessor()
+
+	tests := []struct {
+		file     string
+		content  string
+		expected string
+	}{
+		{file: "/main.go", content: "api := rapid(capital, api_v2, api.Run)", expected: "billing := rapid(capital, api_v2, billing.Run)"},
+		{file: "/main.py", content: "import api\n_api = api(api2)", expected: "import billing\n_api = billing(api2)"},
+		{file: "/index.ts", content: "const $api = api; // api", expected: "const $api = billing; // billing"},
+		{file: "/README.md", content: "The api, not the rapid $api", expected: "The billing, not the rapid $billing"},
+	}
+	for _, tt := range tests {
+		if res := processor.ProcessContent(entities.File(tt.file), []byte(tt.content), mapping); string(res) != tt.expected {
+			t.Errorf("ProcessContent(%s) = %q, expected %q", tt.file, res, tt.expected)
+		}
+	}
+
+	paths := map[string]string{
+		"/cmd/api/":       "/cmd/billing/",
+		"/cmd/rapid/":     "/cmd/rapid/",
+		"api_test.go":     "billing_test.go",
+		"my-api.yaml":     "my-billing.yaml",
+		"apis/capital.go": "apis/capital.go",
+	}
+	for path, expected := range paths {
+		if res := processor.ProcessString(path, mapping); res != expected {
+			t.Errorf("ProcessString(%s) = %q, expected %q", path, res, expected)
+		}
+	}
+
+	// only the sides ending with a word rune are checked
+	res := processor.ProcessContent("/main.go", []byte("x.acme.com y.acme.community"), entities.MapList{{Key: "word:.acme.com", Value: ".example.org"}})
+	if string(res) != "x.example.org y.acme.community" {
+		t.Errorf("ProcessContent() = %q", res)
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := map[string][]string{
+		"MyService":     {"My", "Service"},
+		"HTTPServer":    {"HTTP", "Server"},
+		"my_service-v2": {"my", "service", "v2"},
+		"billing api":   {"billing", "api"},
+		"userID":        {"user", "ID"},
+	}
+	for input, expected := range tests {
+		if res := words(input); !reflect.DeepEqual(res, expected) {
+			t.Errorf("words(%q) = %v, expected %v", input, res, expected)
+		}
+	}
+}
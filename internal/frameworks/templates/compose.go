@@ -0,0 +1,121 @@
+package templates
+
+import (
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"path/filepath"
+	"strings"
+)
+
+type readDef func(fn entities.File) (*entities.TemplateDef, error)
+
+// composer merges a definition with the ones it extends and includes.
+// The extended definition comes first, then the fragments in order, then the definition itself,
+// so the patterns of the definition are combined last and its mappings win.
+type composer struct {
+	read readDef
+	// root is the template directory, the definitions cannot be read from outside it
+	root string
+	// chain holds the definitions being composed, to find the cycles
+	chain []string
+	// merged holds the definitions already merged, a fragment reached twice is merged once
+	merged map[string]bool
+}
+
+func (c *DirectoryTemplateDefService) compose(def entities.File, read readDef) (*entities.TemplateDef, error) {
+	comp := &composer{read: read, root: templateRoot(string(def)), merged: map[string]bool{}}
+	res := &entities.TemplateDef{}
+	err := comp.merge(res, string(def))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (comp *composer) merge(res *entities.TemplateDef, fn string) error {
+	fn = filepath.Clean(fn)
+	for i, parent := range comp.chain {
+		if parent == fn {
+			var cycle []string
+			for _, name := range append(comp.chain[i:], fn) {
+				cycle = append(cycle, filepath.Base(name))
+			}
+			return fmt.Errorf("template definition cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	if comp.merged[fn] {
+		return nil
+	}
+
+	def, err := comp.read(entities.File(fn))
+	if err != nil {
+		return err
+	}
+	if def == nil {
+		def = &entities.TemplateDef{}
+	}
+
+	comp.chain = append(comp.chain, fn)
+	dir := filepath.Dir(fn)
+	parents := def.Include
+	if def.Extends != "" {
+		parents = append([]string{def.Extends}, parents...)
+	}
+	for _, parent := range parents {
+		parent, err = comp.resolve(dir, parent)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(fn), err)
+		}
+		logger.Info(fmt.Sprintf("Merging template definition %s into %s", parent, fn))
+		err = comp.merge(res, parent)
+		if err != nil {
+			return err
+		}
+	}
+	comp.chain = comp.chain[:len(comp.chain)-1]
+	comp.merged[fn] = true
+
+	for _, v := range def.Vars {
+		if i := index(res.Vars, v.Name); i >= 0 {
+			// the variable keeps its place, with the schema of the last definition
+			res.Vars[i] = v
+			continue
+		}
+		res.Vars = append(res.Vars, v)
+	}
+	res.Patterns = append(res.Patterns, def.Patterns...)
+	return nil
+}
+
+// resolve joins a path of extends or include to the directory of the definition, it must stay in the template,
+// so the composition does not depend on the files of the host
+func (comp *composer) resolve(dir, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("the template definition %s must be a relative path", name)
+	}
+	res := filepath.Join(dir, name)
+	rel, err := filepath.Rel(comp.root, res)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the template definition %s is outside the template directory", name)
+	}
+	return res, nil
+}
+
+// templateRoot is the directory holding the .sombra directory of the definition
+func templateRoot(def string) string {
+	dir := filepath.Dir(filepath.Clean(def))
+	if filepath.Base(dir) == ".sombra" {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+func index(vars []*entities.Var, name string) int {
+	for i, v := range vars {
+		if v.Name == name {
+			return i
+		}
+	}
+	return -1
+}
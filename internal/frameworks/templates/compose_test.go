@@ -0,0 +1,166 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+func writeDefs(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		fn := filepath.Join(dir, ".sombra", name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func patterns(def *entities.TemplateDef) []string {
+	var res []string
+	for _, pattern := range def.Patterns {
+		res = append(res, string(pattern.Pattern))
+	}
+	return res
+}
+
+func names(def *entities.TemplateDef) []string {
+	var res []string
+	for _, v := range def.Vars {
+		res = append(res, v.Name)
+	}
+	return res
+}
+
+func TestDirectoryTemplateDefService_Compose(t *testing.T) {
+	dir := writeDefs(t, map[string]string{
+		"default.yaml": `vars: [name]
+patterns:
+  - pattern: "src/**"
+`,
+		"full.yaml": `extends: default.yaml
+include: [shared/licence.yaml, shared/ci.yaml]
+vars:
+  - name
+  - name: owner
+    description: Owner of the licence
+    default: "{{ .name }} team"
+patterns:
+  - pattern: "docs/**"
+    default:
+      "{{ .name }}": name
+`,
+		"shared/licence.yaml": `vars: [owner]
+patterns:
+  - pattern: LICENSE
+`,
+		"shared/ci.yaml": `include: [licence.yaml]
+vars: [ci]
+patterns:
+  - pattern: ".github/**"
+`,
+	})
+	service := NewDefService()
+	fn := service.GetFile(dir, "full")
+
+	def, err := service.Load(fn)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if expected := []string{"name", "owner", "ci"}; !reflect.DeepEqual(names(def), expected) {
+		t.Errorf("Load() vars = %v, expected %v", names(def), expected)
+	}
+	if owner := def.Vars[1]; owner.Description != "Owner of the licence" || owner.Default != "{{ .name }} team" {
+		t.Errorf("Expected the schema of the last definition, got %v", owner)
+	}
+	if expected := []string{"src/**", "LICENSE", ".github/**", "docs/**"}; !reflect.DeepEqual(patterns(def), expected) {
+		t.Errorf("Load() patterns = %v, expected %v", patterns(def), expected)
+	}
+	if def.Extends != "" || def.Include != nil {
+		t.Errorf("Expected a flat definition, got %v %v", def.Extends, def.Include)
+	}
+
+	def, err = service.Render(fn, entities.Mappings{"name": "api"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if last := def.Patterns[len(def.Patterns)-1]; last.Default["api"] != "name" {
+		t.Errorf("Render() default = %v, expected the rendered variables", last.Default)
+	}
+}
+
+func TestDirectoryTemplateDefService_RenderValue(t *testing.T) {
+	service := NewDefService()
+
+	res, err := service.RenderValue("{{ .name | upper }}-{{ .missing }}", entities.Mappings{"name": "api"})
+	if err != nil || res != "API-" {
+		t.Errorf("RenderValue() = %q, %v", res, err)
+	}
+}
+
+func TestDirectoryTemplateDefService_ComposeCycle(t *testing.T) {
+	dir := writeDefs(t, map[string]string{
+		"default.yaml": "extends: base.yaml\npatterns: []\n",
+		"base.yaml":    "include: [default.yaml]\npatterns: []\n",
+	})
+	service := NewDefService()
+
+	_, err := service.Load(service.GetFile(dir, ""))
+	if err == nil || !strings.Contains(err.Error(), "default.yaml -> base.yaml -> default.yaml") {
+		t.Errorf("Expected a cycle error, got %v", err)
+	}
+}
+
+func TestDirectoryTemplateDefService_RenderInvalidRegex(t *testing.T) {
+	service := NewDefService()
+
+	for content, expected := range map[string]string{
+		"patterns:\n  - pattern: \"**\"\n    content:\n      \"re:(acme\": billing\n":     "invalid regex in the mapping",
+		"patterns:\n  - pattern: \"**\"\n    content:\n      \"re:case:Acme\": billing\n": "mixes re: with case: or word:",
+		"patterns:\n  - pattern: \"**\"\n    content:\n      \"word:re:acme\": billing\n": "mixes re: with case: or word:",
+	} {
+		dir := writeDefs(t, map[string]string{"default.yaml": content})
+		_, err := service.Render(service.GetFile(dir, ""), entities.Mappings{})
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Render() error = %v, expected %s", err, expected)
+		}
+	}
+}
+
+func TestDirectoryTemplateDefService_ComposeOutside(t *testing.T) {
+	service := NewDefService()
+
+	for include, expected := range map[string]string{
+		"../../outside.yaml": "is outside the template directory",
+		"/etc/sombra.yaml":   "must be a relative path",
+		"../shared/ci.yaml":  "",
+	} {
+		dir := writeDefs(t, map[string]string{"default.yaml": "include: [" + include + "]\npatterns:\n  - pattern: \"src/**\"\n"})
+		shared := filepath.Join(dir, "shared", "ci.yaml")
+		if err := os.MkdirAll(filepath.Dir(shared), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(shared, []byte("patterns:\n  - pattern: \".github/**\"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		def, err := service.Load(service.GetFile(dir, ""))
+		if expected == "" {
+			if err != nil || !reflect.DeepEqual(patterns(def), []string{".github/**", "src/**"}) {
+				t.Errorf("Load() with %s = %v, %v", include, def, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Load() with %s error = %v, expected %s", include, err, expected)
+		}
+	}
+}
@@ -11,17 +11,46 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 	"text/template"
 )
 
 type DirectoryTemplateDefService struct {
 }
 
-func (c *DirectoryTemplateDefService) GetFile(dir string) entities.File {
-	return entities.File(filepath.Join(dir, ".sombra", "default.yaml"))
+func (c *DirectoryTemplateDefService) GetFile(dir, flavor string) entities.File {
+	if flavor == "" {
+		flavor = entities.DefaultFlavor
+	}
+	return entities.File(filepath.Join(dir, ".sombra", flavor+".yaml"))
+}
+
+func (c *DirectoryTemplateDefService) ListFlavors(dir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, ".sombra"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template definitions: %w", err)
+	}
+
+	var flavors []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".yaml" {
+			continue
+		}
+		flavors = append(flavors, strings.TrimSuffix(name, ".yaml"))
+	}
+	sort.Strings(flavors)
+	return flavors, nil
 }
 
+// Load reads the definition with the definitions it extends or includes
 func (c *DirectoryTemplateDefService) Load(def entities.File) (*entities.TemplateDef, error) {
+	return c.compose(def, c.load)
+}
+
+func (c *DirectoryTemplateDefService) load(def entities.File) (*entities.TemplateDef, error) {
 	// Open the file
 	file, err := os.Open(string(def))
 	if err != nil {
@@ -70,7 +99,50 @@ func (c *DirectoryTemplateDefService) Save(def entities.File, templateDef *entit
 	return nil
 }
 
+// Render renders the definition and the definitions it extends or includes with the same variables
 func (c *DirectoryTemplateDefService) Render(def entities.File, vars entities.Mappings) (*entities.TemplateDef, error) {
+	res, err := c.compose(def, func(fn entities.File) (*entities.TemplateDef, error) {
+		return c.render(fn, vars)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.checkMappings(res)
+	if err != nil {
+		logger.Error("Invalid mappings in "+string(def), err)
+		return nil, err
+	}
+	return res, nil
+}
+
+// checkMappings compiles the regular expressions of the mappings, so an invalid one fails before any file is changed
+func (c *DirectoryTemplateDefService) checkMappings(def *entities.TemplateDef) error {
+	for _, pattern := range def.Patterns {
+		for _, mappings := range []entities.Mappings{pattern.Default, pattern.Path, pattern.Name, pattern.Content} {
+			keys := make([]string, 0, len(mappings))
+			for key := range mappings {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				parsed := entities.ParseMappingKey(key)
+				if parsed.Regex && (parsed.Case || parsed.Word) {
+					return fmt.Errorf("the mapping %q of %s mixes re: with case: or word:, a regex can use \\b for the words", key, pattern.Pattern)
+				}
+				if !parsed.Regex {
+					continue
+				}
+				if _, err := regexp.Compile(parsed.Text); err != nil {
+					return fmt.Errorf("invalid regex in the mapping %q of %s: %w", key, pattern.Pattern, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *DirectoryTemplateDefService) render(def entities.File, vars entities.Mappings) (*entities.TemplateDef, error) {
 	var conf *entities.TemplateDef
 
 	fn := string(def)
@@ -108,6 +180,23 @@ func (c *DirectoryTemplateDefService) Render(def entities.File, vars entities.Ma
 	return conf, nil
 }
 
+func (c *DirectoryTemplateDefService) RenderValue(value string, vars entities.Mappings) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	tmp, err := template.New("value").Funcs(sprig.FuncMap()).Option("missingkey=zero").Parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	buf := bytes.NewBufferString("")
+	err = tmp.Execute(buf, vars)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 func (c *DirectoryTemplateDefService) ensureDir(destDir string, _ os.FileInfo) error {
 	var err error
 	if _, err = os.Stat(destDir); os.IsNotExist(err) {
@@ -0,0 +1,164 @@
+package textdiff
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"strings"
+)
+
+// hunk is a changed region, base lines [baseStart, baseEnd) became side lines [sideStart, sideEnd)
+type hunk struct {
+	baseStart, baseEnd int
+	sideStart, sideEnd int
+	theirs             bool
+}
+
+type MergeService struct {
+}
+
+// Merge applies the changes from base to theirs over ours, overlapping changes are written as conflict blocks
+func (s *MergeService) Merge(base, ours, theirs []byte, oursLabel, theirsLabel string) *entities.MergeResult {
+	baseLines := splitLines(base)
+	oursLines := splitLines(ours)
+	theirsLines := splitLines(theirs)
+
+	hunks := s.mergeHunks(
+		s.hunks(diffLines(baseLines, oursLines), false),
+		s.hunks(diffLines(baseLines, theirsLines), true),
+	)
+
+	res := &entities.MergeResult{}
+	var out strings.Builder
+	pos := 0
+	oursOffset, theirsOffset := 0, 0
+
+	for i := 0; i < len(hunks); {
+		// collect every hunk overlapping or touching the current region
+		start, end := hunks[i].baseStart, hunks[i].baseEnd
+		group := []hunk{hunks[i]}
+		i++
+		for i < len(hunks) && hunks[i].baseStart <= end {
+			end = max(end, hunks[i].baseEnd)
+			group = append(group, hunks[i])
+			i++
+		}
+
+		// unchanged lines before the region
+		for _, line := range baseLines[pos:start] {
+			out.WriteString(line)
+		}
+		pos = end
+
+		oursStart, theirsStart := start+oursOffset, start+theirsOffset
+		changedOurs, changedTheirs := false, false
+		for _, h := range group {
+			if h.theirs {
+				theirsOffset += (h.sideEnd - h.sideStart) - (h.baseEnd - h.baseStart)
+				changedTheirs = true
+			} else {
+				oursOffset += (h.sideEnd - h.sideStart) - (h.baseEnd - h.baseStart)
+				changedOurs = true
+			}
+		}
+		oursChunk := oursLines[oursStart : end+oursOffset]
+		theirsChunk := theirsLines[theirsStart : end+theirsOffset]
+
+		switch {
+		case !changedTheirs:
+			s.write(&out, oursChunk)
+		case !changedOurs:
+			s.write(&out, theirsChunk)
+		case s.equal(oursChunk, theirsChunk):
+			s.write(&out, oursChunk)
+		default:
+			res.Conflicts++
+			out.WriteString("<<<<<<< " + oursLabel + "\n")
+			s.writeBlock(&out, oursChunk)
+			out.WriteString("=======\n")
+			s.writeBlock(&out, theirsChunk)
+			out.WriteString(">>>>>>> " + theirsLabel + "\n")
+		}
+	}
+
+	for _, line := range baseLines[pos:] {
+		out.WriteString(line)
+	}
+
+	res.Content = []byte(out.String())
+	return res
+}
+
+// hunks converts an edit script into the list of changed regions
+func (s *MergeService) hunks(ops []op, theirs bool) []hunk {
+	res := make([]hunk, 0)
+	var current *hunk
+	for _, item := range ops {
+		if item.kind == opEqual {
+			if current != nil {
+				res = append(res, *current)
+				current = nil
+			}
+			continue
+		}
+		if current == nil {
+			current = &hunk{baseStart: item.old, baseEnd: item.old, sideStart: item.new, sideEnd: item.new, theirs: theirs}
+		}
+		if item.kind == opDelete {
+			current.baseEnd++
+		} else {
+			current.sideEnd++
+		}
+	}
+	if current != nil {
+		res = append(res, *current)
+	}
+	return res
+}
+
+// mergeHunks sorts the hunks of both sides by their position in base
+func (s *MergeService) mergeHunks(ours, theirs []hunk) []hunk {
+	res := make([]hunk, 0, len(ours)+len(theirs))
+	i, j := 0, 0
+	for i < len(ours) || j < len(theirs) {
+		if j >= len(theirs) || (i < len(ours) && ours[i].baseStart <= theirs[j].baseStart) {
+			res = append(res, ours[i])
+			i++
+		} else {
+			res = append(res, theirs[j])
+			j++
+		}
+	}
+	return res
+}
+
+func (s *MergeService) equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *MergeService) write(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+// writeBlock writes the lines of a conflict side, the markers must always start in a new line
+func (s *MergeService) writeBlock(out *strings.Builder, lines []string) {
+	s.write(out, lines)
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		out.WriteString("\n")
+	}
+}
+
+func NewMergeService() *MergeService {
+	return &MergeService{}
+}
+
+var _ usecases.MergePort = (*MergeService)(nil)
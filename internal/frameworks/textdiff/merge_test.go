@@ -0,0 +1,74 @@
+package textdiff
+
+import (
+	"testing"
+)
+
+func TestMergeService_Merge(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		content   string
+		conflicts int
+	}{
+		{
+			name:    "no changes",
+			base:    "a\nb\nc\n",
+			ours:    "a\nb\nc\n",
+			theirs:  "a\nb\nc\n",
+			content: "a\nb\nc\n",
+		},
+		{
+			name:    "only theirs changed",
+			base:    "a\nb\nc\n",
+			ours:    "a\nb\nc\n",
+			theirs:  "a\nB\nc\n",
+			content: "a\nB\nc\n",
+		},
+		{
+			name:    "independent changes are combined",
+			base:    "1\n2\n3\n4\n5\n6\n7\n",
+			ours:    "one\n2\n3\n4\n5\n6\n7\n",
+			theirs:  "1\n2\n3\n4\n5\n6\nseven\n",
+			content: "one\n2\n3\n4\n5\n6\nseven\n",
+		},
+		{
+			name:    "same change on both sides",
+			base:    "a\nb\nc\n",
+			ours:    "a\nx\nc\n",
+			theirs:  "a\nx\nc\n",
+			content: "a\nx\nc\n",
+		},
+		{
+			name:      "overlapping changes conflict",
+			base:      "a\nb\nc\n",
+			ours:      "a\nours\nc\n",
+			theirs:    "a\ntheirs\nc\n",
+			content:   "a\n<<<<<<< local\nours\n=======\ntheirs\n>>>>>>> v2\nc\n",
+			conflicts: 1,
+		},
+		{
+			name:      "empty base with different files",
+			base:      "",
+			ours:      "ours",
+			theirs:    "theirs\n",
+			content:   "<<<<<<< local\nours\n=======\ntheirs\n>>>>>>> v2\n",
+			conflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := NewMergeService().Merge([]byte(tt.base), []byte(tt.ours), []byte(tt.theirs), "local", "v2")
+
+			if string(res.Content) != tt.content {
+				t.Errorf("Expected content\n%s\nbut got\n%s", tt.content, res.Content)
+			}
+			if res.Conflicts != tt.conflicts {
+				t.Errorf("Expected %d conflicts, got %d", tt.conflicts, res.Conflicts)
+			}
+		})
+	}
+}
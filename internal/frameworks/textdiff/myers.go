@@ -0,0 +1,164 @@
+package textdiff
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is a single line operation, old and new are the line indexes in each side
+type op struct {
+	kind opKind
+	old  int
+	new  int
+}
+
+// splitLines splits the content keeping the line terminators, so the last line may not end with '\n'
+func splitLines(content []byte) []string {
+	lines := make([]string, 0)
+	start := 0
+	for i, c := range content {
+		if c == '\n' {
+			lines = append(lines, string(content[start:i+1]))
+			start = i + 1
+		}
+	}
+	if start < len(content) {
+		lines = append(lines, string(content[start:]))
+	}
+	return lines
+}
+
+// maxSteps bounds the edits searched from each end, past it the block is replaced as a whole.
+// The time is O((N+M)*D), a rewrite of a large file would take minutes for a script nobody reads line by line.
+const maxSteps = 1024
+
+// diffLines computes the shortest edit script between a and b using the linear space variant of the Myers algorithm,
+// the middle of the script is found from both ends and each half is solved in turn, so the memory is O(N+M)
+func diffLines(a, b []string) []op {
+	size := len(a) + len(b) + 4
+	d := &differ{a: a, b: b, forward: make([]int, size), reverse: make([]int, size), ops: make([]op, 0, len(a)+len(b))}
+	d.diff(0, len(a), 0, len(b))
+	return d.ops
+}
+
+// differ holds the diagonals of both searches, they are reused by every step of the recursion
+type differ struct {
+	a, b             []string
+	forward, reverse []int
+	ops              []op
+}
+
+func (d *differ) diff(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.ops = append(d.ops, op{kind: opEqual, old: aLo, new: bLo})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-suffix-1] == d.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	x, y := -1, -1
+	if aLo < aHi && bLo < bHi {
+		x, y = d.bisect(aLo, aHi, bLo, bHi)
+	}
+	if x >= 0 {
+		d.diff(aLo, x, bLo, y)
+		d.diff(x, aHi, y, bHi)
+	} else {
+		// one of the sides is empty, nothing is shared or the script is too long
+		for i := aLo; i < aHi; i++ {
+			d.ops = append(d.ops, op{kind: opDelete, old: i, new: bLo})
+		}
+		for j := bLo; j < bHi; j++ {
+			d.ops = append(d.ops, op{kind: opInsert, old: aHi, new: j})
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.ops = append(d.ops, op{kind: opEqual, old: aHi + i, new: bHi + i})
+	}
+}
+
+// bisect returns the point where the forward and the reverse paths of the edit script meet, or -1 when they do not
+// or when they need more than maxSteps edits each.
+// The sides have no common prefix or suffix, so the point is always inside and both halves are smaller.
+func (d *differ) bisect(aLo, aHi, bLo, bHi int) (int, int) {
+	a, b := d.a[aLo:aHi], d.b[bLo:bHi]
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset, length := maxD, 2*maxD+2
+	forward, reverse := d.forward[:length], d.reverse[:length]
+	for i := range forward {
+		forward[i], reverse[i] = -1, -1
+	}
+	forward[offset+1], reverse[offset+1] = 0, 0
+
+	// an odd delta makes the paths meet in the forward search
+	delta := n - m
+	front := delta%2 != 0
+	// the diagonals going out of the grid are not searched again
+	fStart, fEnd, rStart, rEnd := 0, 0, 0, 0
+	for step := 0; step < min(maxD, maxSteps); step++ {
+		for k := -step + fStart; k <= step-fEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -step || (k != step && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case front:
+				j := offset + delta - k
+				if j >= 0 && j < length && reverse[j] != -1 && x >= n-reverse[j] {
+					return aLo + x, bLo + y
+				}
+			}
+		}
+
+		for k := -step + rStart; k <= step-rEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -step || (k != step && reverse[i-1] < reverse[i+1]) {
+				x = reverse[i+1]
+			} else {
+				x = reverse[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			reverse[i] = x
+			switch {
+			case x > n:
+				rEnd += 2
+			case y > m:
+				rStart += 2
+			case !front:
+				j := offset + delta - k
+				if j >= 0 && j < length && forward[j] != -1 && forward[j] >= n-x {
+					fx := forward[j]
+					return aLo + fx, bLo + fx - (j - offset)
+				}
+			}
+		}
+	}
+	return -1, -1
+}
@@ -0,0 +1,92 @@
+package textdiff
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// lcs is the length of the longest common subsequence, the shortest script keeps all of it
+func lcs(a, b []string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// checkScript rebuilds both sides from the script and returns the number of equal lines
+func checkScript(t *testing.T, a, b []string, ops []op) int {
+	var old, new []string
+	equal := 0
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			if a[o.old] != b[o.new] {
+				t.Fatalf("Equal op %+v joins different lines", o)
+			}
+			old, new = append(old, a[o.old]), append(new, b[o.new])
+			equal++
+		case opDelete:
+			old = append(old, a[o.old])
+		case opInsert:
+			new = append(new, b[o.new])
+		}
+	}
+	if fmt.Sprint(old) != fmt.Sprint(a) || fmt.Sprint(new) != fmt.Sprint(b) {
+		t.Fatalf("The script of %v -> %v rebuilds %v -> %v", a, b, old, new)
+	}
+	return equal
+}
+
+func TestDiffLines_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	lines := func() []string {
+		res := make([]string, rnd.Intn(30))
+		for i := range res {
+			res[i] = string(rune('a' + rnd.Intn(4)))
+		}
+		return res
+	}
+
+	for run := 0; run < 1000; run++ {
+		a, b := lines(), lines()
+		if equal := checkScript(t, a, b, diffLines(a, b)); equal != lcs(a, b) {
+			t.Fatalf("diffLines(%v, %v) keeps %d lines, expected %d", a, b, equal, lcs(a, b))
+		}
+	}
+}
+
+func TestDiffLines_Rewrite(t *testing.T) {
+	// a full rewrite has the largest script, the trace of every step would take gigabytes
+	a, b := make([]string, 50000), make([]string, 50000)
+	for i := range a {
+		a[i] = fmt.Sprintf("old %d\n", i)
+		b[i] = fmt.Sprintf("new %d\n", i)
+	}
+	b[25000] = a[10000]
+
+	// the script is longer than maxSteps, the file is replaced as a whole
+	if equal := checkScript(t, a, b, diffLines(a, b)); equal != 0 {
+		t.Errorf("Expected the file to be replaced, got %d equal lines", equal)
+	}
+
+	// the changes of a large file are still found line by line
+	b = append([]string{}, a...)
+	for i := 0; i < len(b); i += 100 {
+		b[i] = fmt.Sprintf("new %d\n", i)
+	}
+	if equal := checkScript(t, a, b, diffLines(a, b)); equal != len(a)-len(a)/100 {
+		t.Errorf("Expected %d equal lines, got %d", len(a)-len(a)/100, equal)
+	}
+}
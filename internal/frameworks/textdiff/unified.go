@@ -0,0 +1,118 @@
+package textdiff
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"strings"
+)
+
+const noNewline = "\\ No newline at end of file\n"
+
+type UnifiedService struct {
+	context int
+}
+
+// Unified returns the unified diff between old and new, the patch is empty when both are equal
+func (s *UnifiedService) Unified(fromFile, toFile string, old, new []byte) *entities.TextDiff {
+	a := splitLines(old)
+	b := splitLines(new)
+	ops := diffLines(a, b)
+
+	res := &entities.TextDiff{}
+	for _, item := range ops {
+		switch item.kind {
+		case opInsert:
+			res.Added++
+		case opDelete:
+			res.Removed++
+		}
+	}
+	if res.Added == 0 && res.Removed == 0 {
+		return res
+	}
+
+	var buf bytes.Buffer
+	_, _ = fmt.Fprintf(&buf, "--- %s\n+++ %s\n", fromFile, toFile)
+	for _, hunk := range s.hunks(ops) {
+		s.writeHunk(&buf, hunk, a, b)
+	}
+	res.Patch = buf.Bytes()
+	return res
+}
+
+// hunks groups the operations around each change keeping the configured context
+func (s *UnifiedService) hunks(ops []op) [][]op {
+	res := make([][]op, 0)
+	start, end := -1, -1
+	for i, item := range ops {
+		if item.kind == opEqual {
+			continue
+		}
+		from := max(i-s.context, 0)
+		to := min(i+s.context+1, len(ops))
+		if start >= 0 && from <= end {
+			end = to
+			continue
+		}
+		if start >= 0 {
+			res = append(res, ops[start:end])
+		}
+		start, end = from, to
+	}
+	if start >= 0 {
+		res = append(res, ops[start:end])
+	}
+	return res
+}
+
+func (s *UnifiedService) writeHunk(buf *bytes.Buffer, hunk []op, a, b []string) {
+	oldStart, newStart := hunk[0].old, hunk[0].new
+	oldCount, newCount := 0, 0
+	for _, item := range hunk {
+		if item.kind != opInsert {
+			oldCount++
+		}
+		if item.kind != opDelete {
+			newCount++
+		}
+	}
+
+	_, _ = fmt.Fprintf(buf, "@@ -%s +%s @@\n", s.rangeOf(oldStart, oldCount), s.rangeOf(newStart, newCount))
+	for _, item := range hunk {
+		switch item.kind {
+		case opEqual:
+			s.writeLine(buf, ' ', a[item.old])
+		case opDelete:
+			s.writeLine(buf, '-', a[item.old])
+		case opInsert:
+			s.writeLine(buf, '+', b[item.new])
+		}
+	}
+}
+
+func (s *UnifiedService) rangeOf(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func (s *UnifiedService) writeLine(buf *bytes.Buffer, prefix byte, line string) {
+	buf.WriteByte(prefix)
+	buf.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		buf.WriteString("\n")
+		buf.WriteString(noNewline)
+	}
+}
+
+func NewUnifiedService() *UnifiedService {
+	return &UnifiedService{context: 3}
+}
+
+var _ usecases.TextDiffPort = (*UnifiedService)(nil)
@@ -0,0 +1,67 @@
+package textdiff
+
+import (
+	"testing"
+)
+
+func TestUnifiedService_Unified(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		patch   string
+		added   int
+		removed int
+	}{
+		{
+			name: "equal content",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+		},
+		{
+			name:    "new file",
+			old:     "",
+			new:     "a\nb\n",
+			patch:   "--- a/f\n+++ b/f\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+			added:   2,
+			removed: 0,
+		},
+		{
+			name:    "change in the middle keeps context",
+			old:     "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			new:     "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			patch:   "--- a/f\n+++ b/f\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+			added:   1,
+			removed: 1,
+		},
+		{
+			name:    "distant changes produce two hunks",
+			old:     "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:     "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			patch:   "--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+			added:   2,
+			removed: 2,
+		},
+		{
+			name:    "missing newline at end of file",
+			old:     "a\nb",
+			new:     "a\nb\n",
+			patch:   "--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+			added:   1,
+			removed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := NewUnifiedService().Unified("a/f", "b/f", []byte(tt.old), []byte(tt.new))
+
+			if string(res.Patch) != tt.patch {
+				t.Errorf("Expected patch\n%s\nbut got\n%s", tt.patch, res.Patch)
+			}
+			if res.Added != tt.added || res.Removed != tt.removed {
+				t.Errorf("Expected +%d -%d, got +%d -%d", tt.added, tt.removed, res.Added, res.Removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package vars
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+)
+
+// ChainReader asks the readers in order, the first one with a value wins
+type ChainReader struct {
+	readers []usecases.VariableReaderPort
+}
+
+func (l *ChainReader) GetValue(v *entities.Var, def string, validate func(string) error) (string, bool, error) {
+	for _, reader := range l.readers {
+		value, ok, err := reader.GetValue(v, def, validate)
+		if err != nil || ok {
+			return value, ok, err
+		}
+	}
+	return "", false, nil
+}
+
+func NewChainReader(readers ...usecases.VariableReaderPort) *ChainReader {
+	return &ChainReader{readers: readers}
+}
+
+var _ usecases.VariableReaderPort = (*ChainReader)(nil)
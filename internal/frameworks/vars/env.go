@@ -0,0 +1,38 @@
+package vars
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"os"
+	"strings"
+)
+
+const envPrefix = "SOMBRA_VAR_"
+
+// EnvReader reads the variables from SOMBRA_VAR_<NAME>, the name in upper case with _ for the other characters
+type EnvReader struct {
+}
+
+func (l *EnvReader) GetValue(v *entities.Var, _ string, _ func(string) error) (string, bool, error) {
+	value, ok := os.LookupEnv(envName(v.Name))
+	return value, ok, nil
+}
+
+func envName(name string) string {
+	return envPrefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+func NewEnvReader() *EnvReader {
+	return &EnvReader{}
+}
+
+var _ usecases.VariableReaderPort = (*EnvReader)(nil)
@@ -5,28 +5,71 @@ import (
 	"fmt"
 	"github.com/sombrahq/sombra-cli/internal/core/entities"
 	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"io"
 	"os"
 	"strings"
 )
 
 type FileReader struct {
-	file *os.File
+	in  *bufio.Reader
+	out io.Writer
 }
 
-func (l *FileReader) GetValues(vars []string) *entities.Mappings {
-	reader := bufio.NewReader(l.file)
-	mappings := make(entities.Mappings)
-	for _, varName := range vars {
-		fmt.Printf("Enter value for %s: ", varName)
-		text, _ := reader.ReadString('\n')
-		mappings[varName] = strings.Trim(text, "\n")
+// GetValue prompts for the variable until the value is valid, an empty answer takes the default
+func (l *FileReader) GetValue(v *entities.Var, def string, validate func(string) error) (string, bool, error) {
+	for {
+		_, _ = fmt.Fprint(l.out, l.prompt(v, def))
+		text, err := l.in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", false, err
+		}
+		value := strings.TrimRight(text, "\r\n")
+		if value == "" {
+			value = def
+		}
+		if err == io.EOF && text == "" {
+			// nothing left to read, the default is used if there is one
+			_, _ = fmt.Fprintln(l.out)
+			return "", false, nil
+		}
+
+		invalid := validate(value)
+		if invalid == nil {
+			return value, true, nil
+		}
+		_, _ = fmt.Fprintf(l.out, "Invalid value: %s\n", invalid)
+		if err == io.EOF {
+			return value, true, nil
+		}
+	}
+}
+
+// prompt tells the description, the choices and the default of the variable
+func (l *FileReader) prompt(v *entities.Var, def string) string {
+	var sb strings.Builder
+	sb.WriteString("Enter value for " + v.Name)
+	if v.Description != "" {
+		sb.WriteString(" (" + v.Description + ")")
+	}
+	switch {
+	case len(v.Choices) > 0:
+		sb.WriteString(" {" + strings.Join(v.Choices, ", ") + "}")
+	case v.Type == entities.VarBool:
+		sb.WriteString(" {true, false}")
+	case v.Type == entities.VarList:
+		sb.WriteString(" (comma separated)")
+	}
+	if def != "" {
+		sb.WriteString(" [" + def + "]")
 	}
-	return &mappings
+	sb.WriteString(": ")
+	return sb.String()
 }
 
 func NewReader() *FileReader {
 	return &FileReader{
-		file: os.Stdin,
+		in:  bufio.NewReader(os.Stdin),
+		out: os.Stdout,
 	}
 }
 
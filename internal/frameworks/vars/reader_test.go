@@ -0,0 +1,69 @@
+package vars
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+func TestFileReader_GetValue(t *testing.T) {
+	validate := func(value string) error {
+		if value != "mysql" && value != "postgres" {
+			return fmt.Errorf("db must be one of postgres, mysql")
+		}
+		return nil
+	}
+	db := &entities.Var{Name: "db", Description: "Database", Type: entities.VarChoice, Choices: []string{"postgres", "mysql"}}
+
+	tests := []struct {
+		name     string
+		input    string
+		def      string
+		expected string
+		ok       bool
+		prompt   string
+	}{
+		{
+			name:     "asks again until the value is valid",
+			input:    "oracle\nmysql\n",
+			expected: "mysql",
+			ok:       true,
+			prompt:   "Enter value for db (Database) {postgres, mysql}: Invalid value: db must be one of postgres, mysql\nEnter value for db",
+		},
+		{
+			name:     "empty answer takes the default",
+			input:    "\n",
+			def:      "postgres",
+			expected: "postgres",
+			ok:       true,
+			prompt:   "{postgres, mysql} [postgres]: ",
+		},
+		{
+			name:  "no input left",
+			input: "",
+			ok:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			reader := &FileReader{in: bufio.NewReader(strings.NewReader(tt.input)), out: out}
+
+			value, ok, err := reader.GetValue(db, tt.def, validate)
+			if err != nil {
+				t.Fatalf("GetValue() error = %v", err)
+			}
+			if value != tt.expected || ok != tt.ok {
+				t.Errorf("GetValue() = %q, %v, expected %q, %v", value, ok, tt.expected, tt.ok)
+			}
+			if !strings.Contains(out.String(), tt.prompt) {
+				t.Errorf("Expected %q in the prompt, got %q", tt.prompt, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package vars
+
+import (
+	"fmt"
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/logger"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+// ValuesReader reads the variables given beforehand, with --var or --vars-file
+type ValuesReader struct {
+	values entities.Mappings
+}
+
+func (l *ValuesReader) GetValue(v *entities.Var, _ string, _ func(string) error) (string, bool, error) {
+	value, ok := l.values[v.Name]
+	return value, ok, nil
+}
+
+// NewArgsReader reads the key=value pairs of the command line
+func NewArgsReader(pairs []string) (*ValuesReader, error) {
+	values := entities.Mappings{}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected key=value", pair)
+		}
+		values[key] = value
+	}
+	return &ValuesReader{values: values}, nil
+}
+
+// LoadValues reads a YAML file of variables, the lists are joined with commas like the list variables
+func LoadValues(fn string) (*ValuesReader, error) {
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		logger.Error("Failed to read vars file "+fn, err)
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	err = yaml.Unmarshal(data, &raw)
+	if err != nil {
+		logger.Error("Failed to parse vars file "+fn, err)
+		return nil, err
+	}
+
+	values := entities.Mappings{}
+	for key, value := range raw {
+		switch typed := value.(type) {
+		case nil:
+			values[key] = ""
+		case []interface{}:
+			items := make([]string, 0, len(typed))
+			for _, item := range typed {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case map[string]interface{}:
+			return nil, fmt.Errorf("%s: the value of %s must be a scalar or a list", fn, key)
+		default:
+			values[key] = fmt.Sprint(typed)
+		}
+	}
+	logger.Info(fmt.Sprintf("Loaded %d variables from %s", len(values), fn))
+	return &ValuesReader{values: values}, nil
+}
+
+var _ usecases.VariableReaderPort = (*ValuesReader)(nil)
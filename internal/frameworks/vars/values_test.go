@@ -0,0 +1,61 @@
+package vars
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+func TestChainReader_GetValue(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "vars.yaml")
+	err := os.WriteFile(fn, []byte("name: from-file\nowner: acme\nport: 8080\napps: [users, orders]\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SOMBRA_VAR_NAME", "from-env")
+	t.Setenv("SOMBRA_VAR_PROJECT_SLUG", "slug")
+
+	args, err := NewArgsReader([]string{"name=from-args", "empty="})
+	if err != nil {
+		t.Fatalf("NewArgsReader() error = %v", err)
+	}
+	file, err := LoadValues(fn)
+	if err != nil {
+		t.Fatalf("LoadValues() error = %v", err)
+	}
+	reader := NewChainReader(args, file, NewEnvReader())
+
+	tests := []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{name: "name", expected: "from-args", ok: true},
+		{name: "empty", expected: "", ok: true},
+		{name: "owner", expected: "acme", ok: true},
+		{name: "port", expected: "8080", ok: true},
+		{name: "apps", expected: "users,orders", ok: true},
+		{name: "project-slug", expected: "slug", ok: true},
+		{name: "missing", expected: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok, err := reader.GetValue(&entities.Var{Name: tt.name}, "", nil)
+			if err != nil {
+				t.Fatalf("GetValue() error = %v", err)
+			}
+			if value != tt.expected || ok != tt.ok {
+				t.Errorf("GetValue() = %q, %v, expected %q, %v", value, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNewArgsReader(t *testing.T) {
+	if _, err := NewArgsReader([]string{"name"}); err == nil {
+		t.Errorf("Expected an error without =")
+	}
+}
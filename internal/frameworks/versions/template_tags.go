@@ -1,6 +1,7 @@
 package versions
 
 import (
+	"fmt"
 	"github.com/Masterminds/semver/v3"
 	"github.com/sombrahq/sombra-cli/internal/core/entities"
 	"github.com/sombrahq/sombra-cli/internal/core/usecases"
@@ -67,6 +68,7 @@ func (t *TemplateTagManagerService) GetLatest(tags []string, constraint string)
 	}
 
 	if last == nil {
+		err = fmt.Errorf("no version matches the constraint %s", constraint)
 		logger.Error("No matching versions found", err)
 		return "", err
 	}
@@ -90,8 +92,8 @@ func (t *TemplateTagManagerService) GetNext(tags []string, constraint string, cu
 	}
 	c, err := semver.NewConstraint(constraint)
 	if err != nil {
-		logger.Error("Failed to create constraint, defaulting to '*'", err)
-		c, _ = semver.NewConstraint("*")
+		logger.Error("Failed to create constraint", err)
+		return "", err
 	}
 
 	vs := make(SemVerList, 0)
@@ -112,14 +114,17 @@ func (t *TemplateTagManagerService) GetNext(tags []string, constraint string, cu
 	sort.Sort(vs)
 
 	var res *semver.Version
-	for _, res = range vs {
-		if c.Check(res) && res.GreaterThan(cur) {
+	for _, candidate := range vs {
+		if c.Check(candidate) && candidate.GreaterThan(cur) {
+			res = candidate
 			break
 		}
 	}
+
+	// the current version is already the last one allowed by the constraint
 	if res == nil {
-		logger.Error("No next version found", err)
-		return "", err
+		logger.Info("No next version found, keeping " + string(current))
+		return current, nil
 	}
 
 	logger.Info("Next version found: " + res.Original())
@@ -127,15 +132,16 @@ func (t *TemplateTagManagerService) GetNext(tags []string, constraint string, cu
 }
 
 func (t *TemplateTagManagerService) Compare(v1, v2 entities.Version) (int8, error) {
+	// refs like commits are not an error, the callers can compare them by other means
 	ver1, err := semver.NewVersion(string(v1))
 	if err != nil {
-		logger.Error("Invalid version v1", err)
+		logger.Info("Not a semantic version: " + string(v1))
 		return 0, err
 	}
 
 	ver2, err := semver.NewVersion(string(v2))
 	if err != nil {
-		logger.Error("Invalid version v2", err)
+		logger.Info("Not a semantic version: " + string(v2))
 		return 0, err
 	}
 
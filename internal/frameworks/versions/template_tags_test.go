@@ -0,0 +1,69 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/sombrahq/sombra-cli/internal/core/entities"
+)
+
+func TestTemplateTagManagerService_GetLatest(t *testing.T) {
+	tags := []string{"v1.3.0", "v1.4.0", "v1.4.2", "v2.0.0", "v2.1.0", "latest", "v3.0.0-beta.1"}
+
+	tests := []struct {
+		name        string
+		constraint  string
+		expected    entities.Version
+		shouldError bool
+	}{
+		{name: "any version", constraint: "*", expected: "v2.1.0"},
+		{name: "empty constraint", constraint: "", expected: "v2.1.0"},
+		{name: "caret keeps the major line", constraint: "^1.4", expected: "v1.4.2"},
+		{name: "tilde keeps the minor line", constraint: "~2.0", expected: "v2.0.0"},
+		{name: "no matching version", constraint: "^4", shouldError: true},
+		{name: "invalid constraint", constraint: "not a constraint", shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := NewTemplateTagManagerService().GetLatest(tags, tt.constraint)
+			if (err != nil) != tt.shouldError {
+				t.Fatalf("GetLatest() error = %v, shouldError = %v", err, tt.shouldError)
+			}
+			if version != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, version)
+			}
+		})
+	}
+}
+
+func TestTemplateTagManagerService_GetNext(t *testing.T) {
+	tags := []string{"v2.0.0", "v1.4.0", "v1.3.0", "v1.4.2", "v2.1.0"}
+
+	tests := []struct {
+		name        string
+		constraint  string
+		current     entities.Version
+		expected    entities.Version
+		shouldError bool
+	}{
+		{name: "next release", constraint: "*", current: "v1.3.0", expected: "v1.4.0"},
+		{name: "next release across majors", constraint: "*", current: "v1.4.2", expected: "v2.0.0"},
+		{name: "constraint keeps the major line", constraint: "^1", current: "v1.4.2", expected: "v1.4.2"},
+		{name: "current between releases", constraint: "*", current: "v1.4.1", expected: "v1.4.2"},
+		{name: "latest release keeps the current one", constraint: "*", current: "v2.1.0", expected: "v2.1.0"},
+		{name: "invalid current version", constraint: "*", current: "main", shouldError: true},
+		{name: "invalid constraint", constraint: "not a constraint", current: "v1.3.0", shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := NewTemplateTagManagerService().GetNext(tags, tt.constraint, tt.current)
+			if (err != nil) != tt.shouldError {
+				t.Fatalf("GetNext() error = %v, shouldError = %v", err, tt.shouldError)
+			}
+			if version != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, version)
+			}
+		})
+	}
+}
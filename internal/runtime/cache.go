@@ -0,0 +1,24 @@
+package runtime
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/cvs"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/report"
+)
+
+type CacheRuntime struct {
+	UseCase usecases.CliCacheCase
+}
+
+func NewCacheRuntime() (*CacheRuntime, error) {
+	cache, err := cvs.CacheFor()
+	if err != nil {
+		return nil, err
+	}
+	reporter := report.NewConsoleReporter()
+
+	cliCase := usecases.NewCliCacheInteractor(cache, reporter)
+	return &CacheRuntime{
+		UseCase: cliCase,
+	}, nil
+}
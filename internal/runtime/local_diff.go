@@ -0,0 +1,43 @@
+package runtime
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/auth"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/cvs"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/files"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/report"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/sombra"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/templates"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/textdiff"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/versions"
+)
+
+type LocalDiffRuntime struct {
+	UseCase usecases.CliLocalDiffCase
+}
+
+func NewLocalDiffRuntime(offline bool) (*LocalDiffRuntime, error) {
+	credentials, err := auth.NewService()
+	if err != nil {
+		return nil, err
+	}
+
+	dirManager := files.NewDirectoryScannerService()
+	fileManager := files.NewFileManagerService()
+	stringProcessor := sombra.NewProcessor()
+	engine := usecases.NewSombraEngineInteractor(dirManager, fileManager, stringProcessor)
+	templateDef := templates.NewDefService()
+
+	repoPrepare := usecases.NewRepositoryPrepareInteractor(cvs.FactoryFor(cvs.Options{Offline: offline}), credentials)
+	sombraDefManager := sombra.NewDefService()
+	versionManager := versions.NewTemplateTagManagerService()
+	render := usecases.NewTemplateRenderInteractor(dirManager, fileManager, engine)
+	textDiff := textdiff.NewUnifiedService()
+	reporter := report.NewConsoleReporter()
+
+	diffCase := usecases.NewLocalDiffInteractor(repoPrepare, templateDef, sombraDefManager, versionManager, fileManager, render, textDiff)
+	cliCase := usecases.NewCliLocalDiffInteractor(diffCase, reporter)
+	return &LocalDiffRuntime{
+		UseCase: cliCase,
+	}, nil
+}
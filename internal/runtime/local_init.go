@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/auth"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/cvs"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/sombra"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/templates"
@@ -12,14 +13,52 @@ type LocalInitRuntime struct {
 	UseCase usecases.CliLocalInitCase
 }
 
-func NewLocalInitRuntime() *LocalInitRuntime {
-	var repoPrepare usecases.RepositoryPrepareCase = usecases.NewRepositoryPrepareInteractor(cvs.For)
+// LocalInitOptions sets where the variables are read from, in this order: Vars, VarsFile,
+// the SOMBRA_VAR_<NAME> environment variables and the prompt unless NoInput is set
+type LocalInitOptions struct {
+	Offline  bool
+	Vars     []string
+	VarsFile string
+	NoInput  bool
+}
+
+func NewLocalInitRuntime(opts LocalInitOptions) (*LocalInitRuntime, error) {
+	credentials, err := auth.NewService()
+	if err != nil {
+		return nil, err
+	}
+	varsSource, err := varsReader(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var repoPrepare usecases.RepositoryPrepareCase = usecases.NewRepositoryPrepareInteractor(cvs.FactoryFor(cvs.Options{Offline: opts.Offline}), credentials)
 	var templateDefManager usecases.TemplateDefManagerPort = templates.NewDefService()
 	var sombraDefManager usecases.SombraDefManagerPort = sombra.NewDefService()
-	var varsSource = vars.NewReader()
 	localInitCase := usecases.NewLocalInitInteractor(repoPrepare, templateDefManager, sombraDefManager, varsSource)
 	cliCase := usecases.NewCliLocalInitInteractor(localInitCase)
 	return &LocalInitRuntime{
 		UseCase: cliCase,
+	}, nil
+}
+
+func varsReader(opts LocalInitOptions) (usecases.VariableReaderPort, error) {
+	args, err := vars.NewArgsReader(opts.Vars)
+	if err != nil {
+		return nil, err
+	}
+	readers := []usecases.VariableReaderPort{args}
+
+	if opts.VarsFile != "" {
+		file, err := vars.LoadValues(opts.VarsFile)
+		if err != nil {
+			return nil, err
+		}
+		readers = append(readers, file)
+	}
+	readers = append(readers, vars.NewEnvReader())
+	if !opts.NoInput {
+		readers = append(readers, vars.NewReader())
 	}
+	return vars.NewChainReader(readers...), nil
 }
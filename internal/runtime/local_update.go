@@ -2,34 +2,52 @@ package runtime
 
 import (
 	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/auth"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/cvs"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/files"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/report"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/sombra"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/templates"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/textdiff"
 	"github.com/sombrahq/sombra-cli/internal/frameworks/versions"
 )
 
+// LocalUpdateOptions exposes the update options to the command line
+type LocalUpdateOptions = usecases.LocalUpdateOptions
+
 type LocalUpdateRuntime struct {
 	UseCase usecases.CliUpdateCase
 }
 
-func NewLocalUpdateRuntime() *LocalUpdateRuntime {
+func NewLocalUpdateRuntime(offline bool) (*LocalUpdateRuntime, error) {
+	credentials, err := auth.NewService()
+	if err != nil {
+		return nil, err
+	}
+
 	dirManager := files.NewDirectoryScannerService()
 	fileManager := files.NewFileManagerService()
 	stringProcessor := sombra.NewProcessor()
 	engine := usecases.NewSombraEngineInteractor(dirManager, fileManager, stringProcessor)
 	templateDef := templates.NewDefService()
 
-	repoPrepare := usecases.NewRepositoryPrepareInteractor(cvs.For)
+	repoPrepare := usecases.NewRepositoryPrepareInteractor(cvs.FactoryFor(cvs.Options{Offline: offline}), credentials)
 	sombraDefManager := sombra.NewDefService()
 	versionManager := versions.NewTemplateTagManagerService()
 
-	patchManager := cvs.NewPatchService()
+	patchManager := cvs.PatchFor()
+	patchTransform := cvs.TransformFor(engine)
+	merger := textdiff.NewMergeService()
+	render := usecases.NewTemplateRenderInteractor(dirManager, fileManager, engine)
+	snapshots := files.NewSnapshotService()
+	reporter := report.NewConsoleReporter()
 
 	copyCase := usecases.NewLocalCopyInteractor(repoPrepare, templateDef, sombraDefManager, versionManager, dirManager, fileManager, engine)
-	diffCase := usecases.NewDirectoryLocalDiffInteractor(repoPrepare, patchManager, templateDef, sombraDefManager, versionManager, dirManager, fileManager, engine)
-	cliCase := usecases.NewCliUpdateInteractor(copyCase, diffCase)
+	diffCase := usecases.NewDirectoryLocalDiffInteractor(repoPrepare, patchManager, templateDef, sombraDefManager, versionManager, dirManager, fileManager, patchTransform)
+	mergeCase := usecases.NewLocalMergeInteractor(repoPrepare, templateDef, sombraDefManager, versionManager, fileManager, render, merger)
+	allCase := usecases.NewLocalUpdateAllInteractor(sombraDefManager, snapshots)
+	cliCase := usecases.NewCliUpdateInteractor(copyCase, diffCase, mergeCase, allCase, reporter)
 	return &LocalUpdateRuntime{
 		UseCase: cliCase,
-	}
+	}, nil
 }
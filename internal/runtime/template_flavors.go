@@ -0,0 +1,30 @@
+package runtime
+
+import (
+	"github.com/sombrahq/sombra-cli/internal/core/usecases"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/auth"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/cvs"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/report"
+	"github.com/sombrahq/sombra-cli/internal/frameworks/templates"
+)
+
+type TemplateFlavorsRuntime struct {
+	UseCase usecases.CliTemplateFlavorsCase
+}
+
+func NewTemplateFlavorsRuntime(offline bool) (*TemplateFlavorsRuntime, error) {
+	credentials, err := auth.NewService()
+	if err != nil {
+		return nil, err
+	}
+
+	var repoPrepare usecases.RepositoryPrepareCase = usecases.NewRepositoryPrepareInteractor(cvs.FactoryFor(cvs.Options{Offline: offline}), credentials)
+	var templateDefManager usecases.TemplateDefManagerPort = templates.NewDefService()
+	reporter := report.NewConsoleReporter()
+
+	flavorsCase := usecases.NewTemplateFlavorsInteractor(repoPrepare, templateDefManager)
+	cliCase := usecases.NewCliTemplateFlavorsInteractor(flavorsCase, reporter)
+	return &TemplateFlavorsRuntime{
+		UseCase: cliCase,
+	}, nil
+}
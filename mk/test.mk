@@ -3,5 +3,9 @@ test:
 	@go test --cover -parallel=1 -v -coverprofile=coverage.out ./...
 	@go tool cover -func=coverage.out | sort -rnk3
 
+.PHONY: bench
+bench:
+	@go test -run=^$$ -bench=. -benchmem ./internal/frameworks/sombra/...
+
 
 